@@ -0,0 +1,127 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PowerBudgetShare sets the priority and weight of the PowerCappingConfigs of
+// a namespace, or of a single config of the namespace
+type PowerBudgetShare struct {
+	Namespace string `json:"namespace"`
+	// Name selects a single config of the namespace. Defaults to all of them.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Priority orders the configs when the budget cannot meet all of them:
+	// configs of a higher priority are served first
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+	// Weight splits the budget between configs of the same priority. Defaults to 1.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Weight int32 `json:"weight,omitempty"`
+}
+
+// PowerBudgetSpec defines the desired state of PowerBudget
+type PowerBudgetSpec struct {
+	// PowerCapInWatts is the power that the selected configs may consume together
+	// +kubebuilder:validation:Minimum=0
+	PowerCapInWatts int `json:"powerCapInWatts"`
+	// NodeSelector selects the nodes covered by the budget, e.g. the nodes of
+	// a rack. Only the pods running on them count toward the power
+	// consumption of the configs. When unset, the budget covers all nodes.
+	// +optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+	// ConfigSelector selects the PowerCappingConfigs that share the budget by
+	// their labels. When unset, all configs of the selected namespaces share it.
+	// +optional
+	ConfigSelector *metav1.LabelSelector `json:"configSelector,omitempty"`
+	// NamespaceSelector selects the namespaces of the configs that share the
+	// budget. When unset, configs of all namespaces share it.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Shares set the priority and weight of the selected configs. The first
+	// share matching a config applies; the other configs have priority 0 and
+	// weight 1.
+	// +optional
+	Shares []PowerBudgetShare `json:"shares,omitempty"`
+}
+
+// PowerBudgetAllocation is the part of a power budget allocated to a PowerCappingConfig
+type PowerBudgetAllocation struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Priority  int32  `json:"priority,omitempty"`
+	Weight    int32  `json:"weight"`
+	// PowerConsumption is the current power consumption of the pods of the
+	// config running on the nodes of the budget, in watts
+	PowerConsumption int `json:"powerConsumption"`
+	// PowerCapInWatts is the effective power cap of the config
+	PowerCapInWatts int `json:"powerCapInWatts"`
+}
+
+// PowerBudgetStatus defines the observed state of PowerBudget
+type PowerBudgetStatus struct {
+	// ObservedGeneration is the most recent generation allocated by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// CurrentPowerConsumption is the current power consumption of all selected configs in watts
+	CurrentPowerConsumption int `json:"currentPowerConsumption,omitempty"`
+	// Allocations are the effective power caps of the selected configs
+	Allocations []PowerBudgetAllocation `json:"allocations,omitempty"`
+	// LastEvaluationTime is the time of the last allocation
+	LastEvaluationTime *metav1.Time `json:"lastEvaluationTime,omitempty"`
+
+	// Conditions describe the current state of the budget
+	//+listType=map
+	//+listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:printcolumn:name="Cap",type=integer,JSONPath=`.spec.powerCapInWatts`
+//+kubebuilder:printcolumn:name="Power",type=integer,JSONPath=`.status.currentPowerConsumption`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Capped",type=string,JSONPath=`.status.conditions[?(@.type=="Capped")].status`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// PowerBudget is the Schema for the powerbudgets API. It divides the power
+// budget of a facility, e.g. a rack or a cluster, into effective power caps of
+// the PowerCappingConfigs it selects.
+type PowerBudget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PowerBudgetSpec   `json:"spec,omitempty"`
+	Status PowerBudgetStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PowerBudgetList contains a list of PowerBudget
+type PowerBudgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []PowerBudget `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PowerBudget{}, &PowerBudgetList{})
+}
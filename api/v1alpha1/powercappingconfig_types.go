@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	"fmt"
+	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -29,6 +30,7 @@ const (
 	AbsolutePowerCapInWatts                                   PowerCappingSpecKind = "AbsolutePowerCapInWatts"
 	RelativePowerCapOfPeakPowerConsumptionInPercentage        PowerCappingSpecKind = "RelativePowerCapOfPeakPowerConsumptionInPercentage"
 	RelativePowerCappingOfAveragePowerConsumptionInPercentage PowerCappingSpecKind = "RelativePowerCappingOfAveragePowerConsumptionInPercentage"
+	CarbonAwarePowerCapInWatts                                PowerCappingSpecKind = "CarbonAwarePowerCapInWatts"
 )
 
 type AbsolutePowerCapInWattsSpec struct {
@@ -40,11 +42,172 @@ type RelativePowerCapInPercentageSpec struct {
 	SampleWindow       int `json:"sampleWindow,omitempty"`       // Sample window in seconds
 }
 
+// CarbonAwarePowerCapSpec scales the power cap between MinPowerCapInWatts and
+// MaxPowerCapInWatts with the carbon intensity of the grid, in gCO2eq/kWh.
+// With CarbonBudgetInGramsPerHour, the cap is the power whose emissions fit in
+// the budget at the current intensity. Otherwise the cap falls linearly from
+// the maximum at MinCarbonIntensity to the minimum at MaxCarbonIntensity.
+type CarbonAwarePowerCapSpec struct {
+	MinPowerCapInWatts int `json:"minPowerCapInWatts,omitempty"`
+	MaxPowerCapInWatts int `json:"maxPowerCapInWatts,omitempty"`
+	MinCarbonIntensity int `json:"minCarbonIntensity,omitempty"`
+	MaxCarbonIntensity int `json:"maxCarbonIntensity,omitempty"`
+	// CarbonBudgetInGramsPerHour is the limit of the emissions of the matched
+	// pods together in gCO2eq/h, and requires the Aggregate scope. The cap
+	// never falls under MinPowerCapInWatts, so above an intensity of the
+	// budget over the minimum cap the emissions exceed the budget.
+	// +optional
+	CarbonBudgetInGramsPerHour int `json:"carbonBudgetInGramsPerHour,omitempty"`
+	// Zone is the grid zone whose carbon intensity is followed, e.g. DE or
+	// CAISO_NORTH. Defaults to the zone configured on the operator.
+	// +optional
+	Zone string `json:"zone,omitempty"`
+}
+
+// PowerCappingSchedule changes the effective power cap over the day, e.g. to
+// tighten it during time-of-use price peaks
+type PowerCappingSchedule struct {
+	// TimeZone is the IANA time zone of the windows, e.g. America/Los_Angeles.
+	// Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+	// Windows are checked in order and the first active one applies. Outside
+	// of all windows the power cap of the kind applies unchanged.
+	Windows []ScheduleWindow `json:"windows,omitempty"`
+}
+
+// ScheduleWindow is a recurring period of the day during which the power cap
+// is scaled or replaced
+type ScheduleWindow struct {
+	// Name identifies the window in the status
+	Name string `json:"name"`
+	// Days are the days of the week on which the window starts, in cron
+	// notation, e.g. Mon-Fri or Sat,Sun. Defaults to every day.
+	// +optional
+	Days string `json:"days,omitempty"`
+	// Start and End are times of the day as HH:MM. A window ending at or
+	// before its start ends on the next day.
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// PowerCapPercentage scales the power cap of the kind while the window is active
+	// +optional
+	PowerCapPercentage int `json:"powerCapPercentage,omitempty"`
+	// PowerCapInWatts replaces the power cap of the kind while the window is active
+	// +optional
+	PowerCapInWatts int `json:"powerCapInWatts,omitempty"`
+}
+
+// NodePowerCapAction is what the controller does to a node whose power exceeds its cap
+type NodePowerCapAction string
+
+const (
+	// NodePowerCapActionCordon marks the node unschedulable.
+	NodePowerCapActionCordon NodePowerCapAction = "Cordon"
+	// NodePowerCapActionTaint taints the node with the NoSchedule taint
+	// climatik-project.io/power-pressure so that only pods tolerating power
+	// pressure are scheduled on it.
+	NodePowerCapActionTaint NodePowerCapAction = "Taint"
+	// NodePowerCapActionEvict evicts the matched pods of the node, lowest
+	// priority first, until the node fits in its cap.
+	NodePowerCapActionEvict NodePowerCapAction = "Evict"
+)
+
+// NodePowerCapSpec caps the power of whole nodes as measured by Kepler, e.g.
+// to the limit of the PDU feeding them
+type NodePowerCapSpec struct {
+	// NodeSelector selects the capped nodes
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector"`
+	// PowerCapInWatts is the power cap of each selected node
+	// +kubebuilder:validation:Minimum=1
+	PowerCapInWatts int `json:"powerCapInWatts"`
+	// Action is taken on the nodes exceeding the cap and reverted once they are
+	// back under it. Evictions are not reverted. Defaults to Taint.
+	// +kubebuilder:validation:Enum=Cordon;Taint;Evict
+	// +optional
+	Action NodePowerCapAction `json:"action,omitempty"`
+}
+
+// Forecasting methods of a PowerForecastSpec
+const (
+	// ForecastMethodHoltWinters smooths the level, trend and, with a season,
+	// the seasonal pattern of the power.
+	ForecastMethodHoltWinters = "HoltWinters"
+	// ForecastMethodLinearTrend extrapolates the least squares line through the power.
+	ForecastMethodLinearTrend = "LinearTrend"
+)
+
+// Sources of power and temperature metrics accepted in
+// PowerCappingConfigSpec.MetricsSource
+const (
+	// MetricsSourceKepler reads the energy counters of Kepler from Prometheus.
+	MetricsSourceKepler = "Kepler"
+	// MetricsSourceDCGM reads the GPU gauges of the NVIDIA DCGM exporter from Prometheus.
+	MetricsSourceDCGM = "DCGM"
+	// MetricsSourceFile replays the metrics file given to the operator.
+	MetricsSourceFile = "File"
+)
+
+// PowerForecastSpec predicts the power of all matched pods together from its
+// recent history
+type PowerForecastSpec struct {
+	// Method is the model fitted to the history. Defaults to HoltWinters.
+	// +kubebuilder:validation:Enum=HoltWinters;LinearTrend
+	// +optional
+	Method string `json:"method,omitempty"`
+	// HorizonInSeconds is how far ahead the power is predicted. Defaults to 900.
+	// +optional
+	HorizonInSeconds int `json:"horizonInSeconds,omitempty"`
+	// LookbackInSeconds is the history the model is fitted to. Defaults to 3600.
+	// +optional
+	LookbackInSeconds int `json:"lookbackInSeconds,omitempty"`
+	// StepInSeconds is the resolution of the history. Defaults to 60.
+	// +optional
+	StepInSeconds int `json:"stepInSeconds,omitempty"`
+	// SeasonInSeconds is the period of the seasonal pattern of HoltWinters,
+	// e.g. 86400 for a daily pattern. The lookback must cover two seasons.
+	// Without it only the level and trend are followed.
+	// +optional
+	SeasonInSeconds int `json:"seasonInSeconds,omitempty"`
+	// Proactive acts on the forecast as on the current power: pods are
+	// alerted while the forecast exceeds the power cap, before the power does.
+	// +optional
+	Proactive bool `json:"proactive,omitempty"`
+}
+
+// PowerCappingScope selects what the power cap of a PowerCappingConfig applies to
+type PowerCappingScope string
+
+const (
+	// PowerCappingScopePod caps each matched pod on its own.
+	PowerCappingScopePod PowerCappingScope = "Pod"
+	// PowerCappingScopeAggregate caps the sum of the power of all matched pods.
+	PowerCappingScopeAggregate PowerCappingScope = "Aggregate"
+)
+
 // PowerCappingSpec specifies the kind of PowerCappingConfig
 type PowerCappingSpec struct {
 	Kind                             PowerCappingSpecKind `json:"kind,omitempty"`
 	AbsolutePowerCapInWattsSpec      `json:"absolutePowerCapInWatts,omitempty"`
 	RelativePowerCapInPercentageSpec `json:"relativePowerCapInPercentage,omitempty"`
+	CarbonAwarePowerCapSpec          `json:"carbonAwarePowerCap,omitempty"`
+
+	// Scope is Pod to cap each matched pod on its own or Aggregate to cap the
+	// sum of all matched pods. Defaults to Pod.
+	// +kubebuilder:validation:Enum=Pod;Aggregate
+	// +optional
+	Scope PowerCappingScope `json:"scope,omitempty"`
+
+	// HysteresisPercentage is the band, in percent of the power cap, that
+	// keeps alerts from flapping: an alert raised while the power exceeds the
+	// cap is resolved only once the power is back under the cap by the band,
+	// and a new cap is alerted only when it moves away from the alerted one
+	// by more than the band. Alerts raised while a node exceeds the
+	// temperature threshold are likewise resolved only once the node is back
+	// under the threshold by the band. Defaults to 5.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=50
+	// +optional
+	HysteresisPercentage int `json:"hysteresisPercentage,omitempty"`
 }
 
 type TemperatureThresholdKind string
@@ -61,8 +224,14 @@ type AbsoluteTemperatureThresholdInCelsiusSpec struct {
 }
 
 type RelativeTemperatureThresholdInPercentageSpec struct {
-	TemperatureThresholdPercentage int `json:"temperatureThresholdPercentage,omitempty"` // Temperature threshold in percentage of peak temperature
-	SampleWindow                   int `json:"sampleWindow,omitempty"`                   // Sample window in seconds
+	// TemperatureThresholdPercentage is the threshold in percent of the peak
+	// or average temperature of the node over the sample window. The current
+	// temperature is part of the peak, so a threshold of the peak is only
+	// exceeded below 100%, and then also by a steady temperature: it alerts
+	// while the node runs within that percentage of its recent peak rather
+	// than when the temperature rises.
+	TemperatureThresholdPercentage int `json:"temperatureThresholdPercentage,omitempty"`
+	SampleWindow                   int `json:"sampleWindow,omitempty"` // Sample window in seconds
 }
 
 // TemperatureThresholdSpec specifies the kind of TemperatureThresholdConfig
@@ -72,22 +241,288 @@ type TemperatureThresholdSpec struct {
 	RelativeTemperatureThresholdInPercentageSpec `json:"relativeTemperatureThresholdInPercentage,omitempty"`
 }
 
+// Workload types accepted in PowerCappingConfigSpec.WorkloadType
+const (
+	WorkloadTypeTraining  = "training"
+	WorkloadTypeInference = "inference"
+)
+
+// Efficiency levels accepted in PowerCappingConfigSpec.EfficiencyLevel
+const (
+	EfficiencyLevelHigh   = "high"
+	EfficiencyLevelMedium = "medium"
+	EfficiencyLevelLow    = "low"
+)
+
+// Defaults applied when a relative power cap or temperature threshold omits its values
+const (
+	DefaultPowerCapPercentageHigh   = 90
+	DefaultPowerCapPercentageMedium = 80
+	DefaultPowerCapPercentageLow    = 50
+	DefaultSampleWindowInSeconds    = 60
+	DefaultHysteresisPercentage     = 5
+)
+
+// Defaults applied when a PowerForecastSpec omits its values
+const (
+	DefaultForecastHorizonInSeconds  = 900
+	DefaultForecastLookbackInSeconds = 3600
+	DefaultForecastStepInSeconds     = 60
+)
+
+// PowerCapPercentageForEfficiencyLevel returns the default power cap percentage
+// of an efficiency level, or 0 if the level is unknown.
+func PowerCapPercentageForEfficiencyLevel(level string) int {
+	switch strings.ToLower(level) {
+	case EfficiencyLevelHigh:
+		return DefaultPowerCapPercentageHigh
+	case EfficiencyLevelMedium:
+		return DefaultPowerCapPercentageMedium
+	case EfficiencyLevelLow:
+		return DefaultPowerCapPercentageLow
+	default:
+		return 0
+	}
+}
+
+// ScaledObjectReference references a KEDA ScaledObject in the namespace of the config
+type ScaledObjectReference struct {
+	// APIVersion of the ScaledObject, defaults to keda.sh/v1alpha1
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+	// Kind of the ScaledObject, defaults to ScaledObject
+	// +optional
+	Kind     string               `json:"kind,omitempty"`
+	Metadata ScaledObjectMetadata `json:"metadata"`
+	// Priority and Weight rank the ScaledObject for the Priority strategy
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Weight int32 `json:"weight,omitempty"`
+}
+
+// ScaledObjectMetadata names a referenced ScaledObject
+type ScaledObjectMetadata struct {
+	Name string `json:"name"`
+}
+
+// Kinds of workloads and autoscalers accepted in ScaleTargetReference.Kind
+const (
+	ScaleTargetKindDeployment              = "Deployment"
+	ScaleTargetKindStatefulSet             = "StatefulSet"
+	ScaleTargetKindHorizontalPodAutoscaler = "HorizontalPodAutoscaler"
+)
+
+// Replica allocation strategies accepted in PowerCappingConfigSpec.Strategy
+const (
+	// StrategyProportional splits the power cap by the current power of the scale targets.
+	StrategyProportional = "Proportional"
+	// StrategyMaximizeReplicas maximizes the total replicas, filling the cheapest replicas first.
+	StrategyMaximizeReplicas = "MaximizeReplicas"
+	// StrategyPriority serves scale targets by decreasing priority, splitting equal priorities by weight.
+	StrategyPriority = "Priority"
+	// StrategyFair keeps the replica counts of the scale targets as even as possible.
+	StrategyFair = "Fair"
+)
+
+// ScaleTargetReference references a Deployment, StatefulSet or
+// HorizontalPodAutoscaler in the namespace of the config
+type ScaleTargetReference struct {
+	// APIVersion of the referenced object, defaults to apps/v1 for workloads
+	// and autoscaling/v2 for HorizontalPodAutoscalers
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet;HorizontalPodAutoscaler
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Priority and Weight rank the scale target for the Priority strategy
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Weight int32 `json:"weight,omitempty"`
+}
+
 // PowerCappingConfigSpec defines the desired state of PowerCappingConfig
 type PowerCappingConfigSpec struct {
 	WorkloadType             string                   `json:"workloadType,omitempty"`             // "training" or "inference"
 	EfficiencyLevel          string                   `json:"efficiencyLevel,omitempty"`          // "low", "medium", "high"
 	PowerCappingSpec         PowerCappingSpec         `json:"powerCappingSpec,omitempty"`         // Power capping specification
 	TemperatureThresholdSpec TemperatureThresholdSpec `json:"temperatureThresholdSpec,omitempty"` // Temperature threshold specification
+
+	// Selector selects the pods targeted by the config. When unset, the config
+	// targets the pods labelled climatik-project.io=<config name>.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// NamespaceSelector selects the namespaces in which Selector is applied.
+	// When unset, only the namespace of the config is considered; an empty
+	// selector matches all namespaces.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// ScaledObjectRefs are the KEDA ScaledObjects whose maxReplicaCount is bounded
+	// so that the replicas of their scale targets fit in the power cap.
+	// +optional
+	ScaledObjectRefs []ScaledObjectReference `json:"scaledObjectRefs,omitempty"`
+	// ScaleTargetRefs are the Deployments and StatefulSets whose replicas, and
+	// the HorizontalPodAutoscalers whose maxReplicas, are bounded so that the
+	// replicas fit in the power cap. The original values are restored when
+	// the cap is lifted or the config is deleted.
+	// +optional
+	ScaleTargetRefs []ScaleTargetReference `json:"scaleTargetRefs,omitempty"`
+	// Strategy allocates the replicas of the scale targets under the power cap.
+	// Every scale target keeps at least its minimum replicas. Defaults to
+	// Proportional.
+	// +kubebuilder:validation:Enum=Proportional;MaximizeReplicas;Priority;Fair
+	// +optional
+	Strategy string `json:"strategy,omitempty"`
+	// Schedule changes the power cap during recurring windows of the day.
+	// +optional
+	Schedule *PowerCappingSchedule `json:"schedule,omitempty"`
+	// NodePowerCap caps the power of the selected nodes, on top of the power
+	// cap of the matched pods.
+	// +optional
+	NodePowerCap *NodePowerCapSpec `json:"nodePowerCap,omitempty"`
+	// Forecast predicts the power of the matched pods over a horizon.
+	// +optional
+	Forecast *PowerForecastSpec `json:"forecast,omitempty"`
+	// MetricsSource is where the power and temperatures of the matched pods
+	// and their nodes are read from. Defaults to the source the operator is
+	// started with.
+	// +kubebuilder:validation:Enum=Kepler;DCGM;File
+	// +optional
+	MetricsSource string `json:"metricsSource,omitempty"`
+}
+
+// Condition types reported in PowerCappingConfigStatus.Conditions and
+// PowerBudgetStatus.Conditions
+const (
+	// ConditionReady is True when the last evaluation of the config completed.
+	ConditionReady = "Ready"
+	// ConditionCapped is True when a matched pod consumes more than its power cap.
+	ConditionCapped = "Capped"
+	// ConditionMetricsAvailable is True when power metrics could be read for the matched pods.
+	ConditionMetricsAvailable = "MetricsAvailable"
+	// ConditionDegraded is True when part of the evaluation failed, e.g. some pods had no metrics.
+	ConditionDegraded = "Degraded"
+	// ConditionTemperatureThresholdExceeded is True when a node running matched pods is hotter than the threshold.
+	ConditionTemperatureThresholdExceeded = "TemperatureThresholdExceeded"
+	// ConditionNodePowerCapExceeded is True when a node selected by the node power cap consumes more than the cap.
+	ConditionNodePowerCapExceeded = "NodePowerCapExceeded"
+	// ConditionPowerCapForecastExceeded is True when the forecast power of the matched pods exceeds their power cap.
+	ConditionPowerCapForecastExceeded = "PowerCapForecastExceeded"
+)
+
+// PodPowerShare is the part of an aggregate power cap taken by a pod
+type PodPowerShare struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// PowerConsumption is the current power consumption of the pod in watts
+	PowerConsumption int `json:"powerConsumption"`
+	// SharePercentage is the share of the aggregate power consumption taken by the pod
+	SharePercentage int `json:"sharePercentage"`
+	// PowerCapInWatts is the part of the aggregate power cap attributed to the pod
+	PowerCapInWatts int `json:"powerCapInWatts"`
+}
+
+// ScaleTargetStatus reports the replicas bound set on a referenced
+// ScaledObject, workload or HorizontalPodAutoscaler
+type ScaleTargetStatus struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	// MaxReplicas is the bound set on the maxReplicaCount of a ScaledObject,
+	// the replicas of a workload or the maxReplicas of a HorizontalPodAutoscaler
+	MaxReplicas int32 `json:"maxReplicas,omitempty"`
+	// Replicas is the number of running replicas of the scale target
+	Replicas int32 `json:"replicas,omitempty"`
+	// PowerPerReplicaInWatts is the current power consumption of one replica of the scale target
+	PowerPerReplicaInWatts int `json:"powerPerReplicaInWatts,omitempty"`
+	// PowerBudgetInWatts is the power the bounded replicas of the scale target may consume
+	PowerBudgetInWatts int `json:"powerBudgetInWatts,omitempty"`
+	// Message explains why the bound was not updated
+	Message string `json:"message,omitempty"`
+}
+
+// NodePowerStatus reports the power of a node selected by the node power cap
+type NodePowerStatus struct {
+	Name string `json:"name"`
+	// PowerConsumption is the current power consumption of the node in watts
+	PowerConsumption int `json:"powerConsumption"`
+	// Action is the action in effect on the node, if any
+	Action NodePowerCapAction `json:"action,omitempty"`
+	// EvictedPods is the number of pods evicted from the node in the last evaluation
+	EvictedPods int32 `json:"evictedPods,omitempty"`
+	// Message explains why the action could not be taken
+	Message string `json:"message,omitempty"`
 }
 
 // PowerCappingConfigStatus is the status for a PowerCappingConfig resource
 type PowerCappingConfigStatus struct {
-	CurrentPowerConsumption  int `json:"currentPowerConsumption,omitempty"`
+	// ObservedGeneration is the most recent generation evaluated by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// CurrentPowerConsumption is the current power consumption of all matched pods in watts
+	CurrentPowerConsumption int `json:"currentPowerConsumption,omitempty"`
+	// ForecastPowerConsumption is the power consumption of all matched pods in watts
+	// predicted at the end of the forecast horizon
 	ForecastPowerConsumption int `json:"forecastPowerConsumption,omitempty"`
+	// ActiveScheduleWindow is the name of the schedule window applied in the last evaluation
+	ActiveScheduleWindow string `json:"activeScheduleWindow,omitempty"`
+	// NextScheduleTransitionTime is when the next schedule window starts or the active one ends
+	NextScheduleTransitionTime *metav1.Time `json:"nextScheduleTransitionTime,omitempty"`
+	// CarbonIntensity is the carbon intensity of the grid in gCO2eq/kWh that the
+	// power cap followed in the last evaluation
+	CarbonIntensity int `json:"carbonIntensity,omitempty"`
+	// PowerBudget is the name of the PowerBudget whose allocation bounds the power cap
+	PowerBudget string `json:"powerBudget,omitempty"`
+	// BudgetPowerCapInWatts is the power allocated to the config by PowerBudget,
+	// which bounds the power cap of all matched pods together
+	BudgetPowerCapInWatts int `json:"budgetPowerCapInWatts,omitempty"`
+	// PowerCapInWatts is the highest per-pod power cap computed in the last evaluation,
+	// or the power cap of all matched pods together in Aggregate scope
+	PowerCapInWatts int `json:"powerCapInWatts,omitempty"`
+	// PeakPowerConsumption is the highest per-pod peak power over the sample window in watts,
+	// or the peak of the summed power in Aggregate scope
+	PeakPowerConsumption int `json:"peakPowerConsumption,omitempty"`
+	// AveragePowerConsumption is the mean per-pod average power over the sample window in watts,
+	// or the average of the summed power in Aggregate scope
+	AveragePowerConsumption int `json:"averagePowerConsumption,omitempty"`
+	// PodPowerShares break down the aggregate power consumption and cap per pod in Aggregate scope
+	PodPowerShares []PodPowerShare `json:"podPowerShares,omitempty"`
+	// ScaleTargets report the replicas bound set on each referenced ScaledObject,
+	// workload and HorizontalPodAutoscaler
+	ScaleTargets []ScaleTargetStatus `json:"scaleTargets,omitempty"`
+	// CurrentTemperatureInCelsius is the highest current temperature of the nodes running matched pods
+	CurrentTemperatureInCelsius int `json:"currentTemperatureInCelsius,omitempty"`
+	// TemperatureThresholdInCelsius is the lowest per-node temperature threshold computed in the last evaluation
+	TemperatureThresholdInCelsius int `json:"temperatureThresholdInCelsius,omitempty"`
+	// Nodes report the power of the nodes selected by the node power cap
+	Nodes []NodePowerStatus `json:"nodes,omitempty"`
+	// AlertedPods are the names of the pods whose alert is open in the alert managers,
+	// which are resolved when the config is deleted
+	AlertedPods []string `json:"alertedPods,omitempty"`
+	// MatchedPods is the number of running pods targeted by the config
+	MatchedPods int32 `json:"matchedPods,omitempty"`
+	// LastEvaluationTime is the time of the last power evaluation
+	LastEvaluationTime *metav1.Time `json:"lastEvaluationTime,omitempty"`
+
+	// Conditions describe the current state of the config
+	//+listType=map
+	//+listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Kind",type=string,JSONPath=`.spec.powerCappingSpec.kind`
+//+kubebuilder:printcolumn:name="Pods",type=integer,JSONPath=`.status.matchedPods`
+//+kubebuilder:printcolumn:name="Power",type=integer,JSONPath=`.status.currentPowerConsumption`
+//+kubebuilder:printcolumn:name="Cap",type=integer,JSONPath=`.status.powerCapInWatts`
+//+kubebuilder:printcolumn:name="Window",type=string,JSONPath=`.status.activeScheduleWindow`,priority=1
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Capped",type=string,JSONPath=`.status.conditions[?(@.type=="Capped")].status`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // PowerCappingConfig is the Schema for the powercappingconfigs API
 type PowerCappingConfig struct {
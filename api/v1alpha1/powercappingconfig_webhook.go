@@ -0,0 +1,496 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var powercappingconfiglog = logf.Log.WithName("powercappingconfig-resource")
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks with the manager.
+func (r *PowerCappingConfig) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-climatik-project-io-v1alpha1-powercappingconfig,mutating=true,failurePolicy=fail,sideEffects=None,groups=climatik-project.io,resources=powercappingconfigs,verbs=create;update,versions=v1alpha1,name=mpowercappingconfig.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &PowerCappingConfig{}
+
+// Default implements webhook.Defaulter. It normalizes the efficiency level and
+// fills the sample window of relative specs. Their percentage is left unset,
+// so that the controller follows the percentage of the efficiency level
+// configured on the operator, which may change after admission.
+func (r *PowerCappingConfig) Default() {
+	powercappingconfiglog.Info("default", "name", r.Name)
+
+	r.Spec.WorkloadType = strings.ToLower(r.Spec.WorkloadType)
+	r.Spec.EfficiencyLevel = strings.ToLower(r.Spec.EfficiencyLevel)
+
+	powerCapping := &r.Spec.PowerCappingSpec
+	switch powerCapping.Kind {
+	case "":
+		powerCapping.Kind = NoPowerCappingSpec
+	case RelativePowerCapOfPeakPowerConsumptionInPercentage, RelativePowerCappingOfAveragePowerConsumptionInPercentage:
+		if powerCapping.RelativePowerCapInPercentageSpec.SampleWindow == 0 {
+			powerCapping.RelativePowerCapInPercentageSpec.SampleWindow = DefaultSampleWindowInSeconds
+		}
+	}
+
+	for i := range r.Spec.ScaledObjectRefs {
+		ref := &r.Spec.ScaledObjectRefs[i]
+		if ref.APIVersion == "" {
+			ref.APIVersion = "keda.sh/v1alpha1"
+		}
+		if ref.Kind == "" {
+			ref.Kind = "ScaledObject"
+		}
+	}
+
+	if r.Spec.Strategy == "" {
+		r.Spec.Strategy = StrategyProportional
+	}
+	for i := range r.Spec.ScaleTargetRefs {
+		ref := &r.Spec.ScaleTargetRefs[i]
+		if ref.APIVersion != "" {
+			continue
+		}
+		switch ref.Kind {
+		case ScaleTargetKindDeployment, ScaleTargetKindStatefulSet:
+			ref.APIVersion = "apps/v1"
+		case ScaleTargetKindHorizontalPodAutoscaler:
+			ref.APIVersion = "autoscaling/v2"
+		}
+	}
+
+	if forecast := r.Spec.Forecast; forecast != nil {
+		if forecast.Method == "" {
+			forecast.Method = ForecastMethodHoltWinters
+		}
+		if forecast.HorizonInSeconds == 0 {
+			forecast.HorizonInSeconds = DefaultForecastHorizonInSeconds
+		}
+		if forecast.LookbackInSeconds == 0 {
+			forecast.LookbackInSeconds = DefaultForecastLookbackInSeconds
+		}
+		if forecast.StepInSeconds == 0 {
+			forecast.StepInSeconds = DefaultForecastStepInSeconds
+		}
+	}
+
+	temperature := &r.Spec.TemperatureThresholdSpec
+	switch temperature.Kind {
+	case "":
+		temperature.Kind = NoTemperatureThreshold
+	case RelativeTemperatureThresholdOfPeakTemperatureInPercentage, RelativeTemperatureThresholdOfAverageTemperatureInPercentage:
+		if temperature.RelativeTemperatureThresholdInPercentageSpec.SampleWindow == 0 {
+			temperature.RelativeTemperatureThresholdInPercentageSpec.SampleWindow = DefaultSampleWindowInSeconds
+		}
+	}
+}
+
+//+kubebuilder:webhook:path=/validate-climatik-project-io-v1alpha1-powercappingconfig,mutating=false,failurePolicy=fail,sideEffects=None,groups=climatik-project.io,resources=powercappingconfigs,verbs=create;update,versions=v1alpha1,name=vpowercappingconfig.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &PowerCappingConfig{}
+
+// ValidateCreate implements webhook.Validator.
+func (r *PowerCappingConfig) ValidateCreate() (admission.Warnings, error) {
+	powercappingconfiglog.Info("validate create", "name", r.Name)
+
+	return nil, r.validatePowerCappingConfig()
+}
+
+// ValidateUpdate implements webhook.Validator. Updates of a config being
+// deleted, or leaving its spec unchanged such as removing the finalizer, are
+// accepted as is, so that a config admitted by an older version of the
+// validation can always be released.
+func (r *PowerCappingConfig) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	powercappingconfiglog.Info("validate update", "name", r.Name)
+
+	if !r.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+	if previous, ok := old.(*PowerCappingConfig); ok && equality.Semantic.DeepEqual(previous.Spec, r.Spec) {
+		return nil, nil
+	}
+	return nil, r.validatePowerCappingConfig()
+}
+
+// ValidateDelete implements webhook.Validator.
+func (r *PowerCappingConfig) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (r *PowerCappingConfig) validatePowerCappingConfig() error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if r.Spec.WorkloadType != "" {
+		switch strings.ToLower(r.Spec.WorkloadType) {
+		case WorkloadTypeTraining, WorkloadTypeInference:
+		default:
+			allErrs = append(allErrs, field.NotSupported(specPath.Child("workloadType"), r.Spec.WorkloadType,
+				[]string{WorkloadTypeTraining, WorkloadTypeInference}))
+		}
+	}
+	if r.Spec.EfficiencyLevel != "" && PowerCapPercentageForEfficiencyLevel(r.Spec.EfficiencyLevel) == 0 {
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("efficiencyLevel"), r.Spec.EfficiencyLevel,
+			[]string{EfficiencyLevelHigh, EfficiencyLevelMedium, EfficiencyLevelLow}))
+	}
+
+	allErrs = append(allErrs, metav1validation.ValidateLabelSelector(r.Spec.Selector,
+		metav1validation.LabelSelectorValidationOptions{}, specPath.Child("selector"))...)
+	allErrs = append(allErrs, metav1validation.ValidateLabelSelector(r.Spec.NamespaceSelector,
+		metav1validation.LabelSelectorValidationOptions{}, specPath.Child("namespaceSelector"))...)
+
+	for i, ref := range r.Spec.ScaledObjectRefs {
+		refPath := specPath.Child("scaledObjectRefs").Index(i)
+		if ref.Metadata.Name == "" {
+			allErrs = append(allErrs, field.Required(refPath.Child("metadata", "name"), "must name a ScaledObject"))
+		}
+		if ref.Kind != "" && ref.Kind != "ScaledObject" {
+			allErrs = append(allErrs, field.NotSupported(refPath.Child("kind"), ref.Kind, []string{"ScaledObject"}))
+		}
+		if ref.Weight < 0 {
+			allErrs = append(allErrs, field.Invalid(refPath.Child("weight"), ref.Weight, "must not be negative"))
+		}
+	}
+
+	for i, ref := range r.Spec.ScaleTargetRefs {
+		refPath := specPath.Child("scaleTargetRefs").Index(i)
+		if ref.Name == "" {
+			allErrs = append(allErrs, field.Required(refPath.Child("name"), "must name a scale target"))
+		}
+		switch ref.Kind {
+		case ScaleTargetKindDeployment, ScaleTargetKindStatefulSet, ScaleTargetKindHorizontalPodAutoscaler:
+		default:
+			allErrs = append(allErrs, field.NotSupported(refPath.Child("kind"), ref.Kind,
+				[]string{ScaleTargetKindDeployment, ScaleTargetKindStatefulSet, ScaleTargetKindHorizontalPodAutoscaler}))
+		}
+		if ref.Weight < 0 {
+			allErrs = append(allErrs, field.Invalid(refPath.Child("weight"), ref.Weight, "must not be negative"))
+		}
+	}
+
+	switch r.Spec.Strategy {
+	case "", StrategyProportional, StrategyMaximizeReplicas, StrategyPriority, StrategyFair:
+	default:
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("strategy"), r.Spec.Strategy,
+			[]string{StrategyProportional, StrategyMaximizeReplicas, StrategyPriority, StrategyFair}))
+	}
+
+	switch r.Spec.MetricsSource {
+	case "", MetricsSourceKepler, MetricsSourceDCGM, MetricsSourceFile:
+	default:
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("metricsSource"), r.Spec.MetricsSource,
+			[]string{MetricsSourceKepler, MetricsSourceDCGM, MetricsSourceFile}))
+	}
+
+	allErrs = append(allErrs, validatePowerCappingSpec(&r.Spec.PowerCappingSpec, specPath.Child("powerCappingSpec"))...)
+	allErrs = append(allErrs, validateTemperatureThresholdSpec(&r.Spec.TemperatureThresholdSpec, specPath.Child("temperatureThresholdSpec"))...)
+	if r.Spec.Schedule != nil {
+		allErrs = append(allErrs, validateSchedule(r.Spec.Schedule, specPath.Child("schedule"))...)
+	}
+	if r.Spec.NodePowerCap != nil {
+		allErrs = append(allErrs, validateNodePowerCap(r.Spec.NodePowerCap, specPath.Child("nodePowerCap"))...)
+	}
+	if r.Spec.Forecast != nil {
+		allErrs = append(allErrs, validateForecast(r.Spec.Forecast, specPath.Child("forecast"))...)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(
+		schema.GroupKind{Group: GroupVersion.Group, Kind: "PowerCappingConfig"},
+		r.Name, allErrs)
+}
+
+// validatePowerCappingSpec checks that only the union member matching the kind is set
+// and that its values are in range.
+func validatePowerCappingSpec(spec *PowerCappingSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	absolutePath := fldPath.Child("absolutePowerCapInWatts")
+	relativePath := fldPath.Child("relativePowerCapInPercentage")
+	carbonPath := fldPath.Child("carbonAwarePowerCap")
+	absoluteSet := spec.AbsolutePowerCapInWattsSpec != AbsolutePowerCapInWattsSpec{}
+	relativeSet := spec.RelativePowerCapInPercentageSpec != RelativePowerCapInPercentageSpec{}
+	carbonSet := spec.CarbonAwarePowerCapSpec != CarbonAwarePowerCapSpec{}
+
+	switch spec.Scope {
+	case "", PowerCappingScopePod, PowerCappingScopeAggregate:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("scope"), spec.Scope,
+			[]string{string(PowerCappingScopePod), string(PowerCappingScopeAggregate)}))
+	}
+	if spec.HysteresisPercentage < 0 || spec.HysteresisPercentage > 50 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("hysteresisPercentage"), spec.HysteresisPercentage, "must be between 0 and 50"))
+	}
+
+	kind := spec.Kind
+	if kind == "" {
+		kind = NoPowerCappingSpec
+	}
+	if absoluteSet && kind != AbsolutePowerCapInWatts {
+		allErrs = append(allErrs, field.Forbidden(absolutePath, "must not be set when kind is "+string(kind)))
+	}
+	if relativeSet && kind != RelativePowerCapOfPeakPowerConsumptionInPercentage && kind != RelativePowerCappingOfAveragePowerConsumptionInPercentage {
+		allErrs = append(allErrs, field.Forbidden(relativePath, "must not be set when kind is "+string(kind)))
+	}
+	if carbonSet && kind != CarbonAwarePowerCapInWatts {
+		allErrs = append(allErrs, field.Forbidden(carbonPath, "must not be set when kind is "+string(kind)))
+	}
+
+	switch kind {
+	case NoPowerCappingSpec:
+	case AbsolutePowerCapInWatts:
+		if spec.PowerCapInWatts <= 0 {
+			allErrs = append(allErrs, field.Invalid(absolutePath.Child("powerCapInWatts"), spec.PowerCapInWatts, "must be greater than 0"))
+		}
+	case RelativePowerCapOfPeakPowerConsumptionInPercentage, RelativePowerCappingOfAveragePowerConsumptionInPercentage:
+		allErrs = append(allErrs, validatePercentage(spec.PowerCapPercentage, relativePath.Child("powerCapPercentage"))...)
+		allErrs = append(allErrs, validateSampleWindow(spec.RelativePowerCapInPercentageSpec.SampleWindow, relativePath.Child("sampleWindow"))...)
+	case CarbonAwarePowerCapInWatts:
+		allErrs = append(allErrs, validateCarbonAwarePowerCapSpec(&spec.CarbonAwarePowerCapSpec, carbonPath)...)
+		// A budget capping each pod would let the pods together emit it
+		// once per pod.
+		if spec.CarbonBudgetInGramsPerHour > 0 && spec.Scope != PowerCappingScopeAggregate {
+			allErrs = append(allErrs, field.Forbidden(carbonPath.Child("carbonBudgetInGramsPerHour"), "requires the Aggregate scope"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("kind"), spec.Kind, []string{
+			string(NoPowerCappingSpec),
+			string(AbsolutePowerCapInWatts),
+			string(RelativePowerCapOfPeakPowerConsumptionInPercentage),
+			string(RelativePowerCappingOfAveragePowerConsumptionInPercentage),
+			string(CarbonAwarePowerCapInWatts),
+		}))
+	}
+	return allErrs
+}
+
+// validateCarbonAwarePowerCapSpec checks that the power cap range is set and
+// that either a carbon budget or a carbon intensity range scales it.
+func validateCarbonAwarePowerCapSpec(spec *CarbonAwarePowerCapSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if spec.MinPowerCapInWatts <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minPowerCapInWatts"), spec.MinPowerCapInWatts, "must be greater than 0"))
+	}
+	if spec.MaxPowerCapInWatts < spec.MinPowerCapInWatts {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxPowerCapInWatts"), spec.MaxPowerCapInWatts, "must not be less than minPowerCapInWatts"))
+	}
+	if spec.CarbonBudgetInGramsPerHour < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("carbonBudgetInGramsPerHour"), spec.CarbonBudgetInGramsPerHour, "must not be negative"))
+	}
+	if spec.CarbonBudgetInGramsPerHour == 0 {
+		if spec.MinCarbonIntensity < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("minCarbonIntensity"), spec.MinCarbonIntensity, "must not be negative"))
+		}
+		if spec.MaxCarbonIntensity <= spec.MinCarbonIntensity {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("maxCarbonIntensity"), spec.MaxCarbonIntensity,
+				"must be greater than minCarbonIntensity unless carbonBudgetInGramsPerHour is set"))
+		}
+	}
+	return allErrs
+}
+
+// validateSchedule checks the time zone and that every window is named once,
+// recurs on valid days and times and changes the power cap in a single way.
+func validateSchedule(schedule *PowerCappingSchedule, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if _, err := schedule.Location(); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("timeZone"), schedule.TimeZone, err.Error()))
+	}
+	names := make(map[string]bool, len(schedule.Windows))
+	for i, window := range schedule.Windows {
+		windowPath := fldPath.Child("windows").Index(i)
+		switch {
+		case window.Name == "":
+			allErrs = append(allErrs, field.Required(windowPath.Child("name"), "must name the window"))
+		case names[window.Name]:
+			allErrs = append(allErrs, field.Duplicate(windowPath.Child("name"), window.Name))
+		}
+		names[window.Name] = true
+		if _, err := parseDays(window.Days); err != nil {
+			allErrs = append(allErrs, field.Invalid(windowPath.Child("days"), window.Days, err.Error()))
+		}
+		start, err := parseTimeOfDay(window.Start)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(windowPath.Child("start"), window.Start, err.Error()))
+		}
+		end, err := parseTimeOfDay(window.End)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(windowPath.Child("end"), window.End, err.Error()))
+		} else if end%(24*60) == start%(24*60) {
+			allErrs = append(allErrs, field.Invalid(windowPath.Child("end"), window.End, "must differ from start"))
+		}
+		allErrs = append(allErrs, validatePercentage(window.PowerCapPercentage, windowPath.Child("powerCapPercentage"))...)
+		if window.PowerCapInWatts < 0 {
+			allErrs = append(allErrs, field.Invalid(windowPath.Child("powerCapInWatts"), window.PowerCapInWatts, "must not be negative"))
+		}
+		if (window.PowerCapPercentage > 0) == (window.PowerCapInWatts > 0) {
+			allErrs = append(allErrs, field.Invalid(windowPath, window.Name, "exactly one of powerCapPercentage and powerCapInWatts must be set"))
+		}
+	}
+	return allErrs
+}
+
+// validateNodePowerCap checks that the node power cap selects nodes with a
+// positive cap and a known action.
+func validateNodePowerCap(spec *NodePowerCapSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if spec.NodeSelector == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("nodeSelector"), "must select the capped nodes"))
+	}
+	allErrs = append(allErrs, metav1validation.ValidateLabelSelector(spec.NodeSelector,
+		metav1validation.LabelSelectorValidationOptions{}, fldPath.Child("nodeSelector"))...)
+	if spec.PowerCapInWatts <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("powerCapInWatts"), spec.PowerCapInWatts, "must be greater than 0"))
+	}
+	switch spec.Action {
+	case "", NodePowerCapActionCordon, NodePowerCapActionTaint, NodePowerCapActionEvict:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("action"), spec.Action,
+			[]string{string(NodePowerCapActionCordon), string(NodePowerCapActionTaint), string(NodePowerCapActionEvict)}))
+	}
+	return allErrs
+}
+
+// validateForecast checks that the forecast has a known method and that its
+// lookback holds enough steps, and two seasons when it has one.
+func validateForecast(spec *PowerForecastSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch spec.Method {
+	case "", ForecastMethodHoltWinters, ForecastMethodLinearTrend:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("method"), spec.Method,
+			[]string{ForecastMethodHoltWinters, ForecastMethodLinearTrend}))
+	}
+	for _, value := range []struct {
+		name    string
+		seconds int
+	}{
+		{"horizonInSeconds", spec.HorizonInSeconds},
+		{"lookbackInSeconds", spec.LookbackInSeconds},
+		{"stepInSeconds", spec.StepInSeconds},
+		{"seasonInSeconds", spec.SeasonInSeconds},
+	} {
+		if value.seconds < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(value.name), value.seconds, "must not be negative"))
+		}
+	}
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+
+	lookback := spec.LookbackInSeconds
+	if lookback == 0 {
+		lookback = DefaultForecastLookbackInSeconds
+	}
+	step := spec.StepInSeconds
+	if step == 0 {
+		step = DefaultForecastStepInSeconds
+	}
+	if lookback < 2*step {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("lookbackInSeconds"), spec.LookbackInSeconds, "must cover at least two steps"))
+	}
+	if spec.SeasonInSeconds > 0 {
+		switch {
+		case spec.Method == ForecastMethodLinearTrend:
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("seasonInSeconds"), "must not be set when method is "+ForecastMethodLinearTrend))
+		case spec.SeasonInSeconds%step != 0:
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("seasonInSeconds"), spec.SeasonInSeconds, "must be a multiple of stepInSeconds"))
+		case lookback < 2*spec.SeasonInSeconds:
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("lookbackInSeconds"), spec.LookbackInSeconds, "must cover at least two seasons"))
+		}
+	}
+	return allErrs
+}
+
+// validateTemperatureThresholdSpec checks that only the union member matching the kind
+// is set and that its values are in range.
+func validateTemperatureThresholdSpec(spec *TemperatureThresholdSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	absolutePath := fldPath.Child("absoluteTemperatureThresholdInCelsius")
+	relativePath := fldPath.Child("relativeTemperatureThresholdInPercentage")
+	absoluteSet := spec.AbsoluteTemperatureThresholdInCelsiusSpec != AbsoluteTemperatureThresholdInCelsiusSpec{}
+	relativeSet := spec.RelativeTemperatureThresholdInPercentageSpec != RelativeTemperatureThresholdInPercentageSpec{}
+
+	switch spec.Kind {
+	case "", NoTemperatureThreshold:
+		if absoluteSet {
+			allErrs = append(allErrs, field.Forbidden(absolutePath, "must not be set when kind is "+string(NoTemperatureThreshold)))
+		}
+		if relativeSet {
+			allErrs = append(allErrs, field.Forbidden(relativePath, "must not be set when kind is "+string(NoTemperatureThreshold)))
+		}
+	case AbsoluteTemperatureThresholdInCelsius:
+		if relativeSet {
+			allErrs = append(allErrs, field.Forbidden(relativePath, "must not be set when kind is "+string(spec.Kind)))
+		}
+		if spec.TemperatureThresholdInCelsius <= 0 {
+			allErrs = append(allErrs, field.Invalid(absolutePath.Child("temperatureThresholdInCelsius"), spec.TemperatureThresholdInCelsius, "must be greater than 0"))
+		}
+	case RelativeTemperatureThresholdOfPeakTemperatureInPercentage, RelativeTemperatureThresholdOfAverageTemperatureInPercentage:
+		if absoluteSet {
+			allErrs = append(allErrs, field.Forbidden(absolutePath, "must not be set when kind is "+string(spec.Kind)))
+		}
+		allErrs = append(allErrs, validatePercentage(spec.TemperatureThresholdPercentage, relativePath.Child("temperatureThresholdPercentage"))...)
+		allErrs = append(allErrs, validateSampleWindow(spec.RelativeTemperatureThresholdInPercentageSpec.SampleWindow, relativePath.Child("sampleWindow"))...)
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("kind"), spec.Kind, []string{
+			string(NoTemperatureThreshold),
+			string(AbsoluteTemperatureThresholdInCelsius),
+			string(RelativeTemperatureThresholdOfPeakTemperatureInPercentage),
+			string(RelativeTemperatureThresholdOfAverageTemperatureInPercentage),
+		}))
+	}
+	return allErrs
+}
+
+// validatePercentage accepts values in (0, 100] and 0, which leaves the
+// percentage unset. The controller uses the percentage of the efficiency level
+// configured on the operator at reconcile time for an unset power cap or
+// temperature threshold percentage, while a schedule window without one sets
+// its power cap in watts.
+func validatePercentage(percentage int, fldPath *field.Path) field.ErrorList {
+	if percentage < 0 || percentage > 100 {
+		return field.ErrorList{field.Invalid(fldPath, percentage, "must be between 0 and 100")}
+	}
+	return nil
+}
+
+func validateSampleWindow(window int, fldPath *field.Path) field.ErrorList {
+	if window < 0 {
+		return field.ErrorList{field.Invalid(fldPath, window, "must not be negative")}
+	}
+	return nil
+}
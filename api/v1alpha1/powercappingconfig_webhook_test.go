@@ -0,0 +1,544 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPowerCappingConfigDefault(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     PowerCappingConfigSpec
+		expected PowerCappingConfigSpec
+	}{
+		{
+			name: "empty kinds",
+			spec: PowerCappingConfigSpec{},
+			expected: PowerCappingConfigSpec{
+				Strategy:                 StrategyProportional,
+				PowerCappingSpec:         PowerCappingSpec{Kind: NoPowerCappingSpec},
+				TemperatureThresholdSpec: TemperatureThresholdSpec{Kind: NoTemperatureThreshold},
+			},
+		},
+		{
+			name: "scale target API versions",
+			spec: PowerCappingConfigSpec{
+				ScaleTargetRefs: []ScaleTargetReference{
+					{Kind: ScaleTargetKindStatefulSet, Name: "llm"},
+					{Kind: ScaleTargetKindHorizontalPodAutoscaler, Name: "llm"},
+				},
+			},
+			expected: PowerCappingConfigSpec{
+				Strategy:                 StrategyProportional,
+				PowerCappingSpec:         PowerCappingSpec{Kind: NoPowerCappingSpec},
+				TemperatureThresholdSpec: TemperatureThresholdSpec{Kind: NoTemperatureThreshold},
+				ScaleTargetRefs: []ScaleTargetReference{
+					{APIVersion: "apps/v1", Kind: ScaleTargetKindStatefulSet, Name: "llm"},
+					{APIVersion: "autoscaling/v2", Kind: ScaleTargetKindHorizontalPodAutoscaler, Name: "llm"},
+				},
+			},
+		},
+		{
+			name: "relative power cap follows the efficiency level",
+			spec: PowerCappingConfigSpec{
+				EfficiencyLevel:  "High",
+				PowerCappingSpec: PowerCappingSpec{Kind: RelativePowerCapOfPeakPowerConsumptionInPercentage},
+			},
+			expected: PowerCappingConfigSpec{
+				Strategy:        StrategyProportional,
+				EfficiencyLevel: EfficiencyLevelHigh,
+				PowerCappingSpec: PowerCappingSpec{
+					Kind: RelativePowerCapOfPeakPowerConsumptionInPercentage,
+					RelativePowerCapInPercentageSpec: RelativePowerCapInPercentageSpec{
+						SampleWindow: DefaultSampleWindowInSeconds,
+					},
+				},
+				TemperatureThresholdSpec: TemperatureThresholdSpec{Kind: NoTemperatureThreshold},
+			},
+		},
+		{
+			name: "explicit values are kept",
+			spec: PowerCappingConfigSpec{
+				EfficiencyLevel: EfficiencyLevelLow,
+				PowerCappingSpec: PowerCappingSpec{
+					Kind: RelativePowerCappingOfAveragePowerConsumptionInPercentage,
+					RelativePowerCapInPercentageSpec: RelativePowerCapInPercentageSpec{
+						PowerCapPercentage: 70,
+						SampleWindow:       300,
+					},
+				},
+				TemperatureThresholdSpec: TemperatureThresholdSpec{Kind: RelativeTemperatureThresholdOfPeakTemperatureInPercentage},
+			},
+			expected: PowerCappingConfigSpec{
+				Strategy:        StrategyProportional,
+				EfficiencyLevel: EfficiencyLevelLow,
+				PowerCappingSpec: PowerCappingSpec{
+					Kind: RelativePowerCappingOfAveragePowerConsumptionInPercentage,
+					RelativePowerCapInPercentageSpec: RelativePowerCapInPercentageSpec{
+						PowerCapPercentage: 70,
+						SampleWindow:       300,
+					},
+				},
+				TemperatureThresholdSpec: TemperatureThresholdSpec{
+					Kind: RelativeTemperatureThresholdOfPeakTemperatureInPercentage,
+					RelativeTemperatureThresholdInPercentageSpec: RelativeTemperatureThresholdInPercentageSpec{
+						SampleWindow: DefaultSampleWindowInSeconds,
+					},
+				},
+			},
+		},
+		{
+			name: "forecast defaults",
+			spec: PowerCappingConfigSpec{
+				Forecast: &PowerForecastSpec{StepInSeconds: 30, Proactive: true},
+			},
+			expected: PowerCappingConfigSpec{
+				Strategy:                 StrategyProportional,
+				PowerCappingSpec:         PowerCappingSpec{Kind: NoPowerCappingSpec},
+				TemperatureThresholdSpec: TemperatureThresholdSpec{Kind: NoTemperatureThreshold},
+				Forecast: &PowerForecastSpec{
+					Method:            ForecastMethodHoltWinters,
+					HorizonInSeconds:  DefaultForecastHorizonInSeconds,
+					LookbackInSeconds: DefaultForecastLookbackInSeconds,
+					StepInSeconds:     30,
+					Proactive:         true,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &PowerCappingConfig{Spec: tt.spec}
+			config.Default()
+			assert.Equal(t, tt.expected, config.Spec)
+		})
+	}
+}
+
+func TestPowerCappingConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    PowerCappingConfigSpec
+		wantErr bool
+	}{
+		{
+			name: "empty spec",
+			spec: PowerCappingConfigSpec{},
+		},
+		{
+			name: "valid relative power cap",
+			spec: PowerCappingConfigSpec{
+				WorkloadType:    "Training",
+				EfficiencyLevel: EfficiencyLevelMedium,
+				PowerCappingSpec: PowerCappingSpec{
+					Kind: RelativePowerCapOfPeakPowerConsumptionInPercentage,
+					RelativePowerCapInPercentageSpec: RelativePowerCapInPercentageSpec{
+						PowerCapPercentage: 100,
+						SampleWindow:       60,
+					},
+				},
+			},
+		},
+		{
+			name: "valid absolute temperature threshold",
+			spec: PowerCappingConfigSpec{
+				TemperatureThresholdSpec: TemperatureThresholdSpec{
+					Kind: AbsoluteTemperatureThresholdInCelsius,
+					AbsoluteTemperatureThresholdInCelsiusSpec: AbsoluteTemperatureThresholdInCelsiusSpec{TemperatureThresholdInCelsius: 85},
+				},
+			},
+		},
+		{
+			name: "valid selectors",
+			spec: PowerCappingConfigSpec{
+				Selector:          &metav1.LabelSelector{MatchLabels: map[string]string{"app": "llm"}},
+				NamespaceSelector: &metav1.LabelSelector{},
+			},
+		},
+		{
+			name: "invalid selector operator",
+			spec: PowerCappingConfigSpec{
+				Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "app", Operator: "Matches", Values: []string{"llm"}},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid aggregate scope",
+			spec: PowerCappingConfigSpec{
+				PowerCappingSpec: PowerCappingSpec{
+					Kind:                        AbsolutePowerCapInWatts,
+					AbsolutePowerCapInWattsSpec: AbsolutePowerCapInWattsSpec{PowerCapInWatts: 4000},
+					Scope:                       PowerCappingScopeAggregate,
+				},
+			},
+		},
+		{
+			name: "unknown scope",
+			spec: PowerCappingConfigSpec{
+				PowerCappingSpec: PowerCappingSpec{Scope: "Node"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid hysteresis",
+			spec: PowerCappingConfigSpec{
+				PowerCappingSpec: PowerCappingSpec{
+					Kind:                        AbsolutePowerCapInWatts,
+					AbsolutePowerCapInWattsSpec: AbsolutePowerCapInWattsSpec{PowerCapInWatts: 400},
+					HysteresisPercentage:        10,
+				},
+			},
+		},
+		{
+			name: "hysteresis out of range",
+			spec: PowerCappingConfigSpec{
+				PowerCappingSpec: PowerCappingSpec{HysteresisPercentage: 60},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid scaled object reference",
+			spec: PowerCappingConfigSpec{
+				ScaledObjectRefs: []ScaledObjectReference{{Metadata: ScaledObjectMetadata{Name: "llm"}}},
+			},
+		},
+		{
+			name: "unnamed scaled object reference",
+			spec: PowerCappingConfigSpec{
+				ScaledObjectRefs: []ScaledObjectReference{{Kind: "ScaledObject"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid scale target references",
+			spec: PowerCappingConfigSpec{
+				ScaleTargetRefs: []ScaleTargetReference{
+					{Kind: ScaleTargetKindDeployment, Name: "llm"},
+					{Kind: ScaleTargetKindHorizontalPodAutoscaler, Name: "llm"},
+				},
+			},
+		},
+		{
+			name:    "unknown strategy",
+			spec:    PowerCappingConfigSpec{Strategy: "Random"},
+			wantErr: true,
+		},
+		{
+			name: "DCGM metrics source",
+			spec: PowerCappingConfigSpec{MetricsSource: MetricsSourceDCGM},
+		},
+		{
+			name:    "unknown metrics source",
+			spec:    PowerCappingConfigSpec{MetricsSource: "Scaphandre"},
+			wantErr: true,
+		},
+		{
+			name: "negative weight",
+			spec: PowerCappingConfigSpec{
+				Strategy:        StrategyPriority,
+				ScaleTargetRefs: []ScaleTargetReference{{Kind: ScaleTargetKindDeployment, Name: "llm", Weight: -1}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported scale target kind",
+			spec: PowerCappingConfigSpec{
+				ScaleTargetRefs: []ScaleTargetReference{{Kind: "DaemonSet", Name: "llm"}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "unknown workload type",
+			spec:    PowerCappingConfigSpec{WorkloadType: "batch"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown efficiency level",
+			spec:    PowerCappingConfigSpec{EfficiencyLevel: "extreme"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown power capping kind",
+			spec:    PowerCappingConfigSpec{PowerCappingSpec: PowerCappingSpec{Kind: "Unknown"}},
+			wantErr: true,
+		},
+		{
+			name: "percentage above 100",
+			spec: PowerCappingConfigSpec{
+				PowerCappingSpec: PowerCappingSpec{
+					Kind:                             RelativePowerCapOfPeakPowerConsumptionInPercentage,
+					RelativePowerCapInPercentageSpec: RelativePowerCapInPercentageSpec{PowerCapPercentage: 120},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative sample window",
+			spec: PowerCappingConfigSpec{
+				TemperatureThresholdSpec: TemperatureThresholdSpec{
+					Kind: RelativeTemperatureThresholdOfAverageTemperatureInPercentage,
+					RelativeTemperatureThresholdInPercentageSpec: RelativeTemperatureThresholdInPercentageSpec{SampleWindow: -1},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "absolute kind with relative spec",
+			spec: PowerCappingConfigSpec{
+				PowerCappingSpec: PowerCappingSpec{
+					Kind:                             AbsolutePowerCapInWatts,
+					AbsolutePowerCapInWattsSpec:      AbsolutePowerCapInWattsSpec{PowerCapInWatts: 300},
+					RelativePowerCapInPercentageSpec: RelativePowerCapInPercentageSpec{PowerCapPercentage: 80},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "absolute kind without watts",
+			spec: PowerCappingConfigSpec{
+				PowerCappingSpec: PowerCappingSpec{Kind: AbsolutePowerCapInWatts},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid carbon aware power cap with intensity range",
+			spec: PowerCappingConfigSpec{
+				PowerCappingSpec: PowerCappingSpec{
+					Kind: CarbonAwarePowerCapInWatts,
+					CarbonAwarePowerCapSpec: CarbonAwarePowerCapSpec{
+						MinPowerCapInWatts: 200,
+						MaxPowerCapInWatts: 1000,
+						MinCarbonIntensity: 100,
+						MaxCarbonIntensity: 500,
+						Zone:               "DE",
+					},
+				},
+			},
+		},
+		{
+			name: "valid carbon aware power cap with carbon budget",
+			spec: PowerCappingConfigSpec{
+				PowerCappingSpec: PowerCappingSpec{
+					Kind:  CarbonAwarePowerCapInWatts,
+					Scope: PowerCappingScopeAggregate,
+					CarbonAwarePowerCapSpec: CarbonAwarePowerCapSpec{
+						MinPowerCapInWatts:         200,
+						MaxPowerCapInWatts:         1000,
+						CarbonBudgetInGramsPerHour: 150,
+					},
+				},
+			},
+		},
+		{
+			name: "carbon budget capping each pod",
+			spec: PowerCappingConfigSpec{
+				PowerCappingSpec: PowerCappingSpec{
+					Kind: CarbonAwarePowerCapInWatts,
+					CarbonAwarePowerCapSpec: CarbonAwarePowerCapSpec{
+						MinPowerCapInWatts:         200,
+						MaxPowerCapInWatts:         1000,
+						CarbonBudgetInGramsPerHour: 150,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "carbon aware power cap without scale",
+			spec: PowerCappingConfigSpec{
+				PowerCappingSpec: PowerCappingSpec{
+					Kind:                    CarbonAwarePowerCapInWatts,
+					CarbonAwarePowerCapSpec: CarbonAwarePowerCapSpec{MinPowerCapInWatts: 200, MaxPowerCapInWatts: 1000},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "carbon aware power cap with inverted range",
+			spec: PowerCappingConfigSpec{
+				PowerCappingSpec: PowerCappingSpec{
+					Kind: CarbonAwarePowerCapInWatts,
+					CarbonAwarePowerCapSpec: CarbonAwarePowerCapSpec{
+						MinPowerCapInWatts:         1000,
+						MaxPowerCapInWatts:         200,
+						CarbonBudgetInGramsPerHour: 150,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "absolute kind with carbon aware spec",
+			spec: PowerCappingConfigSpec{
+				PowerCappingSpec: PowerCappingSpec{
+					Kind:                        AbsolutePowerCapInWatts,
+					AbsolutePowerCapInWattsSpec: AbsolutePowerCapInWattsSpec{PowerCapInWatts: 300},
+					CarbonAwarePowerCapSpec:     CarbonAwarePowerCapSpec{MinPowerCapInWatts: 200},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid schedule",
+			spec: PowerCappingConfigSpec{
+				Schedule: &PowerCappingSchedule{
+					TimeZone: "Europe/Berlin",
+					Windows: []ScheduleWindow{
+						{Name: "peak", Days: "Mon-Fri", Start: "16:00", End: "21:00", PowerCapPercentage: 60},
+						{Name: "night", Start: "22:00", End: "06:00", PowerCapInWatts: 2000},
+					},
+				},
+			},
+		},
+		{
+			name: "schedule with unknown time zone",
+			spec: PowerCappingConfigSpec{
+				Schedule: &PowerCappingSchedule{TimeZone: "Mars/Olympus"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "schedule window with invalid times",
+			spec: PowerCappingConfigSpec{
+				Schedule: &PowerCappingSchedule{Windows: []ScheduleWindow{
+					{Name: "peak", Days: "Weekdays", Start: "4pm", End: "21:00", PowerCapPercentage: 60},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "schedule windows with the same name",
+			spec: PowerCappingConfigSpec{
+				Schedule: &PowerCappingSchedule{Windows: []ScheduleWindow{
+					{Name: "peak", Start: "16:00", End: "21:00", PowerCapPercentage: 60},
+					{Name: "peak", Start: "06:00", End: "08:00", PowerCapPercentage: 80},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "schedule window with percentage and watts",
+			spec: PowerCappingConfigSpec{
+				Schedule: &PowerCappingSchedule{Windows: []ScheduleWindow{
+					{Name: "peak", Start: "16:00", End: "21:00", PowerCapPercentage: 60, PowerCapInWatts: 500},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid node power cap",
+			spec: PowerCappingConfigSpec{
+				NodePowerCap: &NodePowerCapSpec{
+					NodeSelector:    &metav1.LabelSelector{MatchLabels: map[string]string{"rack": "a"}},
+					PowerCapInWatts: 1500,
+					Action:          NodePowerCapActionEvict,
+				},
+			},
+		},
+		{
+			name: "node power cap without node selector",
+			spec: PowerCappingConfigSpec{
+				NodePowerCap: &NodePowerCapSpec{PowerCapInWatts: 1500},
+			},
+			wantErr: true,
+		},
+		{
+			name: "node power cap with unknown action",
+			spec: PowerCappingConfigSpec{
+				NodePowerCap: &NodePowerCapSpec{
+					NodeSelector:    &metav1.LabelSelector{},
+					PowerCapInWatts: 1500,
+					Action:          "Shutdown",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid seasonal forecast",
+			spec: PowerCappingConfigSpec{
+				Forecast: &PowerForecastSpec{LookbackInSeconds: 172800, StepInSeconds: 300, SeasonInSeconds: 86400},
+			},
+		},
+		{
+			name: "forecast with unknown method",
+			spec: PowerCappingConfigSpec{
+				Forecast: &PowerForecastSpec{Method: "Prophet"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "forecast lookback shorter than two seasons",
+			spec: PowerCappingConfigSpec{
+				Forecast: &PowerForecastSpec{LookbackInSeconds: 3600, SeasonInSeconds: 86400},
+			},
+			wantErr: true,
+		},
+		{
+			name: "linear trend forecast with season",
+			spec: PowerCappingConfigSpec{
+				Forecast: &PowerForecastSpec{Method: ForecastMethodLinearTrend, LookbackInSeconds: 7200, SeasonInSeconds: 3600},
+			},
+			wantErr: true,
+		},
+		{
+			name: "no threshold with absolute spec",
+			spec: PowerCappingConfigSpec{
+				TemperatureThresholdSpec: TemperatureThresholdSpec{
+					Kind: NoTemperatureThreshold,
+					AbsoluteTemperatureThresholdInCelsiusSpec: AbsoluteTemperatureThresholdInCelsiusSpec{TemperatureThresholdInCelsius: 85},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &PowerCappingConfig{ObjectMeta: metav1.ObjectMeta{Name: "test"}, Spec: tt.spec}
+
+			_, err := config.ValidateCreate()
+			if tt.wantErr {
+				assert.True(t, apierrors.IsInvalid(err), "expected an Invalid error, got %v", err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			_, err = config.ValidateUpdate(&PowerCappingConfig{ObjectMeta: metav1.ObjectMeta{Name: "test"}})
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestPowerCappingConfigValidateUpdateWithoutSpecChange(t *testing.T) {
+	// A spec accepted by an older version of the validation.
+	invalid := PowerCappingConfigSpec{
+		PowerCappingSpec: PowerCappingSpec{
+			Kind:                             RelativePowerCapOfPeakPowerConsumptionInPercentage,
+			RelativePowerCapInPercentageSpec: RelativePowerCapInPercentageSpec{PowerCapPercentage: 120},
+		},
+	}
+	old := &PowerCappingConfig{ObjectMeta: metav1.ObjectMeta{Name: "test", Finalizers: []string{"climatik-project.io/finalizer"}}, Spec: invalid}
+
+	config := old.DeepCopy()
+	config.Finalizers = nil
+	_, err := config.ValidateUpdate(old)
+	assert.NoError(t, err, "metadata-only updates are accepted")
+
+	config = old.DeepCopy()
+	now := metav1.Now()
+	config.DeletionTimestamp = &now
+	config.Spec.WorkloadType = "unknown"
+	_, err = config.ValidateUpdate(old)
+	assert.NoError(t, err, "updates of a config being deleted are accepted")
+
+	config = old.DeepCopy()
+	config.Spec.WorkloadType = WorkloadTypeInference
+	_, err = config.ValidateUpdate(old)
+	assert.True(t, apierrors.IsInvalid(err), "expected an Invalid error, got %v", err)
+}
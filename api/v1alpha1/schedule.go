@@ -0,0 +1,141 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// weekdays maps the day names of cron to weekdays.
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// parseDays returns the weekdays selected by a cron day-of-week field made of
+// day names and ranges, e.g. Mon-Fri or Mon,Wed,Sat-Sun. An empty field or *
+// selects every day.
+func parseDays(days string) ([7]bool, error) {
+	var selected [7]bool
+	if days == "" || days == "*" {
+		for i := range selected {
+			selected[i] = true
+		}
+		return selected, nil
+	}
+	for _, part := range strings.Split(days, ",") {
+		first, last, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		if !isRange {
+			last = first
+		}
+		from, ok := weekdays[strings.ToLower(first)]
+		if !ok {
+			return selected, fmt.Errorf("unknown day %q", first)
+		}
+		to, ok := weekdays[strings.ToLower(last)]
+		if !ok {
+			return selected, fmt.Errorf("unknown day %q", last)
+		}
+		// Ranges may wrap around the week, e.g. Fri-Mon.
+		for day := from; ; day = (day + 1) % 7 {
+			selected[day] = true
+			if day == to {
+				break
+			}
+		}
+	}
+	return selected, nil
+}
+
+// parseTimeOfDay returns the minutes since midnight of a HH:MM time of day,
+// from 00:00 to 24:00.
+func parseTimeOfDay(value string) (int, error) {
+	var hours, minutes int
+	if _, err := fmt.Sscanf(value, "%d:%d", &hours, &minutes); err != nil || len(value) != 5 {
+		return 0, fmt.Errorf("%q is not a HH:MM time of day", value)
+	}
+	if hours < 0 || minutes < 0 || minutes > 59 || hours*60+minutes > 24*60 {
+		return 0, fmt.Errorf("%q is not a HH:MM time of day", value)
+	}
+	return hours*60 + minutes, nil
+}
+
+// Location returns the time zone of the windows of the schedule.
+func (s *PowerCappingSchedule) Location() (*time.Location, error) {
+	if s.TimeZone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(s.TimeZone)
+}
+
+// ActiveWindow returns the first window of the schedule active at now, or nil
+// when none is, along with the next time at which a window starts or ends.
+// The next time is zero when the schedule has no valid window. Windows that
+// cannot be parsed never apply.
+func (s *PowerCappingSchedule) ActiveWindow(now time.Time) (*ScheduleWindow, time.Time) {
+	location, err := s.Location()
+	if err != nil {
+		return nil, time.Time{}
+	}
+	local := now.In(location)
+	var active *ScheduleWindow
+	var next time.Time
+	for i := range s.Windows {
+		window := &s.Windows[i]
+		days, err := parseDays(window.Days)
+		if err != nil {
+			continue
+		}
+		start, err := parseTimeOfDay(window.Start)
+		if err != nil {
+			continue
+		}
+		end, err := parseTimeOfDay(window.End)
+		if err != nil {
+			continue
+		}
+		if end <= start {
+			end += 24 * 60
+		}
+		// A window started on the previous day may still be active, and the
+		// next start is at most a week away.
+		for offset := -1; offset <= 7; offset++ {
+			day := time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, location)
+			if !days[day.Weekday()] {
+				continue
+			}
+			from := time.Date(day.Year(), day.Month(), day.Day(), 0, start, 0, 0, location)
+			to := time.Date(day.Year(), day.Month(), day.Day(), 0, end, 0, 0, location)
+			if active == nil && !now.Before(from) && now.Before(to) {
+				active = window
+			}
+			for _, boundary := range []time.Time{from, to} {
+				if boundary.After(now) && (next.IsZero() || boundary.Before(next)) {
+					next = boundary
+				}
+			}
+		}
+	}
+	return active, next
+}
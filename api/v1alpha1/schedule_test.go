@@ -0,0 +1,104 @@
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDays(t *testing.T) {
+	tests := []struct {
+		days     string
+		expected []time.Weekday
+		wantErr  bool
+	}{
+		{days: "", expected: []time.Weekday{0, 1, 2, 3, 4, 5, 6}},
+		{days: "*", expected: []time.Weekday{0, 1, 2, 3, 4, 5, 6}},
+		{days: "Mon-Fri", expected: []time.Weekday{1, 2, 3, 4, 5}},
+		{days: "sat, SUN", expected: []time.Weekday{0, 6}},
+		{days: "Fri-Mon", expected: []time.Weekday{0, 1, 5, 6}},
+		{days: "Mon,Wed-Thu", expected: []time.Weekday{1, 3, 4}},
+		{days: "Monday", wantErr: true},
+		{days: "Mon-", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.days, func(t *testing.T) {
+			selected, err := parseDays(tt.days)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var days []time.Weekday
+			for day, ok := range selected {
+				if ok {
+					days = append(days, time.Weekday(day))
+				}
+			}
+			assert.Equal(t, tt.expected, days)
+		})
+	}
+}
+
+func TestParseTimeOfDay(t *testing.T) {
+	for value, expected := range map[string]int{"00:00": 0, "09:30": 570, "24:00": 1440} {
+		minutes, err := parseTimeOfDay(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, minutes, value)
+	}
+	for _, value := range []string{"9:30", "24:30", "12:60", "noon", ""} {
+		_, err := parseTimeOfDay(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestActiveWindow(t *testing.T) {
+	schedule := &PowerCappingSchedule{
+		TimeZone: "America/Los_Angeles",
+		Windows: []ScheduleWindow{
+			{Name: "peak", Days: "Mon-Fri", Start: "16:00", End: "21:00", PowerCapPercentage: 60},
+			{Name: "night", Start: "22:00", End: "06:00", PowerCapInWatts: 2000},
+			{Name: "evening", Start: "16:00", End: "23:00", PowerCapPercentage: 80},
+		},
+	}
+	location, err := schedule.Location()
+	require.NoError(t, err)
+	// 2024-05-06 is a Monday.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, time.May, day, hour, minute, 0, 0, location)
+	}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		expected string
+		next     time.Time
+	}{
+		{name: "before the peak", now: at(6, 12, 0), next: at(6, 16, 0)},
+		{name: "start of the peak", now: at(6, 16, 0), expected: "peak", next: at(6, 21, 0)},
+		{name: "first window wins", now: at(6, 20, 59), expected: "peak", next: at(6, 21, 0)},
+		{name: "later window after the peak", now: at(6, 21, 0), expected: "evening", next: at(6, 22, 0)},
+		{name: "window across midnight", now: at(7, 2, 0), expected: "night", next: at(7, 6, 0)},
+		{name: "no peak at the weekend", now: at(11, 17, 0), expected: "evening", next: at(11, 22, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, next := schedule.ActiveWindow(tt.now.UTC())
+			if tt.expected == "" {
+				assert.Nil(t, window)
+			} else {
+				require.NotNil(t, window)
+				assert.Equal(t, tt.expected, window.Name)
+			}
+			assert.True(t, tt.next.Equal(next), "expected next transition at %s, got %s", tt.next, next)
+		})
+	}
+}
+
+func TestActiveWindowWithoutWindows(t *testing.T) {
+	window, next := (&PowerCappingSchedule{}).ActiveWindow(time.Now())
+	assert.Nil(t, window)
+	assert.True(t, next.IsZero())
+}
@@ -21,7 +21,8 @@ limitations under the License.
 package v1alpha1
 
 import (
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -54,13 +55,233 @@ func (in *AbsoluteTemperatureThresholdInCelsiusSpec) DeepCopy() *AbsoluteTempera
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CarbonAwarePowerCapSpec) DeepCopyInto(out *CarbonAwarePowerCapSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CarbonAwarePowerCapSpec.
+func (in *CarbonAwarePowerCapSpec) DeepCopy() *CarbonAwarePowerCapSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CarbonAwarePowerCapSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePowerCapSpec) DeepCopyInto(out *NodePowerCapSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePowerCapSpec.
+func (in *NodePowerCapSpec) DeepCopy() *NodePowerCapSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePowerCapSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePowerStatus) DeepCopyInto(out *NodePowerStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePowerStatus.
+func (in *NodePowerStatus) DeepCopy() *NodePowerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePowerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodPowerShare) DeepCopyInto(out *PodPowerShare) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodPowerShare.
+func (in *PodPowerShare) DeepCopy() *PodPowerShare {
+	if in == nil {
+		return nil
+	}
+	out := new(PodPowerShare)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PowerBudget) DeepCopyInto(out *PowerBudget) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PowerBudget.
+func (in *PowerBudget) DeepCopy() *PowerBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(PowerBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PowerBudget) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PowerBudgetAllocation) DeepCopyInto(out *PowerBudgetAllocation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PowerBudgetAllocation.
+func (in *PowerBudgetAllocation) DeepCopy() *PowerBudgetAllocation {
+	if in == nil {
+		return nil
+	}
+	out := new(PowerBudgetAllocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PowerBudgetList) DeepCopyInto(out *PowerBudgetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PowerBudget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PowerBudgetList.
+func (in *PowerBudgetList) DeepCopy() *PowerBudgetList {
+	if in == nil {
+		return nil
+	}
+	out := new(PowerBudgetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PowerBudgetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PowerBudgetShare) DeepCopyInto(out *PowerBudgetShare) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PowerBudgetShare.
+func (in *PowerBudgetShare) DeepCopy() *PowerBudgetShare {
+	if in == nil {
+		return nil
+	}
+	out := new(PowerBudgetShare)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PowerBudgetSpec) DeepCopyInto(out *PowerBudgetSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigSelector != nil {
+		in, out := &in.ConfigSelector, &out.ConfigSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Shares != nil {
+		in, out := &in.Shares, &out.Shares
+		*out = make([]PowerBudgetShare, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PowerBudgetSpec.
+func (in *PowerBudgetSpec) DeepCopy() *PowerBudgetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PowerBudgetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PowerBudgetStatus) DeepCopyInto(out *PowerBudgetStatus) {
+	*out = *in
+	if in.Allocations != nil {
+		in, out := &in.Allocations, &out.Allocations
+		*out = make([]PowerBudgetAllocation, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastEvaluationTime != nil {
+		in, out := &in.LastEvaluationTime, &out.LastEvaluationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PowerBudgetStatus.
+func (in *PowerBudgetStatus) DeepCopy() *PowerBudgetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PowerBudgetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PowerCappingConfig) DeepCopyInto(out *PowerCappingConfig) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PowerCappingConfig.
@@ -118,6 +339,41 @@ func (in *PowerCappingConfigSpec) DeepCopyInto(out *PowerCappingConfigSpec) {
 	*out = *in
 	out.PowerCappingSpec = in.PowerCappingSpec
 	out.TemperatureThresholdSpec = in.TemperatureThresholdSpec
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScaledObjectRefs != nil {
+		in, out := &in.ScaledObjectRefs, &out.ScaledObjectRefs
+		*out = make([]ScaledObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.ScaleTargetRefs != nil {
+		in, out := &in.ScaleTargetRefs, &out.ScaleTargetRefs
+		*out = make([]ScaleTargetReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(PowerCappingSchedule)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodePowerCap != nil {
+		in, out := &in.NodePowerCap, &out.NodePowerCap
+		*out = new(NodePowerCapSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Forecast != nil {
+		in, out := &in.Forecast, &out.Forecast
+		*out = new(PowerForecastSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PowerCappingConfigSpec.
@@ -133,6 +389,41 @@ func (in *PowerCappingConfigSpec) DeepCopy() *PowerCappingConfigSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PowerCappingConfigStatus) DeepCopyInto(out *PowerCappingConfigStatus) {
 	*out = *in
+	if in.NextScheduleTransitionTime != nil {
+		in, out := &in.NextScheduleTransitionTime, &out.NextScheduleTransitionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PodPowerShares != nil {
+		in, out := &in.PodPowerShares, &out.PodPowerShares
+		*out = make([]PodPowerShare, len(*in))
+		copy(*out, *in)
+	}
+	if in.ScaleTargets != nil {
+		in, out := &in.ScaleTargets, &out.ScaleTargets
+		*out = make([]ScaleTargetStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]NodePowerStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.AlertedPods != nil {
+		in, out := &in.AlertedPods, &out.AlertedPods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastEvaluationTime != nil {
+		in, out := &in.LastEvaluationTime, &out.LastEvaluationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PowerCappingConfigStatus.
@@ -145,11 +436,32 @@ func (in *PowerCappingConfigStatus) DeepCopy() *PowerCappingConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PowerCappingSchedule) DeepCopyInto(out *PowerCappingSchedule) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]ScheduleWindow, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PowerCappingSchedule.
+func (in *PowerCappingSchedule) DeepCopy() *PowerCappingSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(PowerCappingSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PowerCappingSpec) DeepCopyInto(out *PowerCappingSpec) {
 	*out = *in
 	out.AbsolutePowerCapInWattsSpec = in.AbsolutePowerCapInWattsSpec
 	out.RelativePowerCapInPercentageSpec = in.RelativePowerCapInPercentageSpec
+	out.CarbonAwarePowerCapSpec = in.CarbonAwarePowerCapSpec
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PowerCappingSpec.
@@ -162,6 +474,21 @@ func (in *PowerCappingSpec) DeepCopy() *PowerCappingSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PowerForecastSpec) DeepCopyInto(out *PowerForecastSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PowerForecastSpec.
+func (in *PowerForecastSpec) DeepCopy() *PowerForecastSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PowerForecastSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RelativePowerCapInPercentageSpec) DeepCopyInto(out *RelativePowerCapInPercentageSpec) {
 	*out = *in
@@ -192,6 +519,82 @@ func (in *RelativeTemperatureThresholdInPercentageSpec) DeepCopy() *RelativeTemp
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleTargetReference) DeepCopyInto(out *ScaleTargetReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleTargetReference.
+func (in *ScaleTargetReference) DeepCopy() *ScaleTargetReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleTargetReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleTargetStatus) DeepCopyInto(out *ScaleTargetStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleTargetStatus.
+func (in *ScaleTargetStatus) DeepCopy() *ScaleTargetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleTargetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaledObjectMetadata) DeepCopyInto(out *ScaledObjectMetadata) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaledObjectMetadata.
+func (in *ScaledObjectMetadata) DeepCopy() *ScaledObjectMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaledObjectMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaledObjectReference) DeepCopyInto(out *ScaledObjectReference) {
+	*out = *in
+	out.Metadata = in.Metadata
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaledObjectReference.
+func (in *ScaledObjectReference) DeepCopy() *ScaledObjectReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaledObjectReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleWindow) DeepCopyInto(out *ScheduleWindow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleWindow.
+func (in *ScheduleWindow) DeepCopy() *ScheduleWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TemperatureThresholdSpec) DeepCopyInto(out *TemperatureThresholdSpec) {
 	*out = *in
@@ -19,7 +19,10 @@ package main
 import (
 	"crypto/tls"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -35,8 +38,13 @@ import (
 
 	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
 	"github.com/Climatik-Project/Climatik-Project/internal/alert"
+	"github.com/Climatik-Project/Climatik-Project/internal/carbon"
+	"github.com/Climatik-Project/Climatik-Project/internal/config"
 	"github.com/Climatik-Project/Climatik-Project/internal/controller"
+	"github.com/Climatik-Project/Climatik-Project/internal/metrics"
+	"github.com/Climatik-Project/Climatik-Project/internal/planner"
 	"github.com/joho/godotenv"
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -58,6 +66,14 @@ func main() {
 	// var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var plannerAddress string
+	var plannerTimeout time.Duration
+	var carbonSource, carbonFile, carbonURL, carbonToken, carbonZone string
+	var carbonCacheTTL time.Duration
+	var powerMetricsSource, powerMetricsFile string
+	var powerMetricsReplay bool
+	var configFile string
+	var configReloadInterval time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	// flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
@@ -67,19 +83,57 @@ func main() {
 		"If set the metrics endpoint is served securely")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&plannerAddress, "planner-address", os.Getenv("PLANNER_ADDRESS"),
+		"The address of the Planner gRPC service. If empty, replicas are computed by the controller.")
+	flag.DurationVar(&plannerTimeout, "planner-timeout", planner.DefaultTimeout,
+		"How long to wait for the Planner before computing replicas locally.")
+	flag.StringVar(&carbonSource, "carbon-intensity-source", os.Getenv("CARBON_INTENSITY_SOURCE"),
+		"The source of the carbon intensity followed by carbon-aware power caps: file, electricitymaps or watttime.")
+	flag.StringVar(&carbonFile, "carbon-intensity-file", "",
+		"The CSV or JSON time series of carbon intensities read by the file source.")
+	flag.StringVar(&carbonURL, "carbon-intensity-url", "",
+		"The base URL of the carbon intensity API. Defaults to the public API of the source.")
+	flag.StringVar(&carbonToken, "carbon-intensity-token", os.Getenv("CARBON_INTENSITY_TOKEN"),
+		"The token authenticating to the carbon intensity API.")
+	flag.StringVar(&carbonZone, "carbon-zone", os.Getenv("CARBON_ZONE"),
+		"The grid zone of the carbon intensity when a config names none, e.g. DE or CAISO_NORTH.")
+	flag.DurationVar(&carbonCacheTTL, "carbon-intensity-cache-ttl", 5*time.Minute,
+		"How long a carbon intensity is reused before it is read again.")
+	flag.StringVar(&powerMetricsSource, "power-metrics-source", os.Getenv("POWER_METRICS_SOURCE"),
+		"The source of power and temperature metrics when a config selects none: Kepler, DCGM or File. Defaults to Kepler.")
+	flag.StringVar(&powerMetricsFile, "power-metrics-file", "",
+		"The CSV or JSON time series of power and temperatures read by the File source.")
+	flag.BoolVar(&powerMetricsReplay, "power-metrics-replay", false,
+		"If set, the power metrics file is replayed from the start of the manager instead of read at its own times.")
+	flag.StringVar(&configFile, "config", os.Getenv("OPERATOR_CONFIG"),
+		"The operator configuration file. If empty, the configuration is read from the environment and .env.")
+	flag.DurationVar(&configReloadInterval, "config-reload-interval", config.DefaultReloadInterval,
+		"How often the operator configuration file is read for changes.")
 	opts := zap.Options{
 		Development: true,
 	}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
-	err := godotenv.Load()
-	if err != nil {
-		setupLog.Error(err, "Error loading .env file")
-	}
-
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	var operatorConfig *config.OperatorConfig
+	var reloader *config.Reloader
+	if configFile != "" {
+		reloader = &config.Reloader{Path: configFile, Interval: configReloadInterval}
+		var err error
+		operatorConfig, err = reloader.Load()
+		if err != nil {
+			setupLog.Error(err, "unable to load operator configuration", "file", configFile)
+			os.Exit(1)
+		}
+		setupLog.Info("operator configuration loaded", "file", configFile)
+	} else {
+		if err := godotenv.Load(); err != nil {
+			setupLog.Error(err, "Error loading .env file")
+		}
+		operatorConfig = config.FromEnvironment()
+	}
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancelation and
@@ -100,12 +154,15 @@ func main() {
 		TLSOpts: tlsOpts,
 	})
 
+	podWatches := &controller.PodWatches{}
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
 		Metrics: metricsserver.Options{
 			BindAddress:   metricsAddr,
 			SecureServing: secureMetrics,
 			TLSOpts:       tlsOpts,
+			// The pods watched between reconciles, for debugging.
+			ExtraHandlers: map[string]http.Handler{"/debug/pod-watches": podWatches},
 		},
 		WebhookServer: webhookServer,
 		// HealthProbeBindAddress: probeAddr,
@@ -129,41 +186,102 @@ func main() {
 	}
 	setupLog.Info("manager created")
 
-	alertConfig := map[string]map[string]string{
-		"prometheus": {
-			"prometheusAddress": os.Getenv("PROMETHEUS_HOST"),
-		},
-		"gitops": {
-			"repoURL": os.Getenv("GITOPS_REPO_URL"),
-			"repoDir": os.Getenv("GITOPS_REPO_DIR"),
-		},
-		"slack": {
-			"webhookURL": os.Getenv("SLACK_WEBHOOK_URL"),
-			"token":      os.Getenv("SLACK_TOKEN"),
-			"channel":    os.Getenv("SLACK_CHANNEL"),
-		},
-	}
-
-	alertService, err := alert.NewAlertService(alertConfig)
+	alertService, err := alert.NewAlertService(operatorConfig.AlertManagers())
 	if err != nil {
 		setupLog.Error(err, "unable to create alert service")
 		os.Exit(1)
 	}
 	setupLog.Info("alert service created")
+	prometheusClient, err := metrics.NewReloadableClient(operatorConfig.Prometheus.URL)
+	if err != nil {
+		setupLog.Error(err, "unable to create Prometheus client", "url", operatorConfig.Prometheus.URL)
+		os.Exit(1)
+	}
+	setupLog.Info("Prometheus client created", "url", operatorConfig.Prometheus.URL)
+	metricNames := metrics.NewSharedNames(operatorConfig.MetricNames)
+	efficiencyLevels := config.NewSharedEfficiencyLevels(operatorConfig.EfficiencyLevels)
 	client := mgr.GetClient()
 	scheme := mgr.GetScheme()
 	setupLog.Info("client and scheme created")
 	pcController := (&controller.PowerCappingConfigReconciler{
-		Client:       client,
-		Scheme:       scheme,
-		AlertService: alertService,
+		Client:           client,
+		Scheme:           scheme,
+		AlertService:     alertService,
+		PrometheusClient: prom_v1.NewAPI(prometheusClient),
+		MetricNames:      metricNames,
+		EfficiencyLevels: efficiencyLevels,
+		PlannerTimeout:   plannerTimeout,
+		PodWatches:       podWatches,
 	})
+	if plannerAddress != "" {
+		plannerClient, plannerConn, err := planner.NewClient(plannerAddress)
+		if err != nil {
+			setupLog.Error(err, "unable to create planner client", "address", plannerAddress)
+			os.Exit(1)
+		}
+		defer plannerConn.Close()
+		pcController.Planner = plannerClient
+		setupLog.Info("planner client created", "address", plannerAddress)
+	}
+	if carbonSource != "" {
+		provider, err := newCarbonIntensityProvider(carbonSource, carbonFile, carbonURL, carbonToken)
+		if err != nil {
+			setupLog.Error(err, "unable to create carbon intensity provider", "source", carbonSource)
+			os.Exit(1)
+		}
+		pcController.CarbonIntensityProvider = carbon.NewCachedProvider(provider, carbonCacheTTL)
+		pcController.CarbonZone = carbonZone
+		setupLog.Info("carbon intensity provider created", "source", carbonSource, "zone", carbonZone)
+	}
+	metricsSources, err := newPowerMetricsSources(powerMetricsSource, powerMetricsFile, powerMetricsReplay)
+	if err != nil {
+		setupLog.Error(err, "unable to create power metrics sources", "source", powerMetricsSource)
+		os.Exit(1)
+	}
+	pcController.MetricsSources = metricsSources
+	pcController.MetricsSource = powerMetricsSource
 	setupLog.Info("reconciler created")
 	if err = pcController.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "PowerCappingConfig")
 		os.Exit(1)
 	}
 	setupLog.Info("controller created")
+	if err = (&controller.PowerBudgetReconciler{
+		Client:           client,
+		Scheme:           scheme,
+		PrometheusClient: pcController.PrometheusClient,
+		MetricsSources:   metricsSources,
+		MetricsSource:    powerMetricsSource,
+		MetricNames:      metricNames,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PowerBudget")
+		os.Exit(1)
+	}
+	if reloader != nil {
+		// The Prometheus client, the alert managers, the efficiency levels and
+		// the metric names follow the configuration file without a restart.
+		reloader.OnChange = func(operatorConfig *config.OperatorConfig) {
+			if err := prometheusClient.SetAddress(operatorConfig.Prometheus.URL); err != nil {
+				setupLog.Error(err, "unable to update Prometheus client", "url", operatorConfig.Prometheus.URL)
+			}
+			if err := alertService.Reload(operatorConfig.AlertManagers()); err != nil {
+				setupLog.Error(err, "unable to update alert service")
+			}
+			efficiencyLevels.Store(operatorConfig.EfficiencyLevels)
+			metricNames.Store(operatorConfig.MetricNames)
+		}
+		if err := mgr.Add(reloader); err != nil {
+			setupLog.Error(err, "unable to add operator configuration reloader")
+			os.Exit(1)
+		}
+	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&powercappingv1alpha1.PowerCappingConfig{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "PowerCappingConfig")
+			os.Exit(1)
+		}
+		setupLog.Info("webhook created")
+	}
 	//+kubebuilder:scaffold:builder
 
 	// if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -181,3 +299,38 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// newCarbonIntensityProvider returns the carbon intensity provider of source.
+func newCarbonIntensityProvider(source, file, url, token string) (carbon.CarbonIntensityProvider, error) {
+	if source == "file" {
+		return carbon.NewFileProvider(file)
+	}
+	return carbon.NewHTTPProvider(source, url, token)
+}
+
+// newPowerMetricsSources returns the metrics sources that do not read the
+// Prometheus of the controller: the File source when a file is given. The
+// default source must be one of Kepler, DCGM or a configured File source.
+func newPowerMetricsSources(source, file string, replay bool) (map[string]metrics.PowerMetricsSource, error) {
+	sources := make(map[string]metrics.PowerMetricsSource)
+	if file != "" {
+		fileSource, err := metrics.NewFileSource(file)
+		if err != nil {
+			return nil, err
+		}
+		if replay {
+			fileSource.Replay(time.Now())
+		}
+		sources[metrics.File] = fileSource
+	}
+	switch source {
+	case "", metrics.Kepler, metrics.DCGM:
+	case metrics.File:
+		if file == "" {
+			return nil, fmt.Errorf("the File power metrics source needs --power-metrics-file")
+		}
+	default:
+		return nil, fmt.Errorf("unknown power metrics source %q", source)
+	}
+	return sources, nil
+}
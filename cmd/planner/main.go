@@ -0,0 +1,114 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"net"
+	"os"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"google.golang.org/grpc"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/Climatik-Project/Climatik-Project/internal/config"
+	"github.com/Climatik-Project/Climatik-Project/internal/metrics"
+	"github.com/Climatik-Project/Climatik-Project/internal/planner"
+)
+
+var setupLog = ctrl.Log.WithName("setup")
+
+func main() {
+	var address string
+	var prometheusURL string
+	var configFile string
+	var configReloadInterval time.Duration
+	server := planner.NewServer(nil)
+	flag.StringVar(&address, "address", ":9999", "The address the Planner gRPC service binds to.")
+	flag.StringVar(&prometheusURL, "prometheus-url", getEnv("PROM_URL", config.DefaultPrometheusURL),
+		"The URL of the Prometheus server holding the power and load metrics, unless given by --config.")
+	flag.StringVar(&configFile, "config", os.Getenv("OPERATOR_CONFIG"),
+		"The operator configuration file giving the Prometheus URL and the metric names read.")
+	flag.DurationVar(&configReloadInterval, "config-reload-interval", config.DefaultReloadInterval,
+		"How often the operator configuration file is read for changes.")
+	flag.DurationVar(&server.WatchInterval, "watch-interval", planner.DefaultWatchInterval,
+		"How often the plans of subscribers are re-evaluated.")
+	opts := zap.Options{
+		Development: true,
+	}
+	opts.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+
+	var reloader *config.Reloader
+	if configFile != "" {
+		reloader = &config.Reloader{Path: configFile, Interval: configReloadInterval}
+		operatorConfig, err := reloader.Load()
+		if err != nil {
+			setupLog.Error(err, "unable to load operator configuration", "file", configFile)
+			os.Exit(1)
+		}
+		prometheusURL = operatorConfig.Prometheus.URL
+		server.Names.Store(operatorConfig.MetricNames)
+	}
+	promClient, err := metrics.NewReloadableClient(prometheusURL)
+	if err != nil {
+		setupLog.Error(err, "unable to create Prometheus client", "url", prometheusURL)
+		os.Exit(1)
+	}
+	server.Prometheus = prom_v1.NewAPI(promClient)
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		setupLog.Error(err, "unable to listen", "address", address)
+		os.Exit(1)
+	}
+	grpcServer := grpc.NewServer()
+	planner.RegisterPlannerServer(grpcServer, server)
+
+	ctx := ctrl.SetupSignalHandler()
+	go func() {
+		<-ctx.Done()
+		setupLog.Info("stopping planner")
+		grpcServer.GracefulStop()
+	}()
+	if reloader != nil {
+		reloader.OnChange = func(operatorConfig *config.OperatorConfig) {
+			if err := promClient.SetAddress(operatorConfig.Prometheus.URL); err != nil {
+				setupLog.Error(err, "unable to update Prometheus client", "url", operatorConfig.Prometheus.URL)
+			}
+			server.Names.Store(operatorConfig.MetricNames)
+		}
+		go reloader.Start(ctx)
+	}
+
+	setupLog.Info("starting planner", "address", address, "prometheus", prometheusURL)
+	if err := grpcServer.Serve(listener); err != nil {
+		setupLog.Error(err, "problem running planner")
+		os.Exit(1)
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
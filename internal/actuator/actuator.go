@@ -0,0 +1,57 @@
+// actuator.go
+package actuator
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reference identifies an object in a namespace by its API version and kind.
+type Reference struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+}
+
+// Workload is the scalable workload whose pods consume the capped power.
+type Workload struct {
+	Reference
+	// Selector selects the pods of the workload.
+	Selector labels.Selector
+	// MinReplicas is the lowest number of replicas the workload may be bounded to.
+	MinReplicas int32
+	// MaxReplicas is the upper bound of replicas of the workload before it
+	// was first limited, or zero when it has none.
+	MaxReplicas int32
+}
+
+// Actuator bounds the replicas of a workload to keep its power under a cap.
+type Actuator interface {
+	// Workload resolves the workload whose replicas are bounded.
+	Workload(ctx context.Context) (*Workload, error)
+	// LimitReplicas sets the upper bound of replicas of the workload. The
+	// bound never exceeds the value found before the first limit.
+	LimitReplicas(ctx context.Context, maxReplicas int32) error
+	// Restore sets the upper bound of replicas back to the value found before
+	// the first limit. It does nothing when the workload was never limited.
+	Restore(ctx context.Context) error
+}
+
+// NewActuator returns the actuator of the referenced object based on its kind.
+// An empty kind refers to a KEDA ScaledObject.
+func NewActuator(c client.Client, ref Reference) (Actuator, error) {
+	switch ref.Kind {
+	case "", ScaledObjectKind:
+		return NewScaledObjectActuator(c, ref), nil
+	case DeploymentKind, StatefulSetKind:
+		return NewReplicasActuator(c, ref), nil
+	case HorizontalPodAutoscalerKind:
+		return NewHorizontalPodAutoscalerActuator(c, ref), nil
+	default:
+		return nil, fmt.Errorf("unsupported scale target kind: %s", ref.Kind)
+	}
+}
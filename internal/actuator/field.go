@@ -0,0 +1,218 @@
+// field.go
+package actuator
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// OriginalReplicasAnnotation records the value of the bounded replicas field
+// before it was first limited. An empty value records an unset field.
+const OriginalReplicasAnnotation = "climatik-project.io/original-replicas"
+
+// fieldActuator bounds an integer replicas field of an object. The object is
+// read and patched as unstructured so that one implementation serves
+// workloads and autoscalers alike, including those of APIs the operator does
+// not depend on.
+type fieldActuator struct {
+	client client.Client
+	ref    Reference
+	// field is the path of the bounded replicas field.
+	field []string
+	// minField is the path of the lowest number of replicas, if any, and
+	// defaultMin its value when unset.
+	minField   []string
+	defaultMin int64
+	// scaleTargetField is the path of the reference to the scaled workload,
+	// or nil when the object is the workload itself.
+	scaleTargetField []string
+}
+
+// Workload returns the workload scaled by the object, bounded by its minimum
+// and the value of the replicas field before the first limit.
+func (a *fieldActuator) Workload(ctx context.Context) (*Workload, error) {
+	obj, err := a.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	target := a.ref
+	if a.scaleTargetField != nil {
+		targetRef, found, err := unstructured.NestedStringMap(obj.Object, a.scaleTargetField...)
+		if err != nil || !found || targetRef["name"] == "" {
+			return nil, fmt.Errorf("%s %s/%s has no scaleTargetRef", a.ref.Kind, a.ref.Namespace, a.ref.Name)
+		}
+		target = Reference{
+			APIVersion: targetRef["apiVersion"],
+			Kind:       targetRef["kind"],
+			Namespace:  a.ref.Namespace,
+			Name:       targetRef["name"],
+		}
+		if target.APIVersion == "" {
+			target.APIVersion = appsAPIVersion
+		}
+		if target.Kind == "" {
+			target.Kind = DeploymentKind
+		}
+	}
+
+	selector, err := podSelector(ctx, a.client, target)
+	if err != nil {
+		return nil, err
+	}
+	minReplicas := a.defaultMin
+	if a.minField != nil {
+		if value, found, _ := unstructured.NestedInt64(obj.Object, a.minField...); found {
+			minReplicas = value
+		}
+	}
+	// Report the bound set by the owner of the object rather than our limit.
+	maxReplicas, _, _ := unstructured.NestedInt64(obj.Object, a.field...)
+	original, recorded, err := a.original(obj)
+	if err != nil {
+		return nil, err
+	}
+	if recorded {
+		maxReplicas = 0
+		if original != nil {
+			maxReplicas = *original
+		}
+	}
+	return &Workload{
+		Reference:   target,
+		Selector:    selector,
+		MinReplicas: int32(minReplicas),
+		MaxReplicas: int32(maxReplicas),
+	}, nil
+}
+
+// LimitReplicas patches the replicas field to maxReplicas, or to the original
+// value when that is lower, recording the original value on the first limit.
+func (a *fieldActuator) LimitReplicas(ctx context.Context, maxReplicas int32) error {
+	obj, err := a.get(ctx)
+	if err != nil {
+		return err
+	}
+	current, found, _ := unstructured.NestedInt64(obj.Object, a.field...)
+	original, recorded, err := a.original(obj)
+	if err != nil {
+		return err
+	}
+
+	limit := int64(maxReplicas)
+	switch {
+	case recorded && original != nil:
+		limit = min(limit, *original)
+	case !recorded && found:
+		limit = min(limit, current)
+	}
+	if recorded && found && current == limit {
+		return nil
+	}
+
+	patch := client.MergeFrom(obj.DeepCopy())
+	if !recorded {
+		value := ""
+		if found {
+			value = strconv.FormatInt(current, 10)
+		}
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string, 1)
+		}
+		annotations[OriginalReplicasAnnotation] = value
+		obj.SetAnnotations(annotations)
+	}
+	if err := unstructured.SetNestedField(obj.Object, limit, a.field...); err != nil {
+		return err
+	}
+	if err := a.client.Patch(ctx, obj, patch); err != nil {
+		return fmt.Errorf("failed to patch %s of %s %s/%s: %w", a.field[len(a.field)-1], a.ref.Kind, a.ref.Namespace, a.ref.Name, err)
+	}
+	return nil
+}
+
+// Restore patches the replicas field back to its recorded original value and
+// removes the record.
+func (a *fieldActuator) Restore(ctx context.Context) error {
+	obj, err := a.get(ctx)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	original, recorded, err := a.original(obj)
+	if err != nil || !recorded {
+		return err
+	}
+
+	patch := client.MergeFrom(obj.DeepCopy())
+	annotations := obj.GetAnnotations()
+	delete(annotations, OriginalReplicasAnnotation)
+	obj.SetAnnotations(annotations)
+	if original != nil {
+		if err := unstructured.SetNestedField(obj.Object, *original, a.field...); err != nil {
+			return err
+		}
+	} else {
+		unstructured.RemoveNestedField(obj.Object, a.field...)
+	}
+	if err := a.client.Patch(ctx, obj, patch); err != nil {
+		return fmt.Errorf("failed to restore %s of %s %s/%s: %w", a.field[len(a.field)-1], a.ref.Kind, a.ref.Namespace, a.ref.Name, err)
+	}
+	return nil
+}
+
+// original returns the recorded original value of the replicas field, nil
+// when it was unset, and whether a value is recorded at all.
+func (a *fieldActuator) original(obj *unstructured.Unstructured) (*int64, bool, error) {
+	value, recorded := obj.GetAnnotations()[OriginalReplicasAnnotation]
+	if !recorded || value == "" {
+		return nil, recorded, nil
+	}
+	original, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, true, fmt.Errorf("invalid %s annotation on %s %s/%s: %w", OriginalReplicasAnnotation, a.ref.Kind, a.ref.Namespace, a.ref.Name, err)
+	}
+	return &original, true, nil
+}
+
+func (a *fieldActuator) get(ctx context.Context) (*unstructured.Unstructured, error) {
+	obj, err := getUnstructured(ctx, a.client, a.ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %s/%s: %w", a.ref.Kind, a.ref.Namespace, a.ref.Name, err)
+	}
+	return obj, nil
+}
+
+// podSelector returns the pod selector of a workload from its spec.selector.
+func podSelector(ctx context.Context, c client.Client, ref Reference) (labels.Selector, error) {
+	workload, err := getUnstructured(ctx, c, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %s/%s: %w", ref.Kind, ref.Namespace, ref.Name, err)
+	}
+	selectorMap, found, err := unstructured.NestedMap(workload.Object, "spec", "selector")
+	if err != nil || !found {
+		return nil, fmt.Errorf("%s %s/%s has no pod selector", ref.Kind, ref.Namespace, ref.Name)
+	}
+	selector := &metav1.LabelSelector{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(selectorMap, selector); err != nil {
+		return nil, err
+	}
+	return metav1.LabelSelectorAsSelector(selector)
+}
+
+func getUnstructured(ctx context.Context, c client.Client, ref Reference) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind))
+	if err := c.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
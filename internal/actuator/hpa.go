@@ -0,0 +1,29 @@
+// hpa.go
+package actuator
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	HorizontalPodAutoscalerKind = "HorizontalPodAutoscaler"
+
+	autoscalingAPIVersion = "autoscaling/v2"
+)
+
+// NewHorizontalPodAutoscalerActuator returns an actuator that bounds the
+// scale target of a HorizontalPodAutoscaler through its maxReplicas. An empty
+// API version defaults to autoscaling/v2.
+func NewHorizontalPodAutoscalerActuator(c client.Client, ref Reference) Actuator {
+	if ref.APIVersion == "" {
+		ref.APIVersion = autoscalingAPIVersion
+	}
+	return &fieldActuator{
+		client:           c,
+		ref:              ref,
+		field:            []string{"spec", "maxReplicas"},
+		minField:         []string{"spec", "minReplicas"},
+		defaultMin:       1,
+		scaleTargetField: []string{"spec", "scaleTargetRef"},
+	}
+}
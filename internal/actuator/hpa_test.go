@@ -0,0 +1,53 @@
+package actuator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newHorizontalPodAutoscaler(name, target string, maxReplicas int32) *autoscalingv2.HorizontalPodAutoscaler {
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: target},
+			MaxReplicas:    maxReplicas,
+		},
+	}
+}
+
+func TestHorizontalPodAutoscalerActuatorWorkload(t *testing.T) {
+	c := newFakeClient(t,
+		newHorizontalPodAutoscaler("llm", "llm-server", 8),
+		newDeployment("llm-server", map[string]string{"app": "llm"}),
+	)
+	actuator := NewHorizontalPodAutoscalerActuator(c, Reference{Kind: HorizontalPodAutoscalerKind, Namespace: "default", Name: "llm"})
+
+	workload, err := actuator.Workload(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Reference{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "llm-server"}, workload.Reference)
+	assert.Equal(t, int32(1), workload.MinReplicas)
+	assert.Equal(t, int32(8), workload.MaxReplicas)
+}
+
+func TestHorizontalPodAutoscalerActuatorLimitAndRestore(t *testing.T) {
+	c := newFakeClient(t, newHorizontalPodAutoscaler("llm", "llm-server", 8))
+	actuator := NewHorizontalPodAutoscalerActuator(c, Reference{Kind: HorizontalPodAutoscalerKind, Namespace: "default", Name: "llm"})
+	key := client.ObjectKey{Namespace: "default", Name: "llm"}
+
+	require.NoError(t, actuator.LimitReplicas(context.Background(), 3))
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+	require.NoError(t, c.Get(context.Background(), key, hpa))
+	assert.Equal(t, int32(3), hpa.Spec.MaxReplicas)
+
+	require.NoError(t, actuator.Restore(context.Background()))
+	hpa = &autoscalingv2.HorizontalPodAutoscaler{}
+	require.NoError(t, c.Get(context.Background(), key, hpa))
+	assert.Equal(t, int32(8), hpa.Spec.MaxReplicas)
+	assert.NotContains(t, hpa.Annotations, OriginalReplicasAnnotation)
+}
@@ -0,0 +1,32 @@
+// keda.go
+package actuator
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ScaledObjectAPIVersion and ScaledObjectKind identify KEDA ScaledObjects.
+	ScaledObjectAPIVersion = "keda.sh/v1alpha1"
+	ScaledObjectKind       = "ScaledObject"
+)
+
+// NewScaledObjectActuator returns an actuator that bounds the scale target of
+// a KEDA ScaledObject through its maxReplicaCount. ScaledObjects are read and
+// patched as unstructured objects so that the operator does not depend on
+// KEDA's API. An empty API version or kind defaults to a KEDA ScaledObject.
+func NewScaledObjectActuator(c client.Client, ref Reference) Actuator {
+	if ref.APIVersion == "" {
+		ref.APIVersion = ScaledObjectAPIVersion
+	}
+	if ref.Kind == "" {
+		ref.Kind = ScaledObjectKind
+	}
+	return &fieldActuator{
+		client:           c,
+		ref:              ref,
+		field:            []string{"spec", "maxReplicaCount"},
+		minField:         []string{"spec", "minReplicaCount"},
+		scaleTargetField: []string{"spec", "scaleTargetRef"},
+	}
+}
@@ -0,0 +1,145 @@
+package actuator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var scaledObjectGVK = schema.FromAPIVersionAndKind(ScaledObjectAPIVersion, ScaledObjectKind)
+
+func newScaledObject(name, target string, minReplicas, maxReplicas int64) *unstructured.Unstructured {
+	scaledObject := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"scaleTargetRef":  map[string]interface{}{"name": target},
+			"minReplicaCount": minReplicas,
+			"maxReplicaCount": maxReplicas,
+		},
+	}}
+	scaledObject.SetGroupVersionKind(scaledObjectGVK)
+	scaledObject.SetNamespace("default")
+	scaledObject.SetName(name)
+	return scaledObject
+}
+
+func newDeployment(name string, podLabels map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+		},
+	}
+}
+
+func newFakeClient(t *testing.T, objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	scheme.AddKnownTypeWithName(scaledObjectGVK, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(scaledObjectGVK.GroupVersion().WithKind(ScaledObjectKind+"List"), &unstructured.UnstructuredList{})
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func TestScaledObjectActuatorWorkload(t *testing.T) {
+	c := newFakeClient(t,
+		newScaledObject("llm", "llm-server", 1, 10),
+		newDeployment("llm-server", map[string]string{"app": "llm"}),
+	)
+	actuator := NewScaledObjectActuator(c, Reference{Namespace: "default", Name: "llm"})
+
+	workload, err := actuator.Workload(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Reference{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "llm-server"}, workload.Reference)
+	assert.True(t, workload.Selector.Matches(labels.Set{"app": "llm"}))
+	assert.Equal(t, int32(1), workload.MinReplicas)
+	assert.Equal(t, int32(10), workload.MaxReplicas)
+}
+
+func TestScaledObjectActuatorLimitReplicas(t *testing.T) {
+	c := newFakeClient(t, newScaledObject("llm", "llm-server", 1, 10))
+	actuator := NewScaledObjectActuator(c, Reference{Namespace: "default", Name: "llm"})
+
+	require.NoError(t, actuator.LimitReplicas(context.Background(), 4))
+
+	scaledObject := getScaledObject(t, c, "llm")
+	maxReplicas, _, _ := unstructured.NestedInt64(scaledObject.Object, "spec", "maxReplicaCount")
+	assert.Equal(t, int64(4), maxReplicas)
+}
+
+func TestScaledObjectActuatorLimitReplicasKeepsOriginalBound(t *testing.T) {
+	c := newFakeClient(t, newScaledObject("llm", "llm-server", 1, 10))
+	actuator := NewScaledObjectActuator(c, Reference{Namespace: "default", Name: "llm"})
+
+	require.NoError(t, actuator.LimitReplicas(context.Background(), 4))
+	require.NoError(t, actuator.LimitReplicas(context.Background(), 20))
+
+	scaledObject := getScaledObject(t, c, "llm")
+	maxReplicas, _, _ := unstructured.NestedInt64(scaledObject.Object, "spec", "maxReplicaCount")
+	assert.Equal(t, int64(10), maxReplicas)
+	assert.Equal(t, "10", scaledObject.GetAnnotations()[OriginalReplicasAnnotation])
+}
+
+func TestScaledObjectActuatorWorkloadReportsOriginalBound(t *testing.T) {
+	c := newFakeClient(t,
+		newScaledObject("llm", "llm-server", 1, 10),
+		newDeployment("llm-server", map[string]string{"app": "llm"}),
+	)
+	actuator := NewScaledObjectActuator(c, Reference{Namespace: "default", Name: "llm"})
+
+	require.NoError(t, actuator.LimitReplicas(context.Background(), 4))
+	workload, err := actuator.Workload(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(10), workload.MaxReplicas)
+}
+
+func TestScaledObjectActuatorRestore(t *testing.T) {
+	c := newFakeClient(t, newScaledObject("llm", "llm-server", 1, 10))
+	actuator := NewScaledObjectActuator(c, Reference{Namespace: "default", Name: "llm"})
+
+	require.NoError(t, actuator.LimitReplicas(context.Background(), 4))
+	require.NoError(t, actuator.Restore(context.Background()))
+
+	scaledObject := getScaledObject(t, c, "llm")
+	maxReplicas, _, _ := unstructured.NestedInt64(scaledObject.Object, "spec", "maxReplicaCount")
+	assert.Equal(t, int64(10), maxReplicas)
+	assert.NotContains(t, scaledObject.GetAnnotations(), OriginalReplicasAnnotation)
+}
+
+func TestScaledObjectActuatorRestoreUnsetMaxReplicaCount(t *testing.T) {
+	scaledObject := newScaledObject("llm", "llm-server", 1, 10)
+	unstructured.RemoveNestedField(scaledObject.Object, "spec", "maxReplicaCount")
+	c := newFakeClient(t, scaledObject)
+	actuator := NewScaledObjectActuator(c, Reference{Namespace: "default", Name: "llm"})
+
+	require.NoError(t, actuator.LimitReplicas(context.Background(), 4))
+	require.NoError(t, actuator.Restore(context.Background()))
+
+	_, found, _ := unstructured.NestedInt64(getScaledObject(t, c, "llm").Object, "spec", "maxReplicaCount")
+	assert.False(t, found)
+}
+
+func TestScaledObjectActuatorMissingScaledObject(t *testing.T) {
+	actuator := NewScaledObjectActuator(newFakeClient(t), Reference{Namespace: "default", Name: "missing"})
+
+	_, err := actuator.Workload(context.Background())
+	assert.Error(t, err)
+	assert.Error(t, actuator.LimitReplicas(context.Background(), 1))
+	assert.NoError(t, actuator.Restore(context.Background()))
+}
+
+func getScaledObject(t *testing.T, c client.Client, name string) *unstructured.Unstructured {
+	scaledObject := &unstructured.Unstructured{}
+	scaledObject.SetGroupVersionKind(scaledObjectGVK)
+	require.NoError(t, c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: name}, scaledObject))
+	return scaledObject
+}
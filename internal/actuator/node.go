@@ -0,0 +1,174 @@
+// node.go
+package actuator
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// PowerPressureTaintKey is the key of the NoSchedule taint put on nodes
+	// exceeding their power cap.
+	PowerPressureTaintKey = "climatik-project.io/power-pressure"
+
+	// CordonedByAnnotation and TaintedByAnnotation record on a node the
+	// owners, comma separated, that need it cordoned or tainted, so that it
+	// is only reverted once no owner needs it anymore and nodes cordoned by
+	// administrators stay cordoned.
+	CordonedByAnnotation = "climatik-project.io/cordoned-by"
+	TaintedByAnnotation  = "climatik-project.io/tainted-by"
+)
+
+// NodeActuator keeps new pods off the nodes whose power exceeds their cap on
+// behalf of an owner, e.g. the namespace/name of a PowerCappingConfig.
+type NodeActuator struct {
+	client client.Client
+	owner  string
+}
+
+// NewNodeActuator returns a node actuator acting on behalf of owner.
+func NewNodeActuator(c client.Client, owner string) *NodeActuator {
+	return &NodeActuator{client: c, owner: owner}
+}
+
+// Cordon marks the node unschedulable and records the owner among those
+// needing it. A node made unschedulable by someone else is left alone.
+func (a *NodeActuator) Cordon(ctx context.Context, node *corev1.Node) error {
+	return a.patch(ctx, node, func(node *corev1.Node) bool {
+		owners := owners(node, CordonedByAnnotation)
+		if node.Spec.Unschedulable && (len(owners) == 0 || slices.Contains(owners, a.owner)) {
+			return false
+		}
+		node.Spec.Unschedulable = true
+		setOwners(node, CordonedByAnnotation, append(owners, a.owner))
+		return true
+	})
+}
+
+// Taint adds the NoSchedule power pressure taint to the node and records the
+// owner among those needing it. A taint put by someone else is left alone.
+func (a *NodeActuator) Taint(ctx context.Context, node *corev1.Node) error {
+	return a.patch(ctx, node, func(node *corev1.Node) bool {
+		owners := owners(node, TaintedByAnnotation)
+		tainted := hasPowerPressureTaint(node)
+		if tainted && (len(owners) == 0 || slices.Contains(owners, a.owner)) {
+			return false
+		}
+		if !tainted {
+			node.Spec.Taints = append(node.Spec.Taints, corev1.Taint{
+				Key:    PowerPressureTaintKey,
+				Effect: corev1.TaintEffectNoSchedule,
+			})
+		}
+		setOwners(node, TaintedByAnnotation, append(owners, a.owner))
+		return true
+	})
+}
+
+// Release drops the owner from those needing the node cordoned or tainted,
+// and uncordons or untaints it once no owner is left.
+func (a *NodeActuator) Release(ctx context.Context, node *corev1.Node) error {
+	return a.patch(ctx, node, func(node *corev1.Node) bool {
+		cordonOwners := owners(node, CordonedByAnnotation)
+		taintOwners := owners(node, TaintedByAnnotation)
+		cordoned := slices.Contains(cordonOwners, a.owner)
+		tainted := slices.Contains(taintOwners, a.owner)
+		if !cordoned && !tainted {
+			return false
+		}
+		if cordoned {
+			cordonOwners = slices.DeleteFunc(cordonOwners, func(owner string) bool { return owner == a.owner })
+			setOwners(node, CordonedByAnnotation, cordonOwners)
+			if len(cordonOwners) == 0 {
+				node.Spec.Unschedulable = false
+			}
+		}
+		if tainted {
+			taintOwners = slices.DeleteFunc(taintOwners, func(owner string) bool { return owner == a.owner })
+			setOwners(node, TaintedByAnnotation, taintOwners)
+			if len(taintOwners) == 0 {
+				taints := node.Spec.Taints[:0:0]
+				for _, taint := range node.Spec.Taints {
+					if taint.Key != PowerPressureTaintKey {
+						taints = append(taints, taint)
+					}
+				}
+				node.Spec.Taints = taints
+			}
+		}
+		return true
+	})
+}
+
+// patch applies the changes made by mutate to the node. The patch carries
+// the resource version of the node, so that the owners recorded by others
+// in the meantime are not lost: on conflict the node is read again and
+// mutate is run on the fresh copy. mutate returns false when the node needs
+// no change.
+func (a *NodeActuator) patch(ctx context.Context, node *corev1.Node, mutate func(*corev1.Node) bool) error {
+	stale := false
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if stale {
+			if err := a.client.Get(ctx, client.ObjectKeyFromObject(node), node); err != nil {
+				return err
+			}
+		}
+		stale = true
+		patch := client.MergeFromWithOptions(node.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		if !mutate(node) {
+			return nil
+		}
+		return a.client.Patch(ctx, node, patch)
+	})
+}
+
+// Evict evicts the pod through the eviction API, so that the
+// PodDisruptionBudgets covering it are honoured.
+func Evict(ctx context.Context, c client.Client, pod *corev1.Pod) error {
+	eviction := &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+	}
+	return c.SubResource("eviction").Create(ctx, pod, eviction)
+}
+
+func hasPowerPressureTaint(node *corev1.Node) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == PowerPressureTaintKey {
+			return true
+		}
+	}
+	return false
+}
+
+// owners returns the owners recorded in the annotation of the node.
+func owners(node *corev1.Node, key string) []string {
+	value := node.GetAnnotations()[key]
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// setOwners records the sorted owners in the annotation of the node, and
+// removes the annotation when there are none.
+func setOwners(node *corev1.Node, key string, owners []string) {
+	annotations := node.GetAnnotations()
+	if len(owners) == 0 {
+		delete(annotations, key)
+		node.SetAnnotations(annotations)
+		return
+	}
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	slices.Sort(owners)
+	annotations[key] = strings.Join(slices.Compact(owners), ",")
+	node.SetAnnotations(annotations)
+}
@@ -0,0 +1,133 @@
+package actuator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestNodeActuatorCordonAndRelease(t *testing.T) {
+	c := newFakeClient(t, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}})
+	actuator := NewNodeActuator(c, "default/config")
+	ctx := context.Background()
+
+	node := &corev1.Node{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "node"}, node))
+	require.NoError(t, actuator.Cordon(ctx, node))
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "node"}, node))
+	assert.True(t, node.Spec.Unschedulable)
+	assert.Equal(t, "default/config", node.Annotations[CordonedByAnnotation])
+
+	// Another owner does not uncordon the node.
+	require.NoError(t, NewNodeActuator(c, "default/other").Release(ctx, node))
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "node"}, node))
+	assert.True(t, node.Spec.Unschedulable)
+
+	require.NoError(t, actuator.Release(ctx, node))
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "node"}, node))
+	assert.False(t, node.Spec.Unschedulable)
+	assert.NotContains(t, node.Annotations, CordonedByAnnotation)
+}
+
+func TestNodeActuatorSharesNodesBetweenOwners(t *testing.T) {
+	c := newFakeClient(t, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}})
+	first := NewNodeActuator(c, "default/config")
+	second := NewNodeActuator(c, "default/other")
+	ctx := context.Background()
+
+	node := &corev1.Node{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "node"}, node))
+	require.NoError(t, first.Cordon(ctx, node))
+	require.NoError(t, first.Taint(ctx, node))
+	require.NoError(t, second.Cordon(ctx, node))
+	require.NoError(t, second.Taint(ctx, node))
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "node"}, node))
+	assert.Equal(t, "default/config,default/other", node.Annotations[CordonedByAnnotation])
+	assert.Equal(t, "default/config,default/other", node.Annotations[TaintedByAnnotation])
+
+	// The node stays cordoned and tainted while the other owner needs it.
+	require.NoError(t, first.Release(ctx, node))
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "node"}, node))
+	assert.True(t, node.Spec.Unschedulable)
+	assert.Len(t, node.Spec.Taints, 1)
+	assert.Equal(t, "default/other", node.Annotations[CordonedByAnnotation])
+	assert.Equal(t, "default/other", node.Annotations[TaintedByAnnotation])
+
+	require.NoError(t, second.Release(ctx, node))
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "node"}, node))
+	assert.False(t, node.Spec.Unschedulable)
+	assert.Empty(t, node.Spec.Taints)
+	assert.NotContains(t, node.Annotations, CordonedByAnnotation)
+	assert.NotContains(t, node.Annotations, TaintedByAnnotation)
+}
+
+func TestNodeActuatorKeepsOwnersRecordedConcurrently(t *testing.T) {
+	c := newFakeClient(t, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}})
+	ctx := context.Background()
+
+	// Both owners act on a copy read before the other one patched the node.
+	first, second := &corev1.Node{}, &corev1.Node{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "node"}, first))
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "node"}, second))
+	require.NoError(t, NewNodeActuator(c, "default/config").Taint(ctx, first))
+	require.NoError(t, NewNodeActuator(c, "default/other").Taint(ctx, second))
+
+	node := &corev1.Node{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "node"}, node))
+	assert.Len(t, node.Spec.Taints, 1)
+	assert.Equal(t, "default/config,default/other", node.Annotations[TaintedByAnnotation])
+
+	require.NoError(t, NewNodeActuator(c, "default/config").Release(ctx, first))
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "node"}, node))
+	assert.Len(t, node.Spec.Taints, 1)
+	assert.Equal(t, "default/other", node.Annotations[TaintedByAnnotation])
+}
+
+func TestNodeActuatorKeepsNodesCordonedByOthers(t *testing.T) {
+	c := newFakeClient(t, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}, Spec: corev1.NodeSpec{Unschedulable: true}})
+	actuator := NewNodeActuator(c, "default/config")
+	ctx := context.Background()
+
+	node := &corev1.Node{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "node"}, node))
+	require.NoError(t, actuator.Cordon(ctx, node))
+	require.NoError(t, actuator.Release(ctx, node))
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "node"}, node))
+	assert.True(t, node.Spec.Unschedulable)
+	assert.NotContains(t, node.Annotations, CordonedByAnnotation)
+}
+
+func TestNodeActuatorTaintAndRelease(t *testing.T) {
+	other := corev1.Taint{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}
+	c := newFakeClient(t, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}, Spec: corev1.NodeSpec{Taints: []corev1.Taint{other}}})
+	actuator := NewNodeActuator(c, "default/config")
+	ctx := context.Background()
+
+	node := &corev1.Node{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "node"}, node))
+	require.NoError(t, actuator.Taint(ctx, node))
+	require.NoError(t, actuator.Taint(ctx, node))
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "node"}, node))
+	assert.Equal(t, []corev1.Taint{other, {Key: PowerPressureTaintKey, Effect: corev1.TaintEffectNoSchedule}}, node.Spec.Taints)
+
+	require.NoError(t, actuator.Release(ctx, node))
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "node"}, node))
+	assert.Equal(t, []corev1.Taint{other}, node.Spec.Taints)
+	assert.NotContains(t, node.Annotations, TaintedByAnnotation)
+}
+
+func TestEvict(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "llm-server-a", Namespace: "default"}}
+	c := newFakeClient(t, pod)
+	ctx := context.Background()
+
+	require.NoError(t, Evict(ctx, c, pod))
+	err := c.Get(ctx, client.ObjectKeyFromObject(pod), &corev1.Pod{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
@@ -0,0 +1,26 @@
+// replicas.go
+package actuator
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	DeploymentKind  = "Deployment"
+	StatefulSetKind = "StatefulSet"
+
+	appsAPIVersion = "apps/v1"
+)
+
+// NewReplicasActuator returns an actuator that bounds spec.replicas of a
+// Deployment or StatefulSet. An empty API version defaults to apps/v1.
+func NewReplicasActuator(c client.Client, ref Reference) Actuator {
+	if ref.APIVersion == "" {
+		ref.APIVersion = appsAPIVersion
+	}
+	return &fieldActuator{
+		client: c,
+		ref:    ref,
+		field:  []string{"spec", "replicas"},
+	}
+}
@@ -0,0 +1,63 @@
+package actuator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestReplicasActuatorWorkload(t *testing.T) {
+	replicas := int32(6)
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "llm-server", Namespace: "default"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "llm"}},
+		},
+	}
+	c := newFakeClient(t, statefulSet)
+	actuator := NewReplicasActuator(c, Reference{Kind: StatefulSetKind, Namespace: "default", Name: "llm-server"})
+
+	workload, err := actuator.Workload(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Reference{APIVersion: "apps/v1", Kind: "StatefulSet", Namespace: "default", Name: "llm-server"}, workload.Reference)
+	assert.True(t, workload.Selector.Matches(labels.Set{"app": "llm"}))
+	assert.Equal(t, int32(0), workload.MinReplicas)
+	assert.Equal(t, int32(6), workload.MaxReplicas)
+}
+
+func TestReplicasActuatorLimitAndRestore(t *testing.T) {
+	deployment := newDeployment("llm-server", map[string]string{"app": "llm"})
+	replicas := int32(6)
+	deployment.Spec.Replicas = &replicas
+	c := newFakeClient(t, deployment)
+	actuator := NewReplicasActuator(c, Reference{Kind: DeploymentKind, Namespace: "default", Name: "llm-server"})
+
+	require.NoError(t, actuator.LimitReplicas(context.Background(), 2))
+	deployment = &appsv1.Deployment{}
+	require.NoError(t, c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "llm-server"}, deployment))
+	assert.Equal(t, int32(2), *deployment.Spec.Replicas)
+	assert.Equal(t, "6", deployment.Annotations[OriginalReplicasAnnotation])
+
+	require.NoError(t, actuator.Restore(context.Background()))
+	deployment = &appsv1.Deployment{}
+	require.NoError(t, c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "llm-server"}, deployment))
+	assert.Equal(t, int32(6), *deployment.Spec.Replicas)
+	assert.NotContains(t, deployment.Annotations, OriginalReplicasAnnotation)
+}
+
+func TestNewActuator(t *testing.T) {
+	c := newFakeClient(t)
+	for _, kind := range []string{"", ScaledObjectKind, DeploymentKind, StatefulSetKind, HorizontalPodAutoscalerKind} {
+		_, err := NewActuator(c, Reference{Kind: kind, Namespace: "default", Name: "llm"})
+		assert.NoError(t, err, kind)
+	}
+	_, err := NewActuator(c, Reference{Kind: "DaemonSet", Namespace: "default", Name: "llm"})
+	assert.Error(t, err)
+}
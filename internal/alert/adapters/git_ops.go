@@ -1,9 +1,12 @@
 package adapters
 
 import (
+	"context"
 	"fmt"
+	"maps"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
@@ -11,7 +14,10 @@ import (
 
 type GitOpsAlertManager struct {
 	repoDir string
-	alerts  map[string]string // In-memory storage for alerts
+
+	// mu guards alerts, which are created and resolved concurrently.
+	mu     sync.Mutex
+	alerts map[string]string // In-memory storage for alerts
 }
 
 func NewGitOpsAlertManager(repoURL, repoDir string) (*GitOpsAlertManager, error) {
@@ -24,11 +30,24 @@ func NewGitOpsAlertManager(repoURL, repoDir string) (*GitOpsAlertManager, error)
 
 func (g *GitOpsAlertManager) CreateAlert(podName string, powerCapValue int, devices map[string]string, config *powercappingv1alpha1.PowerCappingConfig) error {
 	alertMessage := g.formatAlertMessage(podName, powerCapValue, devices)
-	alertFile := filepath.Join(g.repoDir, "alerts", podName+"-alert.yaml")
-	g.alerts[alertFile] = alertMessage
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.alerts[g.alertFile(podName)] = alertMessage
+	return nil
+}
+
+// ResolveAlert removes the alert file of the pod.
+func (g *GitOpsAlertManager) ResolveAlert(ctx context.Context, podName string, config *powercappingv1alpha1.PowerCappingConfig) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.alerts, g.alertFile(podName))
 	return nil
 }
 
+func (g *GitOpsAlertManager) alertFile(podName string) string {
+	return filepath.Join(g.repoDir, "alerts", podName+"-alert.yaml")
+}
+
 func (g *GitOpsAlertManager) formatAlertMessage(podName string, powerCapValue int, devices map[string]string) string {
 	deviceStr := []string{}
 	for device, value := range devices {
@@ -48,7 +67,9 @@ func (g *GitOpsAlertManager) formatAlertMessage(podName string, powerCapValue in
 	`, podName, time.Now().Format(time.RFC3339), podName, powerCapValue, strings.Join(deviceStr, "\n    "))
 }
 
-// GetAlerts returns the current alerts (for testing purposes)
+// GetAlerts returns a copy of the current alerts (for testing purposes)
 func (g *GitOpsAlertManager) GetAlerts() map[string]string {
-	return g.alerts
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return maps.Clone(g.alerts)
 }
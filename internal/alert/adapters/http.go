@@ -0,0 +1,11 @@
+// http.go
+package adapters
+
+import (
+	"net/http"
+	"time"
+)
+
+// httpClient sends the alerts over HTTP. Its timeout bounds how long an
+// unavailable alert manager holds back the alerts of a pod.
+var httpClient = &http.Client{Timeout: 10 * time.Second}
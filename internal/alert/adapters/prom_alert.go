@@ -38,7 +38,7 @@ func NewPrometheusAlertManager(prometheusAddress string) (*PrometheusAlertManage
 
 func (p *PrometheusAlertManager) CreateAlert(podName string, powerCapValue int, devices map[string]string, config *powercappingv1alpha1.PowerCappingConfig) error {
 	alert := p.FormatPrometheusAlert(podName, powerCapValue, devices)
-	if err := p.SendAlertToPrometheus(alert); err != nil {
+	if err := p.SendAlertToPrometheus(context.Background(), alert); err != nil {
 		return fmt.Errorf("failed to send alert to Prometheus: %w", err)
 	}
 	return nil
@@ -53,11 +53,7 @@ func (p *PrometheusAlertManager) FormatPrometheusAlert(podName string, powerCapV
 		deviceStr = deviceStr[:len(deviceStr)-1] // Remove trailing comma
 	}
 	return PrometheusAlert{
-		Labels: map[string]string{
-			"alertname": "PowerCappingAlert",
-			"severity":  "critical",
-			"pod":       podName,
-		},
+		Labels: alertLabels(podName),
 		Annotations: map[string]string{
 			"summary":     fmt.Sprintf("Power capping alert for pod %s", podName),
 			"description": fmt.Sprintf("The pod is exceeding the power cap of %d watts. Devices: %s", powerCapValue, deviceStr),
@@ -66,20 +62,43 @@ func (p *PrometheusAlertManager) FormatPrometheusAlert(podName string, powerCapV
 	}
 }
 
-func (p *PrometheusAlertManager) SendAlertToPrometheus(alert PrometheusAlert) error {
+// ResolveAlert sends the alert of the pod again ending now, which resolves it
+// in Alertmanager.
+func (p *PrometheusAlertManager) ResolveAlert(ctx context.Context, podName string, config *powercappingv1alpha1.PowerCappingConfig) error {
+	now := time.Now()
+	alert := PrometheusAlert{
+		Labels:   alertLabels(podName),
+		StartsAt: now,
+		EndsAt:   &now,
+	}
+	if err := p.SendAlertToPrometheus(ctx, alert); err != nil {
+		return fmt.Errorf("failed to resolve alert in Prometheus: %w", err)
+	}
+	return nil
+}
+
+// alertLabels identify the alert of a pod in Alertmanager.
+func alertLabels(podName string) map[string]string {
+	return map[string]string{
+		"alertname": "PowerCappingAlert",
+		"severity":  "critical",
+		"pod":       podName,
+	}
+}
+
+func (p *PrometheusAlertManager) SendAlertToPrometheus(ctx context.Context, alert PrometheusAlert) error {
 	alertBody, err := json.Marshal([]PrometheusAlert{alert})
 	if err != nil {
 		return fmt.Errorf("failed to marshal alert: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", p.AlertmanagerURL, bytes.NewBuffer(alertBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", p.AlertmanagerURL, bytes.NewBuffer(alertBody))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
@@ -96,4 +115,5 @@ type PrometheusAlert struct {
 	Labels      map[string]string `json:"labels"`
 	Annotations map[string]string `json:"annotations"`
 	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      *time.Time        `json:"endsAt,omitempty"`
 }
@@ -2,6 +2,7 @@ package adapters
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -73,21 +74,39 @@ func (s *SlackAlertManager) CreateAlert(podName string, powerCapValue int, devic
 	// Update the alert struct with the new message
 	alert.Message = message
 
-	return s.sendWebhookAlert(alert)
+	return s.sendWebhookAlert(context.Background(), alert)
 }
 
-func (s *SlackAlertManager) sendWebhookAlert(alert SlackAlert) error {
+// ResolveAlert posts that the pod is no longer capped by the config.
+func (s *SlackAlertManager) ResolveAlert(ctx context.Context, podName string, config *powercappingv1alpha1.PowerCappingConfig) error {
+	return s.sendWebhookAlert(ctx, SlackAlert{
+		PodName:   podName,
+		Level:     AlertLevelInfo,
+		Timestamp: time.Now(),
+		Config:    config,
+		Message: fmt.Sprintf("*Power Capping Alert resolved for pod %s*\nThe PowerCappingConfig %s/%s no longer caps the pod.\n",
+			podName, config.Namespace, config.Name),
+	})
+}
+
+func (s *SlackAlertManager) sendWebhookAlert(ctx context.Context, alert SlackAlert) error {
+	fields := []map[string]interface{}{
+		{"title": "Pod", "value": alert.PodName, "short": true},
+	}
+	// Resolved alerts carry no power cap.
+	if alert.PowerCapValue > 0 {
+		fields = append(fields,
+			map[string]interface{}{"title": "Power Cap", "value": fmt.Sprintf("%d W", alert.PowerCapValue), "short": true},
+			map[string]interface{}{"title": "Current Power", "value": fmt.Sprintf("%.2f W", alert.CurrentPower), "short": true},
+		)
+	}
+	fields = append(fields, map[string]interface{}{"title": "Timestamp", "value": alert.Timestamp.Format(time.RFC3339), "short": true})
 	payload := map[string]interface{}{
 		"text": alert.Message,
 		"attachments": []map[string]interface{}{
 			{
-				"color": getColorForAlertLevel(alert.Level),
-				"fields": []map[string]interface{}{
-					{"title": "Pod", "value": alert.PodName, "short": true},
-					{"title": "Power Cap", "value": fmt.Sprintf("%d W", alert.PowerCapValue), "short": true},
-					{"title": "Current Power", "value": fmt.Sprintf("%.2f W", alert.CurrentPower), "short": true},
-					{"title": "Timestamp", "value": alert.Timestamp.Format(time.RFC3339), "short": true},
-				},
+				"color":  getColorForAlertLevel(alert.Level),
+				"fields": fields,
 			},
 		},
 	}
@@ -97,7 +116,12 @@ func (s *SlackAlertManager) sendWebhookAlert(alert SlackAlert) error {
 		return fmt.Errorf("failed to marshal JSON payload: %w", err)
 	}
 
-	resp, err := http.Post(s.webhookURL, "application/json", bytes.NewBuffer(jsonPayload))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return fmt.Errorf("failed to create Slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send Slack alert: %w", err)
 	}
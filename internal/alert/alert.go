@@ -1,8 +1,15 @@
 package alert
 
-import "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+import (
+	"context"
 
-// AlertManager is the interface for creating power capping alerts
+	"github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+)
+
+// AlertManager is the interface for creating and resolving power capping alerts
 type AlertManager interface {
 	CreateAlert(podName string, powerCapValue int, devices map[string]string, config *v1alpha1.PowerCappingConfig) error
+	// ResolveAlert clears the alert of the pod created for the config. It is
+	// not an error to resolve an alert that was never created.
+	ResolveAlert(ctx context.Context, podName string, config *v1alpha1.PowerCappingConfig) error
 }
@@ -29,15 +29,25 @@ func NewAlertManager(managerType AlertManagerType, config map[string]string) (Al
 }
 
 func CreateAlertService(config map[string]map[string]string) (*PubSub, error) {
+	managers, err := NewAlertManagers(config)
+	if err != nil {
+		return nil, err
+	}
 	pubsub := NewPubSub()
+	pubsub.Replace("alerts", managers)
+	return pubsub, nil
+}
 
+// NewAlertManagers returns the alert managers of config, which is keyed by
+// alert manager type.
+func NewAlertManagers(config map[string]map[string]string) ([]AlertManager, error) {
+	managers := make([]AlertManager, 0, len(config))
 	for managerType, managerConfig := range config {
 		manager, err := NewAlertManager(AlertManagerType(managerType), managerConfig)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create alert manager %s: %w", managerType, err)
 		}
-		pubsub.Subscribe("alerts", manager)
+		managers = append(managers, manager)
 	}
-
-	return pubsub, nil
+	return managers, nil
 }
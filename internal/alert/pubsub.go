@@ -2,6 +2,9 @@
 package alert
 
 import (
+	"context"
+	"errors"
+	"slices"
 	"sync"
 
 	"github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
@@ -10,11 +13,18 @@ import (
 type PubSub struct {
 	subscribers map[string][]AlertManager
 	mu          sync.RWMutex
+
+	// pending holds, per pod, a channel closed once the last alert published
+	// or resolved for the pod is done, so that every subscriber sees the
+	// alerts of a pod created and resolved in the order they were made.
+	pending   map[string]chan struct{}
+	pendingMu sync.Mutex
 }
 
 func NewPubSub() *PubSub {
 	return &PubSub{
 		subscribers: make(map[string][]AlertManager),
+		pending:     make(map[string]chan struct{}),
 	}
 }
 
@@ -24,10 +34,86 @@ func (ps *PubSub) Subscribe(topic string, subscriber AlertManager) {
 	ps.subscribers[topic] = append(ps.subscribers[topic], subscriber)
 }
 
+// Replace replaces the subscribers of the topic.
+func (ps *PubSub) Replace(topic string, subscribers []AlertManager) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.subscribers[topic] = subscribers
+}
+
+// Publish creates the alert of the pod with every subscriber of the topic in
+// the background, once the alerts previously published or resolved for the
+// pod are done.
 func (ps *PubSub) Publish(topic string, podName string, powerCapValue int, devices map[string]string, config *v1alpha1.PowerCappingConfig) {
+	subscribers := ps.topicSubscribers(topic)
+	previous, done := ps.enqueue(config.Namespace + "/" + podName)
+	var wg sync.WaitGroup
+	for _, subscriber := range subscribers {
+		wg.Add(1)
+		go func(subscriber AlertManager) {
+			defer wg.Done()
+			<-previous
+			subscriber.CreateAlert(podName, powerCapValue, devices, config)
+		}(subscriber)
+	}
+	go func() {
+		wg.Wait()
+		done()
+	}()
+}
+
+// Resolve resolves the alert of the pod with every subscriber of the topic and
+// returns their errors joined. It waits for the alerts of the pod published
+// before, so that a resolution never reaches a subscriber ahead of its alert,
+// and returns the error of ctx if it is done first.
+func (ps *PubSub) Resolve(ctx context.Context, topic string, podName string, config *v1alpha1.PowerCappingConfig) error {
+	subscribers := ps.topicSubscribers(topic)
+	previous, done := ps.enqueue(config.Namespace + "/" + podName)
+	select {
+	case <-previous:
+	case <-ctx.Done():
+		// The alerts of the pod queued next still wait for the previous one.
+		go func() {
+			<-previous
+			done()
+		}()
+		return ctx.Err()
+	}
+	defer done()
+	var errs []error
+	for _, subscriber := range subscribers {
+		errs = append(errs, subscriber.ResolveAlert(ctx, podName, config))
+	}
+	return errors.Join(errs...)
+}
+
+// topicSubscribers returns a copy of the subscribers of the topic, so that
+// they are notified without holding the lock.
+func (ps *PubSub) topicSubscribers(topic string) []AlertManager {
 	ps.mu.RLock()
 	defer ps.mu.RUnlock()
-	for _, subscriber := range ps.subscribers[topic] {
-		go subscriber.CreateAlert(podName, powerCapValue, devices, config)
+	return slices.Clone(ps.subscribers[topic])
+}
+
+// enqueue queues an alert of the pod behind the one pending. It returns a
+// channel closed once the pending alert is done, and the function to call
+// once the queued alert is done.
+func (ps *PubSub) enqueue(pod string) (<-chan struct{}, func()) {
+	ps.pendingMu.Lock()
+	defer ps.pendingMu.Unlock()
+	previous, ok := ps.pending[pod]
+	if !ok {
+		previous = make(chan struct{})
+		close(previous)
+	}
+	current := make(chan struct{})
+	ps.pending[pod] = current
+	return previous, func() {
+		close(current)
+		ps.pendingMu.Lock()
+		defer ps.pendingMu.Unlock()
+		if ps.pending[pod] == current {
+			delete(ps.pending, pod)
+		}
 	}
 }
@@ -1,7 +1,11 @@
 // service.go
 package alert
 
-import "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+import (
+	"context"
+
+	"github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+)
 
 type AlertService struct {
 	Pubsub *PubSub
@@ -15,7 +19,25 @@ func NewAlertService(config map[string]map[string]string) (*AlertService, error)
 	return &AlertService{Pubsub: pubsub}, nil
 }
 
+// Reload replaces the alert managers with those of config, e.g. when the
+// operator configuration changes. The alert managers in use are kept when one
+// of the new ones cannot be created.
+func (s *AlertService) Reload(config map[string]map[string]string) error {
+	managers, err := NewAlertManagers(config)
+	if err != nil {
+		return err
+	}
+	s.Pubsub.Replace("alerts", managers)
+	return nil
+}
+
 func (s *AlertService) SendAlert(podName string, powerCapValue int, devices map[string]string, config *v1alpha1.PowerCappingConfig) error {
 	s.Pubsub.Publish("alerts", podName, powerCapValue, devices, config)
 	return nil
 }
+
+// ResolveAlert resolves the alert of the pod in every alert manager, and
+// returns once all of them are done or ctx is.
+func (s *AlertService) ResolveAlert(ctx context.Context, podName string, config *v1alpha1.PowerCappingConfig) error {
+	return s.Pubsub.Resolve(ctx, "alerts", podName, config)
+}
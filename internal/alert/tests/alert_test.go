@@ -3,8 +3,10 @@ package alert
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
@@ -159,6 +161,31 @@ func TestCreateAlertError(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to send alert to Prometheus Alertmanager")
 }
 
+func TestResolveAlert(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alerts []adapters.PrometheusAlert
+		err := json.NewDecoder(r.Body).Decode(&alerts)
+		require.NoError(t, err)
+		assert.Len(t, alerts, 1)
+
+		alert := alerts[0]
+		assert.Equal(t, "PowerCappingAlert", alert.Labels["alertname"])
+		assert.Equal(t, "test-pod", alert.Labels["pod"])
+		require.NotNil(t, alert.EndsAt)
+		assert.False(t, alert.EndsAt.After(time.Now()))
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	manager := &adapters.PrometheusAlertManager{
+		AlertmanagerURL: server.URL + "/api/v1/alerts",
+	}
+
+	err := manager.ResolveAlert(context.Background(), "test-pod", NewMockPowerCappingConfig())
+	assert.NoError(t, err)
+}
+
 func TestSendAlertToPrometheus(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -175,7 +202,7 @@ func TestSendAlertToPrometheus(t *testing.T) {
 		StartsAt:    time.Now(),
 	}
 
-	err := manager.SendAlertToPrometheus(alert)
+	err := manager.SendAlertToPrometheus(context.Background(), alert)
 	assert.NoError(t, err)
 }
 
@@ -199,6 +226,34 @@ func TestGitOpsAlertManager(t *testing.T) {
 	assert.Contains(t, alertContent, "test-pod")
 	assert.Contains(t, alertContent, "powerCapValue: 100")
 	assert.Contains(t, alertContent, "cpu: high")
+
+	err = manager.ResolveAlert(context.Background(), "test-pod", mockConfig)
+	assert.NoError(t, err)
+	assert.Empty(t, manager.GetAlerts())
+}
+
+// TestGitOpsAlertManagerConcurrently tests that the alerts of the
+// GitOpsAlertManager can be created and resolved concurrently
+func TestGitOpsAlertManagerConcurrently(t *testing.T) {
+	manager, err := adapters.NewGitOpsAlertManager("https://github.com/test/repo.git", "/tmp/test-repo")
+	require.NoError(t, err)
+	mockConfig := NewMockPowerCappingConfig()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		pod := fmt.Sprintf("test-pod-%d", i)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, manager.CreateAlert(pod, 100, nil, mockConfig))
+		}()
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, manager.ResolveAlert(context.Background(), "other-"+pod, mockConfig))
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, manager.GetAlerts(), 10)
 }
 
 // TestAlertService tests the AlertService
@@ -232,6 +287,95 @@ func TestAlertService(t *testing.T) {
 	mockGitOpsManager.AssertExpectations(t)
 }
 
+// TestAlertServiceResolveAlert tests that alerts are resolved in every alert manager
+func TestAlertServiceResolveAlert(t *testing.T) {
+	mockSlackManager := new(MockAlertManager)
+	mockPrometheusManager := new(MockAlertManager)
+
+	pubsub := alert.NewPubSub()
+	pubsub.Subscribe("alerts", mockSlackManager)
+	pubsub.Subscribe("alerts", mockPrometheusManager)
+
+	service := &alert.AlertService{Pubsub: pubsub}
+	mockConfig := NewMockPowerCappingConfig()
+
+	mockSlackManager.On("ResolveAlert", "test-pod", mockConfig).Return(assert.AnError)
+	mockPrometheusManager.On("ResolveAlert", "test-pod", mockConfig).Return(nil)
+
+	err := service.ResolveAlert(context.Background(), "test-pod", mockConfig)
+	assert.ErrorIs(t, err, assert.AnError)
+
+	mockSlackManager.AssertExpectations(t)
+	mockPrometheusManager.AssertExpectations(t)
+}
+
+// TestAlertServiceResolveAlertAfterCreate tests that an alert resolved right
+// after it was sent is resolved only once it is created
+func TestAlertServiceResolveAlertAfterCreate(t *testing.T) {
+	manager := &orderedAlertManager{createDelay: 50 * time.Millisecond}
+	pubsub := alert.NewPubSub()
+	pubsub.Subscribe("alerts", manager)
+	service := &alert.AlertService{Pubsub: pubsub}
+	mockConfig := NewMockPowerCappingConfig()
+
+	require.NoError(t, service.SendAlert("test-pod", 100, nil, mockConfig))
+	require.NoError(t, service.ResolveAlert(context.Background(), "test-pod", mockConfig))
+
+	assert.Equal(t, []string{"create test-pod", "resolve test-pod"}, manager.recorded())
+}
+
+// TestAlertServiceResolveAlertCanceled tests that a resolution gives up when
+// its context is done before the alert is created, and that the resolutions
+// after it still wait for the alert
+func TestAlertServiceResolveAlertCanceled(t *testing.T) {
+	manager := &orderedAlertManager{createDelay: 50 * time.Millisecond}
+	pubsub := alert.NewPubSub()
+	pubsub.Subscribe("alerts", manager)
+	service := &alert.AlertService{Pubsub: pubsub}
+	mockConfig := NewMockPowerCappingConfig()
+
+	require.NoError(t, service.SendAlert("test-pod", 100, nil, mockConfig))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, service.ResolveAlert(ctx, "test-pod", mockConfig), context.DeadlineExceeded)
+
+	resolved := make(chan error, 1)
+	go func() { resolved <- service.ResolveAlert(context.Background(), "test-pod", mockConfig) }()
+	// The subscribers can be replaced while a resolution waits.
+	replaced := make(chan struct{})
+	go func() {
+		pubsub.Replace("other", nil)
+		close(replaced)
+	}()
+	select {
+	case <-replaced:
+	case <-time.After(20 * time.Millisecond):
+		t.Fatal("subscribers locked while resolving")
+	}
+	require.NoError(t, <-resolved)
+
+	assert.Equal(t, []string{"create test-pod", "resolve test-pod"}, manager.recorded())
+}
+
+// TestAlertServiceReload tests that reloading replaces the alert managers only
+// when all of the new ones can be created
+func TestAlertServiceReload(t *testing.T) {
+	mockSlackManager := new(MockAlertManager)
+	pubsub := alert.NewPubSub()
+	pubsub.Subscribe("alerts", mockSlackManager)
+	service := &alert.AlertService{Pubsub: pubsub}
+	mockConfig := NewMockPowerCappingConfig()
+
+	err := service.Reload(map[string]map[string]string{"pagerduty": {}})
+	assert.Error(t, err)
+	mockSlackManager.On("ResolveAlert", "test-pod", mockConfig).Return(nil).Once()
+	assert.NoError(t, service.ResolveAlert(context.Background(), "test-pod", mockConfig))
+
+	assert.NoError(t, service.Reload(map[string]map[string]string{}))
+	assert.NoError(t, service.ResolveAlert(context.Background(), "test-pod", mockConfig))
+	mockSlackManager.AssertExpectations(t)
+}
+
 // MockAlertManager is a mock implementation of the AlertManager interface
 type MockAlertManager struct {
 	mock.Mock
@@ -241,3 +385,39 @@ func (m *MockAlertManager) CreateAlert(podName string, powerCapValue int, device
 	args := m.Called(podName, powerCapValue, devices, config)
 	return args.Error(0)
 }
+
+func (m *MockAlertManager) ResolveAlert(ctx context.Context, podName string, config *v1alpha1.PowerCappingConfig) error {
+	args := m.Called(podName, config)
+	return args.Error(0)
+}
+
+// orderedAlertManager records the order in which alerts are created and
+// resolved, creating them after createDelay
+type orderedAlertManager struct {
+	createDelay time.Duration
+	mu          sync.Mutex
+	calls       []string
+}
+
+func (m *orderedAlertManager) CreateAlert(podName string, powerCapValue int, devices map[string]string, config *v1alpha1.PowerCappingConfig) error {
+	time.Sleep(m.createDelay)
+	m.record("create " + podName)
+	return nil
+}
+
+func (m *orderedAlertManager) ResolveAlert(ctx context.Context, podName string, config *v1alpha1.PowerCappingConfig) error {
+	m.record("resolve " + podName)
+	return nil
+}
+
+func (m *orderedAlertManager) record(call string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, call)
+}
+
+func (m *orderedAlertManager) recorded() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.calls...)
+}
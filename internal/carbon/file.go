@@ -0,0 +1,112 @@
+// file.go
+package carbon
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// point is an entry of a carbon intensity time series file. An empty zone
+// applies to the zones without entries of their own.
+type point struct {
+	Time            time.Time `json:"time"`
+	Zone            string    `json:"zone,omitempty"`
+	CarbonIntensity float64   `json:"carbonIntensity"`
+}
+
+// FileProvider answers from a static time series of carbon intensities, e.g.
+// historical grid data replayed in a test cluster. The current intensity of a
+// zone is the latest entry of the zone that is not in the future, or the
+// latest such entry without a zone when the zone has none.
+type FileProvider struct {
+	// Now returns the current time, time.Now when nil.
+	Now func() time.Time
+
+	points []point
+}
+
+// NewFileProvider loads the time series at path. A .json file holds an array
+// of {"time", "zone", "carbonIntensity"} objects; any other file is read as
+// CSV with the columns time, zone and carbonIntensity, after an optional
+// header. Times are in RFC 3339 and intensities in gCO2eq/kWh.
+func NewFileProvider(path string) (*FileProvider, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var points []point
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.NewDecoder(file).Decode(&points)
+	} else {
+		points, err = readCSV(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read carbon intensities from %s: %w", path, err)
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+	return &FileProvider{points: points}, nil
+}
+
+func readCSV(r io.Reader) ([]point, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	points := make([]point, 0, len(records))
+	for i, record := range records {
+		t, err := time.Parse(time.RFC3339, record[0])
+		if err != nil {
+			if i == 0 {
+				// header
+				continue
+			}
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		value, err := strconv.ParseFloat(record[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		points = append(points, point{Time: t, Zone: record[1], CarbonIntensity: value})
+	}
+	return points, nil
+}
+
+func (p *FileProvider) CarbonIntensity(ctx context.Context, zone string) (*Intensity, error) {
+	now := time.Now()
+	if p.Now != nil {
+		now = p.Now()
+	}
+	var current, fallback *point
+	for i := range p.points {
+		if p.points[i].Time.After(now) {
+			break
+		}
+		switch p.points[i].Zone {
+		case zone:
+			current = &p.points[i]
+		case "":
+			fallback = &p.points[i]
+		}
+	}
+	if current == nil {
+		current = fallback
+	}
+	if current == nil {
+		return nil, fmt.Errorf("no carbon intensity for zone %q at %s", zone, now.Format(time.RFC3339))
+	}
+	return &Intensity{Zone: zone, Value: current.CarbonIntensity, Time: current.Time}, nil
+}
@@ -0,0 +1,81 @@
+package carbon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func at(value string) func() time.Time {
+	return func() time.Time {
+		t, _ := time.Parse(time.RFC3339, value)
+		return t
+	}
+}
+
+func TestFileProviderCSV(t *testing.T) {
+	provider, err := NewFileProvider(writeFile(t, "intensity.csv", `time,zone,carbonIntensity
+2024-05-01T01:00:00Z,DE,250
+2024-05-01T00:00:00Z,DE,300
+2024-05-01T00:00:00Z,FR,50
+2024-05-01T00:00:00Z,,400
+`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		now      string
+		zone     string
+		expected float64
+	}{
+		{name: "latest entry of the zone", now: "2024-05-01T00:30:00Z", zone: "DE", expected: 300},
+		{name: "entries are sorted by time", now: "2024-05-01T02:00:00Z", zone: "DE", expected: 250},
+		{name: "other zone", now: "2024-05-01T02:00:00Z", zone: "FR", expected: 50},
+		{name: "entries without zone apply to all zones", now: "2024-05-01T02:00:00Z", zone: "PL", expected: 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider.Now = at(tt.now)
+			intensity, err := provider.CarbonIntensity(context.Background(), tt.zone)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, intensity.Value)
+			assert.Equal(t, tt.zone, intensity.Zone)
+		})
+	}
+
+	provider.Now = at("2024-04-30T00:00:00Z")
+	_, err = provider.CarbonIntensity(context.Background(), "DE")
+	assert.Error(t, err)
+}
+
+func TestFileProviderJSON(t *testing.T) {
+	provider, err := NewFileProvider(writeFile(t, "intensity.json", `[
+  {"time": "2024-05-01T00:00:00Z", "zone": "DE", "carbonIntensity": 300},
+  {"time": "2024-05-01T01:00:00Z", "zone": "DE", "carbonIntensity": 250}
+]`))
+	require.NoError(t, err)
+	provider.Now = at("2024-05-01T01:00:00Z")
+
+	intensity, err := provider.CarbonIntensity(context.Background(), "DE")
+	require.NoError(t, err)
+	assert.Equal(t, 250.0, intensity.Value)
+	assert.Equal(t, at("2024-05-01T01:00:00Z")(), intensity.Time)
+}
+
+func TestFileProviderInvalid(t *testing.T) {
+	_, err := NewFileProvider(writeFile(t, "intensity.csv", "2024-05-01T00:00:00Z,DE,high\n"))
+	assert.Error(t, err)
+	_, err = NewFileProvider(filepath.Join(t.TempDir(), "missing.csv"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,139 @@
+// http.go
+package carbon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Formats of the carbon intensity APIs understood by HTTPProvider
+const (
+	ElectricityMaps = "electricitymaps"
+	WattTime        = "watttime"
+)
+
+const (
+	// DefaultElectricityMapsURL is the base URL of the Electricity Maps API.
+	DefaultElectricityMapsURL = "https://api.electricitymap.org"
+	// DefaultWattTimeURL is the base URL of the WattTime API.
+	DefaultWattTimeURL = "https://api.watttime.org"
+
+	// gramsPerPound converts lbs/MWh, the unit of WattTime, to gCO2eq/kWh.
+	gramsPerPound = 453.59237 / 1000
+)
+
+// HTTPProvider reads the latest carbon intensity of a zone from the
+// Electricity Maps or WattTime API, or from any server answering with the
+// same shapes.
+type HTTPProvider struct {
+	Format string
+	// URL is the base URL of the API, without the /v3 path.
+	URL   string
+	Token string
+
+	Client *http.Client
+}
+
+// NewHTTPProvider returns a provider of the API in format at baseURL,
+// authenticated with token. An empty baseURL selects the public API of the
+// format.
+func NewHTTPProvider(format, baseURL, token string) (*HTTPProvider, error) {
+	switch format {
+	case ElectricityMaps:
+		if baseURL == "" {
+			baseURL = DefaultElectricityMapsURL
+		}
+	case WattTime:
+		if baseURL == "" {
+			baseURL = DefaultWattTimeURL
+		}
+	default:
+		return nil, fmt.Errorf("unsupported carbon intensity API: %s", format)
+	}
+	return &HTTPProvider{
+		Format: format,
+		URL:    strings.TrimSuffix(baseURL, "/"),
+		Token:  token,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// electricityMapsResponse is the body of GET /v3/carbon-intensity/latest.
+type electricityMapsResponse struct {
+	Zone            string    `json:"zone"`
+	CarbonIntensity float64   `json:"carbonIntensity"`
+	Datetime        time.Time `json:"datetime"`
+}
+
+// wattTimeResponse is the body of GET /v3/forecast.
+type wattTimeResponse struct {
+	Data []struct {
+		PointTime time.Time `json:"point_time"`
+		Value     float64   `json:"value"`
+	} `json:"data"`
+	Meta struct {
+		Region string `json:"region"`
+		Units  string `json:"units"`
+	} `json:"meta"`
+}
+
+func (p *HTTPProvider) CarbonIntensity(ctx context.Context, zone string) (*Intensity, error) {
+	switch p.Format {
+	case ElectricityMaps:
+		var response electricityMapsResponse
+		query := url.Values{"zone": {zone}}
+		if err := p.get(ctx, "/v3/carbon-intensity/latest", query, "auth-token", p.Token, &response); err != nil {
+			return nil, err
+		}
+		return &Intensity{Zone: zone, Value: response.CarbonIntensity, Time: response.Datetime}, nil
+	case WattTime:
+		var response wattTimeResponse
+		query := url.Values{"region": {zone}, "signal_type": {"co2_moer"}, "horizon_hours": {"0"}}
+		if err := p.get(ctx, "/v3/forecast", query, "Authorization", "Bearer "+p.Token, &response); err != nil {
+			return nil, err
+		}
+		if len(response.Data) == 0 {
+			return nil, fmt.Errorf("no carbon intensity for region %q", zone)
+		}
+		value := response.Data[0].Value
+		switch response.Meta.Units {
+		case "lbs_co2_per_mwh":
+			value *= gramsPerPound
+		case "g_co2_per_kwh":
+		default:
+			return nil, fmt.Errorf("unsupported carbon intensity units: %q", response.Meta.Units)
+		}
+		return &Intensity{Zone: zone, Value: value, Time: response.Data[0].PointTime}, nil
+	default:
+		return nil, fmt.Errorf("unsupported carbon intensity API: %s", p.Format)
+	}
+}
+
+// get decodes the JSON answer to a GET of path with query, authenticated by header.
+func (p *HTTPProvider) get(ctx context.Context, path string, query url.Values, header, value string, out interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	if p.Token != "" {
+		request.Header.Set(header, value)
+	}
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("carbon intensity request to %s failed: %s", p.URL+path, response.Status)
+	}
+	return json.NewDecoder(response.Body).Decode(out)
+}
@@ -0,0 +1,84 @@
+package carbon
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPProviderElectricityMaps(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/carbon-intensity/latest", r.URL.Path)
+		assert.Equal(t, "DE", r.URL.Query().Get("zone"))
+		assert.Equal(t, "secret", r.Header.Get("auth-token"))
+		_, _ = w.Write([]byte(`{"zone":"DE","carbonIntensity":302,"datetime":"2024-05-01T00:00:00.000Z","updatedAt":"2024-05-01T00:10:00.000Z","isEstimated":false}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewHTTPProvider(ElectricityMaps, server.URL, "secret")
+	require.NoError(t, err)
+	intensity, err := provider.CarbonIntensity(context.Background(), "DE")
+	require.NoError(t, err)
+	assert.Equal(t, 302.0, intensity.Value)
+	assert.Equal(t, "DE", intensity.Zone)
+	assert.Equal(t, 2024, intensity.Time.Year())
+}
+
+func TestHTTPProviderWattTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		units    string
+		value    string
+		expected float64
+	}{
+		{name: "pounds per megawatt-hour", units: "lbs_co2_per_mwh", value: "1000", expected: 453.59237},
+		{name: "grams per kilowatt-hour", units: "g_co2_per_kwh", value: "120", expected: 120},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v3/forecast", r.URL.Path)
+				assert.Equal(t, "CAISO_NORTH", r.URL.Query().Get("region"))
+				assert.Equal(t, "co2_moer", r.URL.Query().Get("signal_type"))
+				assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+				_, _ = w.Write([]byte(`{"data":[{"point_time":"2024-05-01T00:00:00+00:00","value":` + tt.value + `}],` +
+					`"meta":{"region":"CAISO_NORTH","signal_type":"co2_moer","units":"` + tt.units + `"}}`))
+			}))
+			defer server.Close()
+
+			provider, err := NewHTTPProvider(WattTime, server.URL, "secret")
+			require.NoError(t, err)
+			intensity, err := provider.CarbonIntensity(context.Background(), "CAISO_NORTH")
+			require.NoError(t, err)
+			assert.InDelta(t, tt.expected, intensity.Value, 1e-9)
+		})
+	}
+}
+
+func TestHTTPProviderErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v3/forecast" {
+			_, _ = w.Write([]byte(`{"data":[],"meta":{"units":"lbs_co2_per_mwh"}}`))
+			return
+		}
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	electricityMaps, err := NewHTTPProvider(ElectricityMaps, server.URL, "wrong")
+	require.NoError(t, err)
+	_, err = electricityMaps.CarbonIntensity(context.Background(), "DE")
+	assert.ErrorContains(t, err, "401")
+
+	wattTime, err := NewHTTPProvider(WattTime, server.URL, "")
+	require.NoError(t, err)
+	_, err = wattTime.CarbonIntensity(context.Background(), "CAISO_NORTH")
+	assert.Error(t, err)
+
+	_, err = NewHTTPProvider("unknown", server.URL, "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,63 @@
+// provider.go
+package carbon
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Intensity is the carbon intensity of the electricity of a grid zone.
+type Intensity struct {
+	// Zone is the grid zone, e.g. DE or CAISO_NORTH.
+	Zone string
+	// Value is the carbon intensity in gCO2eq/kWh.
+	Value float64
+	// Time is when the carbon intensity applies.
+	Time time.Time
+}
+
+// CarbonIntensityProvider returns the current carbon intensity of a grid zone.
+type CarbonIntensityProvider interface {
+	CarbonIntensity(ctx context.Context, zone string) (*Intensity, error)
+}
+
+// cachedProvider remembers the intensity of each zone for a while.
+type cachedProvider struct {
+	provider CarbonIntensityProvider
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cachedIntensity
+}
+
+type cachedIntensity struct {
+	intensity *Intensity
+	expires   time.Time
+}
+
+// NewCachedProvider returns a provider answering from provider at most once
+// per ttl for each zone, so that frequent reconciles do not exhaust the quota
+// of a remote API. Failures are not cached.
+func NewCachedProvider(provider CarbonIntensityProvider, ttl time.Duration) CarbonIntensityProvider {
+	return &cachedProvider{provider: provider, ttl: ttl, now: time.Now, entries: make(map[string]cachedIntensity)}
+}
+
+func (c *cachedProvider) CarbonIntensity(ctx context.Context, zone string) (*Intensity, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[zone]
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expires) {
+		return entry.intensity, nil
+	}
+
+	intensity, err := c.provider.CarbonIntensity(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.entries[zone] = cachedIntensity{intensity: intensity, expires: c.now().Add(c.ttl)}
+	c.mu.Unlock()
+	return intensity, nil
+}
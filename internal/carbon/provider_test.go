@@ -0,0 +1,62 @@
+package carbon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingProvider answers value, or err, and counts the calls.
+type countingProvider struct {
+	value float64
+	err   error
+	calls int
+}
+
+func (c *countingProvider) CarbonIntensity(ctx context.Context, zone string) (*Intensity, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &Intensity{Zone: zone, Value: c.value}, nil
+}
+
+func TestCachedProvider(t *testing.T) {
+	source := &countingProvider{value: 300}
+	now := time.Now()
+	cached := NewCachedProvider(source, time.Minute).(*cachedProvider)
+	cached.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		intensity, err := cached.CarbonIntensity(context.Background(), "DE")
+		require.NoError(t, err)
+		assert.Equal(t, 300.0, intensity.Value)
+	}
+	assert.Equal(t, 1, source.calls)
+
+	_, err := cached.CarbonIntensity(context.Background(), "FR")
+	require.NoError(t, err)
+	assert.Equal(t, 2, source.calls)
+
+	now = now.Add(time.Minute)
+	source.value = 200
+	intensity, err := cached.CarbonIntensity(context.Background(), "DE")
+	require.NoError(t, err)
+	assert.Equal(t, 200.0, intensity.Value)
+	assert.Equal(t, 3, source.calls)
+}
+
+func TestCachedProviderDoesNotCacheFailures(t *testing.T) {
+	source := &countingProvider{err: errors.New("unavailable")}
+	cached := NewCachedProvider(source, time.Minute)
+
+	_, err := cached.CarbonIntensity(context.Background(), "DE")
+	assert.Error(t, err)
+	_, err = cached.CarbonIntensity(context.Background(), "DE")
+	assert.Error(t, err)
+	assert.Equal(t, 2, source.calls)
+}
@@ -0,0 +1,224 @@
+// config.go
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/Climatik-Project/Climatik-Project/internal/alert"
+	"github.com/Climatik-Project/Climatik-Project/internal/metrics"
+)
+
+// APIVersion and Kind identify the version of the operator configuration read
+// by this operator.
+const (
+	APIVersion = "climatik-project.io/v1alpha1"
+	Kind       = "OperatorConfig"
+)
+
+// DefaultPrometheusURL is the Prometheus queried when none is configured.
+const DefaultPrometheusURL = "http://prometheus:9090"
+
+// OperatorConfig is the configuration of the operator, read from a YAML file,
+// usually a mounted ConfigMap. Settings left out keep their default.
+type OperatorConfig struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+
+	Prometheus PrometheusConfig `json:"prometheus"`
+	Alerts     AlertsConfig     `json:"alerts"`
+	// EfficiencyLevels are the power cap percentages of the efficiency
+	// levels of configs.
+	EfficiencyLevels EfficiencyLevels `json:"efficiencyLevels"`
+	// MetricNames rename the series and labels read by the Kepler and DCGM
+	// metrics sources.
+	MetricNames metrics.Names `json:"metricNames"`
+}
+
+// PrometheusConfig configures the Prometheus read for power and temperatures.
+type PrometheusConfig struct {
+	URL string `json:"url"`
+}
+
+// AlertsConfig configures the alert backends. Only the backends set are
+// alerted.
+type AlertsConfig struct {
+	Prometheus *PrometheusAlertsConfig `json:"prometheus,omitempty"`
+	GitOps     *GitOpsAlertsConfig     `json:"gitops,omitempty"`
+	Slack      *SlackAlertsConfig      `json:"slack,omitempty"`
+}
+
+// PrometheusAlertsConfig configures the Alertmanager receiving alerts.
+type PrometheusAlertsConfig struct {
+	Address string `json:"address"`
+}
+
+// GitOpsAlertsConfig configures the repository alert files are committed to.
+type GitOpsAlertsConfig struct {
+	RepoURL string `json:"repoURL"`
+	RepoDir string `json:"repoDir"`
+}
+
+// SlackAlertsConfig configures the Slack channel alerts are posted to.
+type SlackAlertsConfig struct {
+	WebhookURL string `json:"webhookURL"`
+	Token      string `json:"token,omitempty"`
+	Channel    string `json:"channel,omitempty"`
+}
+
+// Default returns the configuration of an operator configured with nothing.
+func Default() *OperatorConfig {
+	return &OperatorConfig{
+		APIVersion:       APIVersion,
+		Kind:             Kind,
+		Prometheus:       PrometheusConfig{URL: DefaultPrometheusURL},
+		EfficiencyLevels: DefaultEfficiencyLevels(),
+		MetricNames:      metrics.DefaultNames(),
+	}
+}
+
+// FromEnvironment returns the configuration given by the environment variables
+// read before the configuration file existed: PROM_URL, PROMETHEUS_HOST,
+// GITOPS_REPO_URL, GITOPS_REPO_DIR, SLACK_WEBHOOK_URL, SLACK_TOKEN and
+// SLACK_CHANNEL. All alert backends are configured, as they always were.
+func FromEnvironment() *OperatorConfig {
+	config := Default()
+	if url := os.Getenv("PROM_URL"); url != "" {
+		config.Prometheus.URL = url
+	}
+	config.Alerts = AlertsConfig{
+		Prometheus: &PrometheusAlertsConfig{Address: os.Getenv("PROMETHEUS_HOST")},
+		GitOps:     &GitOpsAlertsConfig{RepoURL: os.Getenv("GITOPS_REPO_URL"), RepoDir: os.Getenv("GITOPS_REPO_DIR")},
+		Slack: &SlackAlertsConfig{
+			WebhookURL: os.Getenv("SLACK_WEBHOOK_URL"),
+			Token:      os.Getenv("SLACK_TOKEN"),
+			Channel:    os.Getenv("SLACK_CHANNEL"),
+		},
+	}
+	return config
+}
+
+// envReference matches a reference to an environment variable in a
+// configuration file, e.g. ${SLACK_TOKEN}.
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Parse reads and validates a configuration from YAML on top of the defaults.
+// References to environment variables, e.g. ${SLACK_TOKEN}, are expanded so
+// that secrets can stay out of the file. Unknown fields are rejected.
+func Parse(data []byte) (*OperatorConfig, error) {
+	data, err := expandEnv(data)
+	if err != nil {
+		return nil, err
+	}
+	config := Default()
+	config.APIVersion, config.Kind = "", ""
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// expandEnv replaces the ${VARIABLE} references of data with the value of the
+// environment variable. Any other $ is kept as is, and it fails with every
+// variable referenced that is not set, rather than expanding it empty.
+func expandEnv(data []byte) ([]byte, error) {
+	var errs []error
+	expanded := envReference.ReplaceAllFunc(data, func(reference []byte) []byte {
+		name := string(envReference.FindSubmatch(reference)[1])
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			errs = append(errs, fmt.Errorf("environment variable %s is not set", name))
+		}
+		return []byte(value)
+	})
+	return expanded, errors.Join(errs...)
+}
+
+// Validate fails with all the invalid settings of the configuration.
+func (c *OperatorConfig) Validate() error {
+	var errs []error
+	if c.APIVersion != APIVersion {
+		errs = append(errs, fmt.Errorf("apiVersion: unsupported version %q, expected %q", c.APIVersion, APIVersion))
+	}
+	if c.Kind != Kind {
+		errs = append(errs, fmt.Errorf("kind: unsupported kind %q, expected %q", c.Kind, Kind))
+	}
+	errs = append(errs, validateURL("prometheus.url", c.Prometheus.URL, true))
+	if alerts := c.Alerts.Prometheus; alerts != nil {
+		errs = append(errs, validateURL("alerts.prometheus.address", alerts.Address, true))
+	}
+	if alerts := c.Alerts.GitOps; alerts != nil {
+		if alerts.RepoURL == "" {
+			errs = append(errs, errors.New("alerts.gitops.repoURL: must be set"))
+		}
+		if alerts.RepoDir == "" {
+			errs = append(errs, errors.New("alerts.gitops.repoDir: must be set"))
+		}
+	}
+	if alerts := c.Alerts.Slack; alerts != nil {
+		errs = append(errs, validateURL("alerts.slack.webhookURL", alerts.WebhookURL, true))
+	}
+	for _, level := range []struct {
+		field      string
+		percentage int
+	}{
+		{"efficiencyLevels.high", c.EfficiencyLevels.High},
+		{"efficiencyLevels.medium", c.EfficiencyLevels.Medium},
+		{"efficiencyLevels.low", c.EfficiencyLevels.Low},
+	} {
+		if level.percentage < 1 || level.percentage > 100 {
+			errs = append(errs, fmt.Errorf("%s: must be between 1 and 100, got %d", level.field, level.percentage))
+		}
+	}
+	if err := c.MetricNames.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("metricNames: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// validateURL fails when value is not an absolute HTTP or HTTPS URL, or is
+// empty while required.
+func validateURL(field, value string, required bool) error {
+	if value == "" {
+		if required {
+			return fmt.Errorf("%s: must be set", field)
+		}
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", field, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s: must be an http or https URL, got %q", field, value)
+	}
+	return nil
+}
+
+// AlertManagers returns the configuration of the alert backends in the form
+// read by alert.NewAlertService.
+func (c *OperatorConfig) AlertManagers() map[string]map[string]string {
+	managers := make(map[string]map[string]string, 3)
+	if alerts := c.Alerts.Prometheus; alerts != nil {
+		managers[string(alert.Prometheus)] = map[string]string{"prometheusAddress": alerts.Address}
+	}
+	if alerts := c.Alerts.GitOps; alerts != nil {
+		managers[string(alert.GitOps)] = map[string]string{"repoURL": alerts.RepoURL, "repoDir": alerts.RepoDir}
+	}
+	if alerts := c.Alerts.Slack; alerts != nil {
+		managers[string(alert.Slack)] = map[string]string{
+			"webhookURL": alerts.WebhookURL,
+			"token":      alerts.Token,
+			"channel":    alerts.Channel,
+		}
+	}
+	return managers
+}
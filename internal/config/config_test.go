@@ -0,0 +1,121 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Climatik-Project/Climatik-Project/internal/metrics"
+)
+
+func TestParse(t *testing.T) {
+	t.Setenv("TEST_SLACK_TOKEN", "xoxb-secret")
+	config, err := Parse([]byte(`
+apiVersion: climatik-project.io/v1alpha1
+kind: OperatorConfig
+prometheus:
+  url: http://prometheus.monitoring:9090
+alerts:
+  slack:
+    webhookURL: https://hooks.slack.com/services/T/B/X
+    token: ${TEST_SLACK_TOKEN}
+    channel: power
+efficiencyLevels:
+  high: 95
+metricNames:
+  kepler:
+    podLabel: pod
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://prometheus.monitoring:9090", config.Prometheus.URL)
+	assert.Equal(t, EfficiencyLevels{High: 95, Medium: 80, Low: 50}, config.EfficiencyLevels)
+	assert.Equal(t, "pod", config.MetricNames.Kepler.PodLabel)
+	assert.Equal(t, metrics.DefaultNames().Kepler.ContainerJoules, config.MetricNames.Kepler.ContainerJoules)
+	assert.Equal(t, map[string]map[string]string{
+		"slack": {"webhookURL": "https://hooks.slack.com/services/T/B/X", "token": "xoxb-secret", "channel": "power"},
+	}, config.AlertManagers())
+}
+
+func TestParseExpandsOnlyReferencesToSetVariables(t *testing.T) {
+	t.Setenv("TEST_SLACK_TOKEN", "xoxb-secret")
+	config, err := Parse([]byte(header + `alerts:
+  slack:
+    webhookURL: https://hooks.slack.com/services/T/B/X
+    token: ${TEST_SLACK_TOKEN}
+    channel: $power
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "xoxb-secret", config.Alerts.Slack.Token)
+	assert.Equal(t, "$power", config.Alerts.Slack.Channel, "a literal $ is kept")
+
+	_, err = Parse([]byte(header + "alerts:\n  slack:\n    webhookURL: ${TEST_UNSET_WEBHOOK_URL}\n"))
+	assert.ErrorContains(t, err, "environment variable TEST_UNSET_WEBHOOK_URL is not set")
+}
+
+func TestEfficiencyLevelsPowerCapPercentage(t *testing.T) {
+	levels := EfficiencyLevels{High: 90, Medium: 80, Low: 50}
+
+	assert.Equal(t, 90, levels.PowerCapPercentage("High"))
+	assert.Equal(t, 50, levels.PowerCapPercentage("low"))
+	assert.Equal(t, 80, levels.PowerCapPercentage(""), "unset levels are medium")
+}
+
+func TestParseRejectsInvalidConfigs(t *testing.T) {
+	for name, test := range map[string]struct {
+		yaml string
+		err  string
+	}{
+		"missing version": {
+			yaml: "kind: OperatorConfig\n",
+			err:  "apiVersion: unsupported version",
+		},
+		"unknown field": {
+			yaml: "apiVersion: climatik-project.io/v1alpha1\nkind: OperatorConfig\nprometheus:\n  address: http://prometheus:9090\n",
+			err:  `unknown field "address"`,
+		},
+		"invalid URL": {
+			yaml: "apiVersion: climatik-project.io/v1alpha1\nkind: OperatorConfig\nprometheus:\n  url: prometheus:9090\n",
+			err:  "prometheus.url: must be an http or https URL",
+		},
+		"incomplete gitops": {
+			yaml: "apiVersion: climatik-project.io/v1alpha1\nkind: OperatorConfig\nalerts:\n  gitops:\n    repoURL: https://example.com/repo.git\n",
+			err:  "alerts.gitops.repoDir: must be set",
+		},
+		"percentage out of range": {
+			yaml: "apiVersion: climatik-project.io/v1alpha1\nkind: OperatorConfig\nefficiencyLevels:\n  low: 0\n",
+			err:  "efficiencyLevels.low: must be between 1 and 100",
+		},
+		"invalid metric name": {
+			yaml: "apiVersion: climatik-project.io/v1alpha1\nkind: OperatorConfig\nmetricNames:\n  dcgm:\n    powerUsage: DCGM-POWER\n",
+			err:  "metricNames: dcgm.powerUsage: invalid metric name",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(test.yaml))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestFromEnvironment(t *testing.T) {
+	t.Setenv("PROM_URL", "http://thanos:9090")
+	t.Setenv("PROMETHEUS_HOST", "http://alertmanager:9093")
+	t.Setenv("GITOPS_REPO_URL", "https://example.com/repo.git")
+	t.Setenv("GITOPS_REPO_DIR", "/tmp/repo")
+	t.Setenv("SLACK_WEBHOOK_URL", "https://hooks.slack.com/services/T/B/X")
+	t.Setenv("SLACK_TOKEN", "")
+	t.Setenv("SLACK_CHANNEL", "")
+
+	config := FromEnvironment()
+
+	assert.NoError(t, config.Validate())
+	assert.Equal(t, "http://thanos:9090", config.Prometheus.URL)
+	assert.Equal(t, map[string]map[string]string{
+		"prometheus": {"prometheusAddress": "http://alertmanager:9093"},
+		"gitops":     {"repoURL": "https://example.com/repo.git", "repoDir": "/tmp/repo"},
+		"slack":      {"webhookURL": "https://hooks.slack.com/services/T/B/X", "token": "", "channel": ""},
+	}, config.AlertManagers())
+}
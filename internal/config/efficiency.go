@@ -0,0 +1,63 @@
+// efficiency.go
+package config
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+)
+
+// EfficiencyLevels are the power cap percentages of the efficiency levels of
+// configs, followed by the configs that set no percentage of their own.
+type EfficiencyLevels struct {
+	High   int `json:"high"`
+	Medium int `json:"medium"`
+	Low    int `json:"low"`
+}
+
+// DefaultEfficiencyLevels returns the percentages of the efficiency levels
+// used unless the operator is configured otherwise.
+func DefaultEfficiencyLevels() EfficiencyLevels {
+	return EfficiencyLevels{
+		High:   v1alpha1.DefaultPowerCapPercentageHigh,
+		Medium: v1alpha1.DefaultPowerCapPercentageMedium,
+		Low:    v1alpha1.DefaultPowerCapPercentageLow,
+	}
+}
+
+// PowerCapPercentage returns the percentage of an efficiency level, or of the
+// medium level when the level is unset or unknown.
+func (l EfficiencyLevels) PowerCapPercentage(level string) int {
+	switch strings.ToLower(level) {
+	case v1alpha1.EfficiencyLevelHigh:
+		return l.High
+	case v1alpha1.EfficiencyLevelLow:
+		return l.Low
+	default:
+		return l.Medium
+	}
+}
+
+// SharedEfficiencyLevels holds the efficiency levels read by the controller,
+// replaced as a whole when the operator configuration is reloaded.
+type SharedEfficiencyLevels struct {
+	levels atomic.Pointer[EfficiencyLevels]
+}
+
+// NewSharedEfficiencyLevels returns shared efficiency levels holding levels.
+func NewSharedEfficiencyLevels(levels EfficiencyLevels) *SharedEfficiencyLevels {
+	s := &SharedEfficiencyLevels{}
+	s.Store(levels)
+	return s
+}
+
+// Load returns the efficiency levels held.
+func (s *SharedEfficiencyLevels) Load() EfficiencyLevels {
+	return *s.levels.Load()
+}
+
+// Store replaces the efficiency levels held.
+func (s *SharedEfficiencyLevels) Store(levels EfficiencyLevels) {
+	s.levels.Store(&levels)
+}
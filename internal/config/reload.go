@@ -0,0 +1,94 @@
+// reload.go
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var log = ctrl.Log.WithName("config")
+
+// DefaultReloadInterval is how often the configuration file is read for
+// changes when no interval is given.
+const DefaultReloadInterval = 10 * time.Second
+
+// Reloader reads the configuration file again every interval, and calls
+// OnChange with the new configuration whenever its content changes. It reads
+// the content rather than watching the file, since a mounted ConfigMap is
+// updated by swapping symbolic links. Invalid configurations are logged and
+// ignored, so that the operator keeps running with the last valid one.
+//
+// Reloader is a manager.Runnable, run by every replica of the manager.
+type Reloader struct {
+	Path     string
+	Interval time.Duration
+	OnChange func(*OperatorConfig)
+
+	last []byte
+}
+
+// Load reads and validates the configuration file, which is then only passed
+// to OnChange again once its content changes.
+func (r *Reloader) Load() (*OperatorConfig, error) {
+	data, err := os.ReadFile(r.Path)
+	if err != nil {
+		return nil, err
+	}
+	config, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid operator configuration %s: %w", r.Path, err)
+	}
+	r.last = data
+	return config, nil
+}
+
+// Start reads the configuration file every interval until ctx is done.
+func (r *Reloader) Start(ctx context.Context) error {
+	interval := r.Interval
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.reload()
+		}
+	}
+}
+
+// NeedLeaderElection returns false, since every replica has to follow the
+// configuration.
+func (r *Reloader) NeedLeaderElection() bool {
+	return false
+}
+
+// reload reads the configuration file and calls OnChange when its content
+// changed into a valid configuration. It returns whether OnChange was called.
+func (r *Reloader) reload() bool {
+	data, err := os.ReadFile(r.Path)
+	if err != nil {
+		log.Error(err, "Failed to read operator configuration", "path", r.Path)
+		return false
+	}
+	if bytes.Equal(data, r.last) {
+		return false
+	}
+	r.last = data
+	config, err := Parse(data)
+	if err != nil {
+		log.Error(err, "Ignoring invalid operator configuration", "path", r.Path)
+		return false
+	}
+	log.Info("Operator configuration reloaded", "path", r.Path)
+	r.OnChange(config)
+	return true
+}
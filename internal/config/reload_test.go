@@ -0,0 +1,43 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const header = "apiVersion: climatik-project.io/v1alpha1\nkind: OperatorConfig\n"
+
+func TestReloaderFollowsValidChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(header+"prometheus:\n  url: http://a:9090\n"), 0o600))
+	var reloaded []string
+	reloader := &Reloader{Path: path, OnChange: func(config *OperatorConfig) {
+		reloaded = append(reloaded, config.Prometheus.URL)
+	}}
+
+	config, err := reloader.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "http://a:9090", config.Prometheus.URL)
+	assert.False(t, reloader.reload(), "an unchanged file is not reloaded")
+
+	require.NoError(t, os.WriteFile(path, []byte(header+"prometheus:\n  url: b:9090\n"), 0o600))
+	assert.False(t, reloader.reload(), "an invalid file is ignored")
+
+	require.NoError(t, os.WriteFile(path, []byte(header+"prometheus:\n  url: http://b:9090\n"), 0o600))
+	assert.True(t, reloader.reload())
+	assert.False(t, reloader.reload())
+	assert.Equal(t, []string{"http://b:9090"}, reloaded)
+}
+
+func TestReloaderLoadRejectsInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("kind: OperatorConfig\n"), 0o600))
+
+	_, err := (&Reloader{Path: path}).Load()
+
+	assert.ErrorContains(t, err, "invalid operator configuration")
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+)
+
+// aggregateEvaluation holds the power figures of all pods of a config taken
+// together, in watts. total carries the summed current power, the peak and
+// average of the summed power and the aggregate power cap; pods carries the
+// current power of each pod and its part of the aggregate cap.
+type aggregateEvaluation struct {
+	total podEvaluation
+	pods  []podEvaluation
+}
+
+func (a *aggregateEvaluation) exceeded() bool {
+	return a.total.currentPower > a.total.powerCap
+}
+
+// shares returns the per-pod breakdown reported in the status.
+func (a *aggregateEvaluation) shares() []powercappingv1alpha1.PodPowerShare {
+	shares := make([]powercappingv1alpha1.PodPowerShare, 0, len(a.pods))
+	for _, evaluation := range a.pods {
+		shares = append(shares, powercappingv1alpha1.PodPowerShare{
+			Name:             evaluation.pod.Name,
+			Namespace:        evaluation.pod.Namespace,
+			PowerConsumption: int(evaluation.currentPower),
+			SharePercentage:  int(shareOf(evaluation.currentPower, a.total.currentPower, len(a.pods)) * 100),
+			PowerCapInWatts:  int(evaluation.powerCap),
+		})
+	}
+	return shares
+}
+
+// shareOf returns the fraction of total taken by part, splitting evenly
+// between count parts when nothing is consumed.
+func shareOf(part, total float64, count int) float64 {
+	if total <= 0 {
+		return 1 / float64(count)
+	}
+	return part / total
+}
+
+// enforceAggregatePowerCap evaluates the sum of the power of all pods against
+// the power cap of the config and alerts every pod with its part of the cap
+// while the sum, or the proactive forecast of the sum, exceeds it. The cap is
+// bounded by budget, the PowerBudget allocation of the config, when it is
+// positive. It returns nil when there is nothing to evaluate, along with the
+// number of pods that could not be evaluated.
+func (r *PowerCappingConfigReconciler) enforceAggregatePowerCap(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, pods []corev1.Pod, window time.Duration, budget float64, proactive *powerForecast) (*aggregateEvaluation, int) {
+	spec := &powerCappingConfig.Spec.PowerCappingSpec
+	if (!isPowerCapped(powerCappingConfig) && budget <= 0) || len(pods) == 0 {
+		return nil, 0
+	}
+
+	aggregate := &aggregateEvaluation{pods: make([]podEvaluation, 0, len(pods))}
+	failed := 0
+	for i := range pods {
+		pod := &pods[i]
+		currentPower, err := r.measureCurrentPower(ctx, powerCappingConfig, *pod)
+		if err != nil {
+			log.Error(err, "Failed to query pod power", "pod", pod.Name, "namespace", pod.Namespace)
+			failed++
+			continue
+		}
+		aggregate.pods = append(aggregate.pods, podEvaluation{pod: pod, currentPower: currentPower})
+		aggregate.total.currentPower += currentPower
+	}
+	if len(aggregate.pods) == 0 {
+		return nil, failed
+	}
+
+	reading, err := r.measurePods(ctx, powerCappingConfig, pods, window)
+	if err != nil {
+		log.Error(err, "Failed to query aggregate power", "powerCappingConfig", powerCappingConfig.Name)
+		return nil, len(pods)
+	}
+	aggregate.total.peakPower = reading.Peak
+	aggregate.total.averagePower = reading.Average
+
+	switch spec.Kind {
+	case v1alpha1.AbsolutePowerCapInWatts:
+		aggregate.total.powerCap = float64(spec.PowerCapInWatts)
+	case v1alpha1.RelativePowerCapOfPeakPowerConsumptionInPercentage:
+		aggregate.total.powerCap = r.calculatePowerCap(aggregate.total.peakPower, r.getPowerCapPercentage(powerCappingConfig))
+	case v1alpha1.RelativePowerCappingOfAveragePowerConsumptionInPercentage:
+		aggregate.total.powerCap = r.calculatePowerCap(aggregate.total.averagePower, r.getPowerCapPercentage(powerCappingConfig))
+	case v1alpha1.CarbonAwarePowerCapInWatts:
+		intensity, err := r.carbonIntensity(ctx, powerCappingConfig)
+		if err != nil {
+			log.Error(err, "Failed to get carbon intensity", "powerCappingConfig", powerCappingConfig.Name)
+			return nil, len(pods)
+		}
+		aggregate.total.powerCap = carbonAwarePowerCap(&spec.CarbonAwarePowerCapSpec, intensity.Value)
+	}
+	scheduleWindow, _ := r.scheduleWindow(powerCappingConfig)
+	aggregate.total.powerCap = scheduledPowerCap(scheduleWindow, aggregate.total.powerCap)
+	budgeted := budgetedPowerCap(powerCappingConfig, aggregate.total.powerCap, budget)
+	aggregate.total.followsPower = budgeted == aggregate.total.powerCap && followsPodPower(spec.Kind, scheduleWindow)
+	aggregate.total.powerCap = budgeted
+	for i := range aggregate.pods {
+		evaluation := &aggregate.pods[i]
+		evaluation.powerCap = aggregate.total.powerCap * shareOf(evaluation.currentPower, aggregate.total.currentPower, len(aggregate.pods))
+		if proactive != nil {
+			evaluation.forecastPower = evaluation.currentPower * proactive.growth
+		}
+	}
+	if proactive != nil {
+		aggregate.total.forecastPower = proactive.power
+	}
+
+	key := client.ObjectKeyFromObject(powerCappingConfig)
+	powerCap := int(aggregate.total.powerCap)
+	log.Info("Aggregate power cap calculated", "powerCappingConfig", key, "kind", spec.Kind, "pods", len(aggregate.pods),
+		"currentPower", aggregate.total.currentPower, "peakPower", aggregate.total.peakPower,
+		"averagePower", aggregate.total.averagePower, "forecastPower", aggregate.total.forecastPower, "powerCap", powerCap)
+	hysteresis := getHysteresis(powerCappingConfig)
+	if !aggregate.total.overCap() {
+		// Within the hysteresis band under the cap, the alert is kept.
+		if aggregate.total.underCap(hysteresis) && r.forgetAggregateAlert(key) {
+			for _, evaluation := range aggregate.pods {
+				r.resolveAlert(ctx, evaluation.pod, powerCappingConfig)
+			}
+		}
+		return aggregate, failed
+	}
+	if r.shouldAlertAggregate(key, powerCap, hysteresis) {
+		for _, evaluation := range aggregate.pods {
+			devices := r.getPodDevices(ctx, powerCappingConfig, evaluation.pod)
+			if devices == nil {
+				devices = make(map[string]string, 2)
+			}
+			devices["aggregatePower"] = fmt.Sprintf("%.0f", aggregate.total.currentPower)
+			devices["aggregatePowerCap"] = fmt.Sprintf("%d", powerCap)
+			if aggregate.total.forecastPower > 0 {
+				devices["aggregateForecastPower"] = fmt.Sprintf("%.0f", aggregate.total.forecastPower)
+			}
+			if err := r.createAlert(evaluation.pod, int(evaluation.powerCap), devices, powerCappingConfig); err != nil {
+				log.Error(err, "Failed to create alert", "pod", evaluation.pod.Name)
+			}
+		}
+	}
+	return aggregate, failed
+}
+
+// shouldAlertAggregate records powerCap as the latest aggregate cap alerted
+// for the config and reports whether the config was not alerted yet or the
+// cap moved beyond the hysteresis band of the previous one.
+func (r *PowerCappingConfigReconciler) shouldAlertAggregate(key types.NamespacedName, powerCap int, hysteresis float64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.alertedAggregates == nil {
+		r.alertedAggregates = make(map[types.NamespacedName]int)
+	}
+	if last, ok := r.alertedAggregates[key]; ok && !movedBeyond(float64(last), float64(powerCap), hysteresis) {
+		return false
+	}
+	r.alertedAggregates[key] = powerCap
+	return true
+}
+
+// forgetAggregateAlert re-arms the aggregate alert of the config and reports
+// whether the config was alerted.
+func (r *PowerCappingConfigReconciler) forgetAggregateAlert(key types.NamespacedName) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.alertedAggregates[key]
+	delete(r.alertedAggregates, key)
+	return ok
+}
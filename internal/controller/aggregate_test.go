@@ -0,0 +1,66 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+)
+
+var _ = Describe("PowerCappingConfig Controller", func() {
+	Context("When computing an aggregate power cap", func() {
+		ctx := context.Background()
+		pods := []corev1.Pod{newTestPod("pod-a"), newTestPod("pod-b")}
+
+		newConfig := func(spec powercappingv1alpha1.PowerCappingSpec) *powercappingv1alpha1.PowerCappingConfig {
+			spec.Scope = powercappingv1alpha1.PowerCappingScopeAggregate
+			return newTestConfig(powercappingv1alpha1.PowerCappingConfigSpec{PowerCappingSpec: spec})
+		}
+
+		It("should compare the summed power to the absolute cap and split it by share", func() {
+			config := newConfig(powercappingv1alpha1.PowerCappingSpec{
+				Kind:                        powercappingv1alpha1.AbsolutePowerCapInWatts,
+				AbsolutePowerCapInWattsSpec: powercappingv1alpha1.AbsolutePowerCapInWattsSpec{PowerCapInWatts: 300},
+			})
+			aggregate, failed := newTestReconciler().enforceAggregatePowerCap(ctx, config, pods, defaultSampleWindow, 0, nil)
+			Expect(failed).To(BeZero())
+			Expect(aggregate.total.currentPower).To(Equal(500.0))
+			Expect(aggregate.total.powerCap).To(Equal(300.0))
+			Expect(aggregate.exceeded()).To(BeTrue())
+
+			shares := aggregate.shares()
+			Expect(shares).To(HaveLen(2))
+			Expect(shares[0].SharePercentage).To(Equal(50))
+			Expect(shares[0].PowerCapInWatts).To(Equal(150))
+		})
+
+		It("should scale the peak of the summed power for the peak-based kind", func() {
+			config := newConfig(powercappingv1alpha1.PowerCappingSpec{
+				Kind:                             powercappingv1alpha1.RelativePowerCapOfPeakPowerConsumptionInPercentage,
+				RelativePowerCapInPercentageSpec: powercappingv1alpha1.RelativePowerCapInPercentageSpec{PowerCapPercentage: 50},
+			})
+			aggregate, failed := newTestReconciler().enforceAggregatePowerCap(ctx, config, pods, defaultSampleWindow, 0, nil)
+			Expect(failed).To(BeZero())
+			Expect(aggregate.total.powerCap).To(Equal(200.0))
+		})
+	})
+})
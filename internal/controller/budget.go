@@ -0,0 +1,77 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+)
+
+// budgetAllocation returns the name of the PowerBudget that made the tightest
+// allocation to the config, along with the allocation. The allocation is zero
+// when no budget selects the config.
+func (r *PowerCappingConfigReconciler) budgetAllocation(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) (string, float64, error) {
+	budgetList := &powercappingv1alpha1.PowerBudgetList{}
+	if err := r.List(ctx, budgetList); err != nil {
+		return "", 0, err
+	}
+	var name string
+	var allocation float64
+	for _, powerBudget := range budgetList.Items {
+		for _, allocated := range powerBudget.Status.Allocations {
+			if allocated.Namespace != powerCappingConfig.Namespace || allocated.Name != powerCappingConfig.Name {
+				continue
+			}
+			if name == "" || float64(allocated.PowerCapInWatts) < allocation {
+				name, allocation = powerBudget.Name, float64(allocated.PowerCapInWatts)
+			}
+		}
+	}
+	return name, allocation, nil
+}
+
+// budgetedPowerCap bounds the power cap of the kind by the budget allocated to
+// the pods it applies to. The budget is the cap of configs that do not cap
+// power themselves.
+func budgetedPowerCap(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, powerCap, budget float64) float64 {
+	switch {
+	case budget <= 0:
+		return powerCap
+	case !isPowerCapped(powerCappingConfig):
+		return budget
+	default:
+		return min(powerCap, budget)
+	}
+}
+
+// mapBudgetToConfigs enqueues the configs allocated by a PowerBudget. Both the
+// old and the new budget are mapped on updates, so configs that lose their
+// allocation are reconciled too.
+func (r *PowerCappingConfigReconciler) mapBudgetToConfigs(ctx context.Context, obj client.Object) []reconcile.Request {
+	powerBudget, ok := obj.(*powercappingv1alpha1.PowerBudget)
+	if !ok {
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(powerBudget.Status.Allocations))
+	for _, allocated := range powerBudget.Status.Allocations {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKey{Namespace: allocated.Namespace, Name: allocated.Name}})
+	}
+	return requests
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+	"github.com/Climatik-Project/Climatik-Project/internal/carbon"
+)
+
+// carbonIntensity returns the current carbon intensity of the grid zone of the
+// config, or of the operator when the config names none.
+func (r *PowerCappingConfigReconciler) carbonIntensity(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) (*carbon.Intensity, error) {
+	if r.CarbonIntensityProvider == nil {
+		return nil, fmt.Errorf("no carbon intensity provider is configured")
+	}
+	zone := powerCappingConfig.Spec.PowerCappingSpec.Zone
+	if zone == "" {
+		zone = r.CarbonZone
+	}
+	return r.CarbonIntensityProvider.CarbonIntensity(ctx, zone)
+}
+
+// carbonAwarePowerCap returns the power cap in watts at the carbon intensity
+// in gCO2eq/kWh. The cap keeps the emissions within the carbon budget when one
+// is set, and otherwise falls linearly from the maximum to the minimum cap
+// across the carbon intensity range. It never leaves the range of caps.
+func carbonAwarePowerCap(spec *powercappingv1alpha1.CarbonAwarePowerCapSpec, intensity float64) float64 {
+	minCap, maxCap := float64(spec.MinPowerCapInWatts), float64(spec.MaxPowerCapInWatts)
+	if spec.CarbonBudgetInGramsPerHour > 0 {
+		if intensity <= 0 {
+			return maxCap
+		}
+		// gCO2eq/h divided by gCO2eq/kWh gives kW.
+		return min(max(float64(spec.CarbonBudgetInGramsPerHour)*1000/intensity, minCap), maxCap)
+	}
+	low, high := float64(spec.MinCarbonIntensity), float64(spec.MaxCarbonIntensity)
+	switch {
+	case intensity <= low:
+		return maxCap
+	case intensity >= high:
+		return minCap
+	default:
+		return maxCap - (maxCap-minCap)*(intensity-low)/(high-low)
+	}
+}
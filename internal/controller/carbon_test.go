@@ -0,0 +1,91 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+)
+
+var _ = Describe("PowerCappingConfig Controller", func() {
+	Context("When computing the power cap of a pod", func() {
+		ctx := context.Background()
+		pod := newTestPod("pod")
+
+		newConfig := func(spec powercappingv1alpha1.CarbonAwarePowerCapSpec) *powercappingv1alpha1.PowerCappingConfig {
+			return newTestConfig(powercappingv1alpha1.PowerCappingConfigSpec{
+				PowerCappingSpec: powercappingv1alpha1.PowerCappingSpec{
+					Kind:                    powercappingv1alpha1.CarbonAwarePowerCapInWatts,
+					CarbonAwarePowerCapSpec: spec,
+				},
+			})
+		}
+
+		It("should follow the carbon intensity for the carbon-aware kind", func() {
+			config := newConfig(powercappingv1alpha1.CarbonAwarePowerCapSpec{
+				MinPowerCapInWatts: 100,
+				MaxPowerCapInWatts: 500,
+				MinCarbonIntensity: 100,
+				MaxCarbonIntensity: 300,
+			})
+			intensities := &fakeCarbonIntensity{value: 150}
+			reconciler := newTestReconciler()
+			reconciler.CarbonIntensityProvider = intensities
+			reconciler.CarbonZone = "DE"
+			evaluation, err := reconciler.enforcePowerCap(ctx, config, &pod, defaultSampleWindow, 0, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(evaluation.powerCap).To(Equal(400.0))
+			Expect(intensities.zone).To(Equal("DE"))
+
+			config.Spec.PowerCappingSpec.Zone = "FR"
+			_, err = reconciler.enforcePowerCap(ctx, config, &pod, defaultSampleWindow, 0, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(intensities.zone).To(Equal("FR"))
+		})
+
+		It("should fail the carbon-aware kind without carbon intensity", func() {
+			config := newConfig(powercappingv1alpha1.CarbonAwarePowerCapSpec{
+				MinPowerCapInWatts:         100,
+				MaxPowerCapInWatts:         500,
+				CarbonBudgetInGramsPerHour: 50,
+			})
+			_, err := newTestReconciler().enforcePowerCap(ctx, config, &pod, defaultSampleWindow, 0, nil)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should scale the carbon-aware cap within its range", func() {
+			linear := &powercappingv1alpha1.CarbonAwarePowerCapSpec{
+				MinPowerCapInWatts: 100, MaxPowerCapInWatts: 500, MinCarbonIntensity: 100, MaxCarbonIntensity: 300,
+			}
+			Expect(carbonAwarePowerCap(linear, 50)).To(Equal(500.0))
+			Expect(carbonAwarePowerCap(linear, 200)).To(Equal(300.0))
+			Expect(carbonAwarePowerCap(linear, 400)).To(Equal(100.0))
+
+			budget := &powercappingv1alpha1.CarbonAwarePowerCapSpec{
+				MinPowerCapInWatts: 100, MaxPowerCapInWatts: 500, CarbonBudgetInGramsPerHour: 60,
+			}
+			Expect(carbonAwarePowerCap(budget, 200)).To(Equal(300.0))
+			Expect(carbonAwarePowerCap(budget, 60)).To(Equal(500.0))
+			Expect(carbonAwarePowerCap(budget, 1000)).To(Equal(100.0))
+			Expect(carbonAwarePowerCap(budget, 0)).To(Equal(500.0))
+		})
+	})
+})
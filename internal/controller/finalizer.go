@@ -0,0 +1,168 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+	"github.com/Climatik-Project/Climatik-Project/internal/actuator"
+)
+
+// finalizerName holds a config back from deletion until the replicas it
+// bounded are restored, the nodes it cordoned or tainted are released and the
+// alerts of its pods are resolved.
+const finalizerName = "climatik-project.io/finalizer"
+
+// ensureFinalizer adds the finalizer to the config, since any config may
+// alert on its pods.
+func (r *PowerCappingConfigReconciler) ensureFinalizer(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) error {
+	if controllerutil.ContainsFinalizer(powerCappingConfig, finalizerName) {
+		return nil
+	}
+	controllerutil.AddFinalizer(powerCappingConfig, finalizerName)
+	return r.Update(ctx, powerCappingConfig)
+}
+
+// finalize restores the replicas of every scale target referenced by, or
+// reported in the status of, a config being deleted, releases the nodes
+// reported in its status, resolves the alerts of the pods it alerted in every
+// alert manager and then removes the finalizer. The finalizer is kept while
+// any scale target cannot be restored or node released so that the cleanup is
+// retried; alerts are retried up to maxResolveAttempts times.
+func (r *PowerCappingConfigReconciler) finalize(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) error {
+	if !controllerutil.ContainsFinalizer(powerCappingConfig, finalizerName) {
+		return nil
+	}
+	refs := scaleTargetRefs(powerCappingConfig)
+	_, stale := reportedScaleTargets(powerCappingConfig, refs)
+	if _, failed := r.restoreScaleTargets(ctx, append(refs, stale...)); failed > 0 {
+		return fmt.Errorf("%d scale targets could not be restored", failed)
+	}
+	nodes := actuator.NewNodeActuator(r.Client, client.ObjectKeyFromObject(powerCappingConfig).String())
+	for _, node := range powerCappingConfig.Status.Nodes {
+		if err := r.releaseNode(ctx, nodes, node.Name); err != nil {
+			return fmt.Errorf("node %s could not be released: %w", node.Name, err)
+		}
+	}
+	if err := r.resolveAlerts(ctx, powerCappingConfig); err != nil {
+		return err
+	}
+	controllerutil.RemoveFinalizer(powerCappingConfig, finalizerName)
+	return r.Update(ctx, powerCappingConfig)
+}
+
+// maxResolveAttempts bounds the reconciles of a deleted config retrying the
+// alerts that could not be resolved, after which the config is deleted anyway
+// rather than held back by an unavailable alert manager.
+const maxResolveAttempts = 5
+
+// resolveAlerts resolves the alerts of the pods alerted by the config, which
+// may have stopped matching since. Resolved pods are not resolved again when
+// others fail and are retried.
+func (r *PowerCappingConfigReconciler) resolveAlerts(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) error {
+	if r.AlertService == nil {
+		return nil
+	}
+	key := client.ObjectKeyFromObject(powerCappingConfig)
+	var errs []error
+	for _, name := range r.alertedPods(powerCappingConfig) {
+		if err := r.AlertService.ResolveAlert(ctx, name, powerCappingConfig); err != nil {
+			errs = append(errs, fmt.Errorf("alert of pod %s could not be resolved: %w", name, err))
+			continue
+		}
+		r.recordAlert(powerCappingConfig, name, false)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.resolveAttempts == nil {
+		r.resolveAttempts = make(map[types.NamespacedName]int)
+	}
+	r.resolveAttempts[key]++
+	if r.resolveAttempts[key] < maxResolveAttempts {
+		return errors.Join(errs...)
+	}
+	log.Error(errors.Join(errs...), "Giving up resolving alerts of deleted config", "powerCappingConfig", key,
+		"attempts", r.resolveAttempts[key])
+	return nil
+}
+
+// recordAlert records whether the alert of a pod of the config is open. The
+// pods alerted before the operator restarted are read from the status.
+func (r *PowerCappingConfigReconciler) recordAlert(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, name string, open bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	alerts := r.openAlertsLocked(powerCappingConfig)
+	if open {
+		alerts[name] = true
+	} else {
+		delete(alerts, name)
+	}
+}
+
+// alertedPods returns the sorted names of the pods of the config whose alert
+// is open.
+func (r *PowerCappingConfigReconciler) alertedPods(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	alerts := r.openAlertsLocked(powerCappingConfig)
+	if len(alerts) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(alerts))
+	for name := range alerts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// openAlertsLocked returns the open alerts of the config, seeded from its
+// status on first use. r.mu must be held.
+func (r *PowerCappingConfigReconciler) openAlertsLocked(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) map[string]bool {
+	key := client.ObjectKeyFromObject(powerCappingConfig)
+	if r.openAlerts == nil {
+		r.openAlerts = make(map[types.NamespacedName]map[string]bool)
+	}
+	alerts, ok := r.openAlerts[key]
+	if !ok {
+		alerts = make(map[string]bool, len(powerCappingConfig.Status.AlertedPods))
+		for _, name := range powerCappingConfig.Status.AlertedPods {
+			alerts[name] = true
+		}
+		r.openAlerts[key] = alerts
+	}
+	return alerts
+}
+
+// forgetOpenAlerts drops the open alerts and resolve attempts of a config that
+// is gone.
+func (r *PowerCappingConfigReconciler) forgetOpenAlerts(key types.NamespacedName) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.openAlerts, key)
+	delete(r.resolveAttempts, key)
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+	"github.com/Climatik-Project/Climatik-Project/internal/actuator"
+)
+
+var _ = Describe("PowerCappingConfig Controller", func() {
+	Context("When deleting a config", func() {
+		ctx := context.Background()
+
+		// newDeletedConfig returns a config being deleted, still held by the
+		// finalizer of the operator.
+		newDeletedConfig := func(spec powercappingv1alpha1.PowerCappingConfigSpec) *powercappingv1alpha1.PowerCappingConfig {
+			now := metav1.Now()
+			config := newTestConfig(spec)
+			config.Finalizers = []string{finalizerName}
+			config.DeletionTimestamp = &now
+			return config
+		}
+		// deleted reports whether the config was released and is gone.
+		deleted := func(reconciler *PowerCappingConfigReconciler, config *powercappingv1alpha1.PowerCappingConfig) bool {
+			err := reconciler.Get(ctx, client.ObjectKeyFromObject(config), &powercappingv1alpha1.PowerCappingConfig{})
+			return errors.IsNotFound(err)
+		}
+
+		It("should restore the scale targets before releasing a deleted config", func() {
+			replicas := int32(2)
+			config := newDeletedConfig(powercappingv1alpha1.PowerCappingConfigSpec{
+				ScaleTargetRefs: []powercappingv1alpha1.ScaleTargetReference{{Kind: "Deployment", Name: "llm-server"}},
+			})
+			reconciler := newTestReconciler(config, &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "llm-server",
+					Namespace:   "default",
+					Annotations: map[string]string{actuator.OriginalReplicasAnnotation: "6"},
+				},
+				Spec: appsv1.DeploymentSpec{Replicas: &replicas},
+			})
+
+			Expect(reconciler.finalize(ctx, config)).To(Succeed())
+			deployment := &appsv1.Deployment{}
+			Expect(reconciler.Get(ctx, types.NamespacedName{Name: "llm-server", Namespace: "default"}, deployment)).To(Succeed())
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(6)))
+			Expect(deleted(reconciler, config)).To(BeTrue())
+		})
+
+		It("should resolve the alerts of the alerted pods before releasing a deleted config", func() {
+			podLabels := map[string]string{"app": "llm"}
+			config := newDeletedConfig(powercappingv1alpha1.PowerCappingConfigSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+			})
+			config.Status = powercappingv1alpha1.PowerCappingConfigStatus{
+				// llm-b stopped matching after it was alerted.
+				AlertedPods:    []string{"llm-b", "llm-c"},
+				PodPowerShares: []powercappingv1alpha1.PodPowerShare{{Name: "llm-c", Namespace: "default"}},
+			}
+			reconciler := newTestReconciler(config, newRunningPod("llm-a", podLabels))
+			alerts := &fakeAlertManager{err: errors.NewServiceUnavailable("slack is down")}
+			reconciler.AlertService = newAlertService(alerts)
+
+			// The finalizer is kept while the alerts are retried.
+			Expect(reconciler.finalize(ctx, config)).NotTo(Succeed())
+			Expect(deleted(reconciler, config)).To(BeFalse())
+
+			alerts.err = nil
+			alerts.resolved = nil
+			Expect(reconciler.finalize(ctx, config)).To(Succeed())
+			// llm-a was never alerted, so no resolution is posted for it.
+			Expect(alerts.resolved).To(Equal([]string{"llm-b", "llm-c"}))
+			Expect(deleted(reconciler, config)).To(BeTrue())
+		})
+
+		It("should release a deleted config once its alerts failed to resolve too often", func() {
+			config := newDeletedConfig(powercappingv1alpha1.PowerCappingConfigSpec{})
+			config.Status.AlertedPods = []string{"llm-a"}
+			reconciler := newTestReconciler(config)
+			alerts := &fakeAlertManager{err: errors.NewServiceUnavailable("slack is down")}
+			reconciler.AlertService = newAlertService(alerts)
+
+			for i := 1; i < maxResolveAttempts; i++ {
+				Expect(reconciler.finalize(ctx, config)).NotTo(Succeed())
+			}
+			Expect(reconciler.finalize(ctx, config)).To(Succeed())
+			Expect(alerts.resolved).To(HaveLen(maxResolveAttempts))
+			Expect(deleted(reconciler, config)).To(BeTrue())
+		})
+
+		It("should record the alerted pods in the status until they are resolved", func() {
+			config := newTestConfig(powercappingv1alpha1.PowerCappingConfigSpec{})
+			config.Status.AlertedPods = []string{"llm-b"}
+			reconciler := newTestReconciler(config)
+			reconciler.AlertService = newAlertService(&fakeAlertManager{})
+			pod := newTestPod("llm-a")
+
+			Expect(reconciler.createAlert(&pod, 100, nil, config)).To(Succeed())
+			Expect(reconciler.alertedPods(config)).To(Equal([]string{"llm-a", "llm-b"}))
+			reconciler.resolveAlert(ctx, &pod, config)
+			Expect(reconciler.alertedPods(config)).To(Equal([]string{"llm-b"}))
+		})
+	})
+})
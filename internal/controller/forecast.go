@@ -0,0 +1,101 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+	"github.com/Climatik-Project/Climatik-Project/internal/forecast"
+)
+
+// powerForecast is the power of all pods of a config together predicted at
+// the end of the forecast horizon, in watts. growth is its ratio to the power
+// last measured, which projects the power of each pod; it is zero when no
+// power was measured.
+type powerForecast struct {
+	power  float64
+	growth float64
+}
+
+// forecastPower fits the forecasting model of the config to the recent power
+// of the pods and predicts it at the end of the horizon. It returns nil
+// without error when the config has no forecast or no pods.
+func (r *PowerCappingConfigReconciler) forecastPower(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, pods []corev1.Pod) (*powerForecast, error) {
+	spec := powerCappingConfig.Spec.Forecast
+	if spec == nil || len(pods) == 0 {
+		return nil, nil
+	}
+	horizon := secondsOrDefault(spec.HorizonInSeconds, v1alpha1.DefaultForecastHorizonInSeconds)
+	lookback := secondsOrDefault(spec.LookbackInSeconds, v1alpha1.DefaultForecastLookbackInSeconds)
+	step := secondsOrDefault(spec.StepInSeconds, v1alpha1.DefaultForecastStepInSeconds)
+	seasonLength := 0
+	if spec.SeasonInSeconds > 0 {
+		seasonLength = int(time.Duration(spec.SeasonInSeconds) * time.Second / step)
+	}
+	forecaster, err := forecast.New(spec.Method, seasonLength)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := r.metricsSource(powerCappingConfig)
+	if err != nil {
+		return nil, err
+	}
+	// The history covers the pods running now, so replaced pods drop out of it.
+	history, err := source.PodPowerHistory(ctx, podKeys(pods), lookback, step)
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]forecast.Sample, 0, len(history))
+	for _, sample := range history {
+		samples = append(samples, forecast.Sample{Time: sample.Time, Value: sample.Value})
+	}
+	power, err := forecaster.Forecast(samples, horizon)
+	if err != nil {
+		return nil, err
+	}
+
+	predicted := &powerForecast{power: max(power, 0)}
+	if last := samples[len(samples)-1].Value; last > 0 {
+		predicted.growth = predicted.power / last
+	}
+	log.Info("Power forecast", "powerCappingConfig", powerCappingConfig.Name, "method", spec.Method,
+		"samples", len(samples), "horizon", horizon, "power", predicted.power)
+	return predicted, nil
+}
+
+// proactiveForecast returns the forecast when the config acts on it, and nil
+// otherwise.
+func proactiveForecast(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, predicted *powerForecast) *powerForecast {
+	if spec := powerCappingConfig.Spec.Forecast; spec == nil || !spec.Proactive {
+		return nil
+	}
+	return predicted
+}
+
+// secondsOrDefault returns seconds as a duration, or fallback seconds when it
+// is not positive.
+func secondsOrDefault(seconds, fallback int) time.Duration {
+	if seconds <= 0 {
+		seconds = fallback
+	}
+	return time.Duration(seconds) * time.Second
+}
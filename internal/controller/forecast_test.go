@@ -0,0 +1,114 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+)
+
+var _ = Describe("PowerCappingConfig Controller", func() {
+	Context("When forecasting power", func() {
+		ctx := context.Background()
+		pods := []corev1.Pod{newTestPod("pod-a"), newTestPod("pod-b")}
+
+		// The summed power of the pods rose by 10W a minute to 500W.
+		newReconciler := func(objs ...client.Object) *PowerCappingConfigReconciler {
+			reconciler := newTestReconciler(objs...)
+			reconciler.PrometheusClient.(*fakePrometheus).history = []float64{410, 420, 430, 440, 450, 460, 470, 480, 490, 500}
+			return reconciler
+		}
+		newConfig := func(forecast *powercappingv1alpha1.PowerForecastSpec) *powercappingv1alpha1.PowerCappingConfig {
+			return newTestConfig(powercappingv1alpha1.PowerCappingConfigSpec{
+				PowerCappingSpec: powercappingv1alpha1.PowerCappingSpec{
+					Kind:                        powercappingv1alpha1.AbsolutePowerCapInWatts,
+					Scope:                       powercappingv1alpha1.PowerCappingScopeAggregate,
+					AbsolutePowerCapInWattsSpec: powercappingv1alpha1.AbsolutePowerCapInWattsSpec{PowerCapInWatts: 520},
+				},
+				Forecast: forecast,
+			})
+		}
+
+		It("should predict the summed power at the end of the horizon", func() {
+			for _, method := range []string{powercappingv1alpha1.ForecastMethodLinearTrend, powercappingv1alpha1.ForecastMethodHoltWinters} {
+				config := newConfig(&powercappingv1alpha1.PowerForecastSpec{Method: method, HorizonInSeconds: 300})
+				predicted, err := newReconciler().forecastPower(ctx, config, pods)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(predicted.power).To(BeNumerically("~", 550, 1e-6), method)
+				Expect(predicted.growth).To(BeNumerically("~", 1.1, 1e-6), method)
+			}
+		})
+
+		It("should fail without enough history", func() {
+			reconciler := newReconciler()
+			reconciler.PrometheusClient.(*fakePrometheus).history = []float64{500}
+			_, err := reconciler.forecastPower(ctx, newConfig(&powercappingv1alpha1.PowerForecastSpec{}), pods)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should alert the aggregate cap ahead of the power only when proactive", func() {
+			key := types.NamespacedName{Name: "config", Namespace: "default"}
+			for _, proactive := range []bool{false, true} {
+				config := newConfig(&powercappingv1alpha1.PowerForecastSpec{HorizonInSeconds: 300, Proactive: proactive})
+				reconciler := newReconciler()
+				predicted, err := reconciler.forecastPower(ctx, config, pods)
+				Expect(err).NotTo(HaveOccurred())
+
+				aggregate, failed := reconciler.enforceAggregatePowerCap(ctx, config, pods, defaultSampleWindow, 0, proactiveForecast(config, predicted))
+				Expect(failed).To(BeZero())
+				Expect(aggregate.exceeded()).To(BeFalse())
+				Expect(aggregate.total.overCap()).To(Equal(proactive))
+				// The alert was already sent for this cap when proactive.
+				Expect(reconciler.shouldAlertAggregate(key, 520, 0)).To(Equal(!proactive))
+			}
+		})
+
+		It("should report the forecast in the status", func() {
+			config := newConfig(&powercappingv1alpha1.PowerForecastSpec{HorizonInSeconds: 300})
+			config.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "llm"}}
+			objects := []client.Object{config}
+			for i := range pods {
+				pod := newRunningPod(pods[i].Name, map[string]string{"app": "llm"})
+				pod.UID = pods[i].UID
+				objects = append(objects, pod)
+			}
+			reconciler := newReconciler(objects...)
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(config)})
+			Expect(err).NotTo(HaveOccurred())
+
+			updated := &powercappingv1alpha1.PowerCappingConfig{}
+			Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(config), updated)).To(Succeed())
+			Expect(updated.Status.CurrentPowerConsumption).To(Equal(500))
+			Expect(updated.Status.ForecastPowerConsumption).To(Equal(550))
+			condition := meta.FindStatusCondition(updated.Status.Conditions, powercappingv1alpha1.ConditionPowerCapForecastExceeded)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, powercappingv1alpha1.ConditionCapped)).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,281 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/gomega"
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+	service "github.com/Climatik-Project/Climatik-Project/internal/alert"
+	"github.com/Climatik-Project/Climatik-Project/internal/carbon"
+	"github.com/Climatik-Project/Climatik-Project/internal/metrics"
+	"github.com/Climatik-Project/Climatik-Project/internal/planner"
+)
+
+// fakePrometheus answers power queries by their aggregation: peak power for
+// max_over_time, average power for avg_over_time and current power otherwise.
+// Range queries are answered with history, one value per step up to the end
+// of the range.
+type fakePrometheus struct {
+	prom_v1.API
+	current, peak, average float64
+	history                []float64
+}
+
+func (f *fakePrometheus) QueryRange(ctx context.Context, query string, r prom_v1.Range, opts ...prom_v1.Option) (model.Value, prom_v1.Warnings, error) {
+	if len(f.history) == 0 {
+		return model.Matrix{}, nil, nil
+	}
+	stream := &model.SampleStream{}
+	for i, value := range f.history {
+		ts := r.End.Add(-time.Duration(len(f.history)-1-i) * r.Step)
+		stream.Values = append(stream.Values, model.SamplePair{Timestamp: model.TimeFromUnixNano(ts.UnixNano()), Value: model.SampleValue(value)})
+	}
+	return model.Matrix{stream}, nil, nil
+}
+
+func (f *fakePrometheus) Query(ctx context.Context, query string, ts time.Time, opts ...prom_v1.Option) (model.Value, prom_v1.Warnings, error) {
+	value := f.current
+	switch {
+	case strings.HasPrefix(query, "max_over_time"):
+		value = f.peak
+	case strings.HasPrefix(query, "avg_over_time"):
+		value = f.average
+	}
+	return model.Vector{&model.Sample{Value: model.SampleValue(value)}}, nil, nil
+}
+
+// fakeCarbonIntensity answers value for any zone and records the last zone asked.
+type fakeCarbonIntensity struct {
+	value float64
+	zone  string
+}
+
+func (f *fakeCarbonIntensity) CarbonIntensity(ctx context.Context, zone string) (*carbon.Intensity, error) {
+	f.zone = zone
+	return &carbon.Intensity{Zone: zone, Value: f.value, Time: time.Now()}, nil
+}
+
+// fakeMetricsSource answers pod power with reading and records the pods asked.
+type fakeMetricsSource struct {
+	metrics.PowerMetricsSource
+	reading metrics.Reading
+	pods    []types.NamespacedName
+}
+
+func (f *fakeMetricsSource) PodPower(ctx context.Context, pods []types.NamespacedName, window time.Duration) (*metrics.Reading, error) {
+	f.pods = pods
+	reading := f.reading
+	return &reading, nil
+}
+
+// fakeAlertManager records the pods whose alerts are resolved, and fails to
+// resolve them with err.
+type fakeAlertManager struct {
+	resolved []string
+	err      error
+}
+
+func (f *fakeAlertManager) CreateAlert(podName string, powerCapValue int, devices map[string]string, config *powercappingv1alpha1.PowerCappingConfig) error {
+	return nil
+}
+
+func (f *fakeAlertManager) ResolveAlert(ctx context.Context, podName string, config *powercappingv1alpha1.PowerCappingConfig) error {
+	f.resolved = append(f.resolved, podName)
+	return f.err
+}
+
+// fakePlanner answers CalculateOptimalReplicas with fixed replicas per
+// deployment, or with err. A zero delay answers at once; otherwise the call
+// waits for the delay or the deadline of the request. WatchRecommendations
+// streams the plans sent to recommendations, and is unimplemented when it is
+// nil. The watch requests are sent to watches when it is set.
+type fakePlanner struct {
+	replicas        map[string]int32
+	delay           time.Duration
+	err             error
+	request         *planner.CalculateOptimalReplicasRequest
+	recommendations chan *planner.CalculateOptimalReplicasResponse
+	watches         chan *planner.WatchRecommendationsRequest
+}
+
+// fakeRecommendations receives the plans of a fakePlanner until the watch is cancelled.
+type fakeRecommendations struct {
+	grpc.ClientStream
+	ctx             context.Context
+	recommendations chan *planner.CalculateOptimalReplicasResponse
+}
+
+func (f *fakeRecommendations) Recv() (*planner.CalculateOptimalReplicasResponse, error) {
+	select {
+	case response := <-f.recommendations:
+		return response, nil
+	case <-f.ctx.Done():
+		return nil, f.ctx.Err()
+	}
+}
+
+func (f *fakePlanner) WatchRecommendations(ctx context.Context, in *planner.WatchRecommendationsRequest, opts ...grpc.CallOption) (planner.Planner_WatchRecommendationsClient, error) {
+	if f.recommendations == nil {
+		return nil, grpcstatus.Error(codes.Unimplemented, "no recommendations")
+	}
+	if f.watches != nil {
+		f.watches <- in
+	}
+	return &fakeRecommendations{ctx: ctx, recommendations: f.recommendations}, nil
+}
+
+func (f *fakePlanner) CalculateOptimalReplicas(ctx context.Context, in *planner.CalculateOptimalReplicasRequest, opts ...grpc.CallOption) (*planner.CalculateOptimalReplicasResponse, error) {
+	f.request = in
+	if f.delay > 0 {
+		select {
+		case <-time.After(f.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	response := &planner.CalculateOptimalReplicasResponse{}
+	for _, deployment := range in.Deployments {
+		if replicas, ok := f.replicas[deployment.Name]; ok {
+			response.DeploymentReplicas = append(response.DeploymentReplicas, &planner.DeploymentReplicas{
+				Name:            deployment.Name,
+				Namespace:       deployment.Namespace,
+				OptimalReplicas: replicas,
+			})
+		}
+	}
+	return response, nil
+}
+
+// fakePodPower answers power queries with the sum of the power of the pods
+// named in the query.
+type fakePodPower struct {
+	prom_v1.API
+	power map[string]float64
+}
+
+func (f *fakePodPower) Query(ctx context.Context, query string, ts time.Time, opts ...prom_v1.Option) (model.Value, prom_v1.Warnings, error) {
+	var value float64
+	for name, power := range f.power {
+		if strings.Contains(query, name) {
+			value += power
+		}
+	}
+	return model.Vector{&model.Sample{Value: model.SampleValue(value)}}, nil, nil
+}
+
+// scaledObjectGVK is the kind of the KEDA ScaledObjects bounded by the specs.
+var scaledObjectGVK = schema.GroupVersionKind{Group: "keda.sh", Version: "v1alpha1", Kind: "ScaledObject"}
+
+// newTestScheme returns a scheme of the Kubernetes types, the types of the
+// operator and KEDA ScaledObjects.
+func newTestScheme() *runtime.Scheme {
+	testScheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(testScheme)).To(Succeed())
+	Expect(powercappingv1alpha1.AddToScheme(testScheme)).To(Succeed())
+	testScheme.AddKnownTypeWithName(scaledObjectGVK, &unstructured.Unstructured{})
+	return testScheme
+}
+
+// newFakeClient returns a fake client holding objs, with the status of the
+// configs as a subresource and the configs indexed by target namespace.
+func newFakeClient(objs ...client.Object) client.Client {
+	return fake.NewClientBuilder().
+		WithScheme(newTestScheme()).
+		WithObjects(objs...).
+		WithStatusSubresource(&powercappingv1alpha1.PowerCappingConfig{}).
+		WithIndex(&powercappingv1alpha1.PowerCappingConfig{}, targetNamespaceIndex, indexTargetNamespace).
+		Build()
+}
+
+// newAlertService returns an alert service publishing to alertManagers.
+func newAlertService(alertManagers ...service.AlertManager) *service.AlertService {
+	pubsub := service.NewPubSub()
+	for _, alertManager := range alertManagers {
+		pubsub.Subscribe("alerts", alertManager)
+	}
+	return &service.AlertService{Pubsub: pubsub}
+}
+
+// newTestReconciler returns a reconciler of a fake client holding objs, which
+// reads 250W of current, 400W of peak and 200W of average power for any pod.
+func newTestReconciler(objs ...client.Object) *PowerCappingConfigReconciler {
+	return &PowerCappingConfigReconciler{
+		Client:           newFakeClient(objs...),
+		PrometheusClient: &fakePrometheus{current: 250, peak: 400, average: 200},
+		AlertService:     newAlertService(),
+	}
+}
+
+// newTestConfig returns the config named config in the default namespace.
+func newTestConfig(spec powercappingv1alpha1.PowerCappingConfigSpec) *powercappingv1alpha1.PowerCappingConfig {
+	return &powercappingv1alpha1.PowerCappingConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default"},
+		Spec:       spec,
+	}
+}
+
+// newTestPod returns a pod of the default namespace whose UID is derived
+// from its name.
+func newTestPod(name string) corev1.Pod {
+	return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID("uid-" + name)}}
+}
+
+// newRunningPod returns a running pod of the default namespace with labels.
+func newRunningPod(name string, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+// newScaledObject returns a ScaledObject of the default namespace scaling
+// target between 1 and 10 replicas.
+func newScaledObject(name, target string) *unstructured.Unstructured {
+	scaledObject := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"scaleTargetRef":  map[string]interface{}{"name": target},
+			"minReplicaCount": int64(1),
+			"maxReplicaCount": int64(10),
+		},
+	}}
+	scaledObject.SetGroupVersionKind(scaledObjectGVK)
+	scaledObject.SetNamespace("default")
+	scaledObject.SetName(name)
+	return scaledObject
+}
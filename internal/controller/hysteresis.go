@@ -0,0 +1,85 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	"context"
+	"math"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+)
+
+// getHysteresis returns the hysteresis band of the config as a fraction of
+// the power cap, falling back to v1alpha1.DefaultHysteresisPercentage.
+func getHysteresis(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) float64 {
+	percentage := powerCappingConfig.Spec.PowerCappingSpec.HysteresisPercentage
+	if percentage <= 0 {
+		percentage = v1alpha1.DefaultHysteresisPercentage
+	}
+	return float64(percentage) / 100
+}
+
+// underCap reports whether the power, and the forecast acted on, are back
+// under the power cap by the hysteresis band. Between the cap and the band an
+// alert raised for exceeding the cap is kept rather than resolved.
+func (e *podEvaluation) underCap(hysteresis float64) bool {
+	return max(e.currentPower, e.forecastPower) <= e.powerCap*(1-hysteresis)
+}
+
+// movedBeyond reports whether powerCap moved away from the previous cap by
+// more than the hysteresis band of the previous cap.
+func movedBeyond(previous, powerCap, hysteresis float64) bool {
+	return math.Abs(powerCap-previous) > previous*hysteresis
+}
+
+// followedPowerCap returns the power cap of a pod computed from its peak and
+// average power, for the kinds whose cap follows the power of the pod, or nil
+// for the others.
+func (r *PowerCappingConfigReconciler) followedPowerCap(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) func(peak, average float64) float64 {
+	percentage := r.getPowerCapPercentage(powerCappingConfig)
+	var powerCap func(peak, average float64) float64
+	switch powerCappingConfig.Spec.PowerCappingSpec.Kind {
+	case v1alpha1.RelativePowerCapOfPeakPowerConsumptionInPercentage:
+		powerCap = func(peak, _ float64) float64 { return r.calculatePowerCap(peak, percentage) }
+	case v1alpha1.RelativePowerCappingOfAveragePowerConsumptionInPercentage:
+		powerCap = func(_, average float64) float64 { return r.calculatePowerCap(average, percentage) }
+	default:
+		return nil
+	}
+	return func(peak, average float64) float64 {
+		scheduleWindow, _ := r.scheduleWindow(powerCappingConfig)
+		return scheduledPowerCap(scheduleWindow, powerCap(peak, average))
+	}
+}
+
+// resolveAlert resolves the alert of a pod that is back under its power cap
+// or whose node is back under the temperature threshold. The alert is kept
+// while the pod is still alerted for the other one.
+func (r *PowerCappingConfigReconciler) resolveAlert(ctx context.Context, pod *corev1.Pod, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) {
+	if r.AlertService == nil || r.stillAlerted(client.ObjectKeyFromObject(powerCappingConfig), pod) {
+		return
+	}
+	log.Info("Resolving alert", "pod", pod.Name, "namespace", pod.Namespace)
+	if err := r.AlertService.ResolveAlert(ctx, pod.Name, powerCappingConfig); err != nil {
+		log.Error(err, "Failed to resolve alert", "pod", pod.Name)
+		return
+	}
+	r.recordAlert(powerCappingConfig, pod.Name, false)
+}
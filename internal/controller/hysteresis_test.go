@@ -0,0 +1,106 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+)
+
+var _ = Describe("PowerCappingConfig Controller", func() {
+	Context("When monitoring power over time", func() {
+		ctx := context.Background()
+		pod := newTestPod("pod")
+		key := types.NamespacedName{Name: "config", Namespace: "default"}
+
+		newReconciler := func(alerts *fakeAlertManager) *PowerCappingConfigReconciler {
+			reconciler := newTestReconciler()
+			reconciler.AlertService = newAlertService(alerts)
+			return reconciler
+		}
+		newConfig := func(spec powercappingv1alpha1.PowerCappingSpec) *powercappingv1alpha1.PowerCappingConfig {
+			return newTestConfig(powercappingv1alpha1.PowerCappingConfigSpec{PowerCappingSpec: spec})
+		}
+		// alertedCap returns the cap last alerted for the pod, 0 when the
+		// pod is not alerted.
+		alertedCap := func(reconciler *PowerCappingConfigReconciler) int {
+			reconciler.mu.Lock()
+			defer reconciler.mu.Unlock()
+			return reconciler.alertedCaps[key][pod.UID]
+		}
+
+		It("should resolve the alert only once the pod is back under the hysteresis band", func() {
+			alerts := &fakeAlertManager{}
+			reconciler := newReconciler(alerts)
+			config := newConfig(powercappingv1alpha1.PowerCappingSpec{
+				Kind:                        powercappingv1alpha1.AbsolutePowerCapInWatts,
+				AbsolutePowerCapInWattsSpec: powercappingv1alpha1.AbsolutePowerCapInWattsSpec{PowerCapInWatts: 200},
+				HysteresisPercentage:        10,
+			})
+			prometheus := reconciler.PrometheusClient.(*fakePrometheus)
+
+			_, err := reconciler.enforcePowerCap(ctx, config, &pod, defaultSampleWindow, 0, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(alertedCap(reconciler)).To(Equal(200))
+
+			// Under the cap but within 10% of it, the alert is kept.
+			for _, current := range []float64{195, 185} {
+				prometheus.current = current
+				_, err = reconciler.enforcePowerCap(ctx, config, &pod, defaultSampleWindow, 0, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(alertedCap(reconciler)).To(Equal(200))
+				Expect(alerts.resolved).To(BeEmpty())
+			}
+
+			for i := 0; i < 2; i++ {
+				prometheus.current = 175
+				_, err = reconciler.enforcePowerCap(ctx, config, &pod, defaultSampleWindow, 0, nil)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(alertedCap(reconciler)).To(BeZero())
+			Expect(alerts.resolved).To(Equal([]string{"pod"}))
+		})
+
+		It("should alert a cap following the power only once it moves beyond the band", func() {
+			reconciler := newReconciler(&fakeAlertManager{})
+			config := newConfig(powercappingv1alpha1.PowerCappingSpec{
+				Kind:                             powercappingv1alpha1.RelativePowerCapOfPeakPowerConsumptionInPercentage,
+				RelativePowerCapInPercentageSpec: powercappingv1alpha1.RelativePowerCapInPercentageSpec{PowerCapPercentage: 50},
+			})
+			prometheus := reconciler.PrometheusClient.(*fakePrometheus)
+
+			for _, step := range []struct{ peak, alerted float64 }{
+				{peak: 400, alerted: 200},
+				// A 205W cap is within the default 5% of the alerted one.
+				{peak: 410, alerted: 200},
+				{peak: 440, alerted: 220},
+				{peak: 420, alerted: 220},
+			} {
+				prometheus.peak = step.peak
+				evaluation, err := reconciler.enforcePowerCap(ctx, config, &pod, defaultSampleWindow, 0, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(evaluation.powerCap).To(Equal(step.peak / 2))
+				Expect(alertedCap(reconciler)).To(Equal(int(step.alerted)))
+			}
+		})
+	})
+})
@@ -0,0 +1,125 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+	"github.com/Climatik-Project/Climatik-Project/internal/metrics"
+)
+
+// metricsSources selects the source of the power and temperature metrics of
+// a config among the configured ones.
+type metricsSources struct {
+	// sources are the configured sources by name, and name is the source of
+	// the configs that select none, metrics.Default when empty.
+	sources map[string]metrics.PowerMetricsSource
+	name    string
+	// prometheus backs the Kepler and DCGM sources missing from sources,
+	// which read the series of names, metrics.DefaultNames when nil.
+	prometheus prom_v1.API
+	names      *metrics.SharedNames
+}
+
+// source returns the metrics source selected by the config.
+func (s metricsSources) source(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) (metrics.PowerMetricsSource, error) {
+	name := powerCappingConfig.Spec.MetricsSource
+	if name == "" {
+		name = s.name
+	}
+	if name == "" {
+		name = metrics.Default
+	}
+	if source, ok := s.sources[name]; ok {
+		return source, nil
+	}
+	if s.prometheus != nil {
+		switch name {
+		case metrics.Kepler:
+			source := metrics.NewKeplerSource(s.prometheus)
+			if s.names != nil {
+				source.Names = s.names.Load()
+			}
+			return source, nil
+		case metrics.DCGM:
+			source := metrics.NewDCGMSource(s.prometheus)
+			if s.names != nil {
+				source.Names = s.names.Load()
+			}
+			return source, nil
+		}
+	}
+	return nil, fmt.Errorf("metrics source %q is not configured", name)
+}
+
+// metricsSource returns the metrics source selected by the config.
+func (r *PowerCappingConfigReconciler) metricsSource(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) (metrics.PowerMetricsSource, error) {
+	return metricsSources{sources: r.MetricsSources, name: r.MetricsSource, prometheus: r.PrometheusClient,
+		names: r.MetricNames}.source(powerCappingConfig)
+}
+
+// measurePods reads the power of the pods together over the window.
+func (r *PowerCappingConfigReconciler) measurePods(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, pods []corev1.Pod, window time.Duration) (*metrics.Reading, error) {
+	source, err := r.metricsSource(powerCappingConfig)
+	if err != nil {
+		return nil, err
+	}
+	return source.PodPower(ctx, podKeys(pods), window)
+}
+
+// measureCurrentPower reads the current power of the pods together.
+func (r *PowerCappingConfigReconciler) measureCurrentPower(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, pods ...corev1.Pod) (float64, error) {
+	reading, err := r.measurePods(ctx, powerCappingConfig, pods, 0)
+	if err != nil {
+		return 0, err
+	}
+	return reading.Current, nil
+}
+
+// getPodDevices returns the devices the pod runs on, reported along with
+// alerts, or nil when the metrics source of the config does not know them.
+func (r *PowerCappingConfigReconciler) getPodDevices(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, pod *corev1.Pod) map[string]string {
+	source, err := r.metricsSource(powerCappingConfig)
+	if err != nil {
+		return nil
+	}
+	devices, ok := source.(metrics.PodDeviceSource)
+	if !ok {
+		return nil
+	}
+	labels, err := devices.PodDevices(ctx, client.ObjectKeyFromObject(pod))
+	if err != nil {
+		log.Error(err, "Failed to get pod devices", "pod", pod.Name)
+		return nil
+	}
+	return labels
+}
+
+func podKeys(pods []corev1.Pod) []types.NamespacedName {
+	keys := make([]types.NamespacedName, 0, len(pods))
+	for i := range pods {
+		keys = append(keys, client.ObjectKeyFromObject(&pods[i]))
+	}
+	return keys
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+	"github.com/Climatik-Project/Climatik-Project/internal/metrics"
+)
+
+var _ = Describe("PowerCappingConfig Controller", func() {
+	Context("When selecting a metrics source", func() {
+		ctx := context.Background()
+		pod := newTestPod("pod-a")
+		newConfig := func(source string) *powercappingv1alpha1.PowerCappingConfig {
+			return newTestConfig(powercappingv1alpha1.PowerCappingConfigSpec{MetricsSource: source})
+		}
+
+		It("should read the source selected by the config", func() {
+			file := &fakeMetricsSource{reading: metrics.Reading{Current: 300, Peak: 350, Average: 320}}
+			reconciler := &PowerCappingConfigReconciler{
+				PrometheusClient: &fakePrometheus{current: 100, peak: 150, average: 120},
+				MetricsSources:   map[string]metrics.PowerMetricsSource{metrics.File: file},
+			}
+
+			evaluation, err := reconciler.measurePodPower(ctx, newConfig(powercappingv1alpha1.MetricsSourceFile), &pod, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(evaluation.currentPower).To(Equal(300.0))
+			Expect(evaluation.peakPower).To(Equal(350.0))
+			Expect(file.pods).To(Equal([]types.NamespacedName{{Namespace: "default", Name: "pod-a"}}))
+
+			// Kepler is read from Prometheus by default.
+			evaluation, err = reconciler.measurePodPower(ctx, newConfig(""), &pod, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(evaluation.currentPower).To(Equal(100.0))
+		})
+
+		It("should default to the source of the operator", func() {
+			reconciler := &PowerCappingConfigReconciler{
+				PrometheusClient: &fakePrometheus{current: 100},
+				MetricsSources:   map[string]metrics.PowerMetricsSource{metrics.File: &fakeMetricsSource{reading: metrics.Reading{Current: 300}}},
+				MetricsSource:    metrics.File,
+			}
+			power, err := reconciler.measureCurrentPower(ctx, newConfig(""), pod)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(power).To(Equal(300.0))
+		})
+
+		It("should fail when the selected source is not configured", func() {
+			reconciler := &PowerCappingConfigReconciler{}
+			_, err := reconciler.measureCurrentPower(ctx, newConfig(powercappingv1alpha1.MetricsSourceDCGM), pod)
+			Expect(err).To(MatchError(ContainSubstring(`metrics source "DCGM" is not configured`)))
+		})
+
+		It("should read Prometheus with the configured metric names", func() {
+			names := metrics.DefaultNames()
+			names.Kepler.PodLabel = "pod"
+			names.DCGM.NodeLabel = "node"
+			reconciler := &PowerCappingConfigReconciler{PrometheusClient: &fakePrometheus{}, MetricNames: metrics.NewSharedNames(names)}
+
+			source, err := reconciler.metricsSource(newConfig(""))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(source.(*metrics.KeplerSource).Names).To(Equal(names))
+			source, err = reconciler.metricsSource(newConfig(powercappingv1alpha1.MetricsSourceDCGM))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(source.(*metrics.DCGMSource).Names).To(Equal(names))
+
+			// The names are read again by every source built.
+			reconciler.MetricNames.Store(metrics.DefaultNames())
+			source, err = reconciler.metricsSource(newConfig(""))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(source.(*metrics.KeplerSource).Names).To(Equal(metrics.DefaultNames()))
+		})
+	})
+})
@@ -0,0 +1,240 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+	"github.com/Climatik-Project/Climatik-Project/internal/actuator"
+)
+
+// nodeEvictionCooldown is how long a node is left to settle after pods were
+// evicted from it before more are, since its power is read with a delay and
+// the evicted pods take time to stop.
+const nodeEvictionCooldown = 5 * time.Minute
+
+// enforceNodePowerCap measures the nodes selected by the node power cap of the
+// config and acts on those exceeding it: they are cordoned, tainted, or
+// tainted and lose matched pods to eviction, lowest priority first, at most
+// once every nodeEvictionCooldown. Nodes back under the cap,
+// or no longer selected, are released. It returns the status of the selected
+// nodes and the number of nodes that could not be measured or acted on.
+func (r *PowerCappingConfigReconciler) enforceNodePowerCap(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, pods []corev1.Pod) ([]powercappingv1alpha1.NodePowerStatus, int) {
+	spec := powerCappingConfig.Spec.NodePowerCap
+	nodes := actuator.NewNodeActuator(r.Client, client.ObjectKeyFromObject(powerCappingConfig).String())
+	selected := make(map[string]bool)
+	var statuses []powercappingv1alpha1.NodePowerStatus
+	failed := 0
+
+	if spec != nil {
+		selector, err := metav1.LabelSelectorAsSelector(spec.NodeSelector)
+		nodeList := &corev1.NodeList{}
+		if err == nil {
+			err = r.List(ctx, nodeList, client.MatchingLabelsSelector{Selector: selector})
+		}
+		if err != nil {
+			log.Error(err, "Failed to list nodes for node power cap", "powerCappingConfig", powerCappingConfig.Name)
+			// Keep the nodes acted on until they can be evaluated again.
+			return powerCappingConfig.Status.Nodes, 1
+		}
+		sort.Slice(nodeList.Items, func(i, j int) bool { return nodeList.Items[i].Name < nodeList.Items[j].Name })
+
+		action := spec.Action
+		if action == "" {
+			action = v1alpha1.NodePowerCapActionTaint
+		}
+		for i := range nodeList.Items {
+			node := &nodeList.Items[i]
+			selected[node.Name] = true
+			status := powercappingv1alpha1.NodePowerStatus{Name: node.Name}
+			power, err := r.measureNodePower(ctx, powerCappingConfig, node.Name)
+			if err != nil {
+				log.Error(err, "Failed to query node power", "node", node.Name)
+				status.Message = err.Error()
+				statuses = append(statuses, status)
+				failed++
+				continue
+			}
+			status.PowerConsumption = int(power)
+			if power <= float64(spec.PowerCapInWatts) {
+				if err := nodes.Release(ctx, node); err != nil {
+					log.Error(err, "Failed to release node", "node", node.Name)
+					status.Message = err.Error()
+					failed++
+				}
+				statuses = append(statuses, status)
+				continue
+			}
+
+			log.Info("Node power cap exceeded", "node", node.Name, "power", power, "powerCap", spec.PowerCapInWatts, "action", action)
+			status.Action = action
+			switch action {
+			case v1alpha1.NodePowerCapActionCordon:
+				err = nodes.Cordon(ctx, node)
+			case v1alpha1.NodePowerCapActionTaint:
+				err = nodes.Taint(ctx, node)
+			case v1alpha1.NodePowerCapActionEvict:
+				// The node is tainted first so that the evicted pods are not
+				// scheduled back on it.
+				if err = nodes.Taint(ctx, node); err == nil {
+					status.EvictedPods, err = r.evictNodePods(ctx, powerCappingConfig, node.Name, pods, power-float64(spec.PowerCapInWatts))
+				}
+			}
+			if err != nil {
+				log.Error(err, "Failed to relieve node", "node", node.Name, "action", action)
+				status.Message = err.Error()
+				failed++
+			}
+			statuses = append(statuses, status)
+		}
+	}
+
+	for _, previous := range powerCappingConfig.Status.Nodes {
+		if selected[previous.Name] {
+			continue
+		}
+		if err := r.releaseNode(ctx, nodes, previous.Name); err != nil {
+			log.Error(err, "Failed to release node", "node", previous.Name)
+			// Keep reporting the node so that the release is retried.
+			statuses = append(statuses, powercappingv1alpha1.NodePowerStatus{Name: previous.Name, Message: err.Error()})
+			failed++
+		}
+	}
+	return statuses, failed
+}
+
+// releaseNode reverts the actions taken on the named node, if it still exists.
+func (r *PowerCappingConfigReconciler) releaseNode(ctx context.Context, nodes *actuator.NodeActuator, name string) error {
+	node := &corev1.Node{}
+	if err := r.Get(ctx, client.ObjectKey{Name: name}, node); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return nodes.Release(ctx, node)
+}
+
+// measureNodePower reads the current power of a node.
+func (r *PowerCappingConfigReconciler) measureNodePower(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, node string) (float64, error) {
+	source, err := r.metricsSource(powerCappingConfig)
+	if err != nil {
+		return 0, err
+	}
+	reading, err := source.NodePower(ctx, node, 0)
+	if err != nil {
+		return 0, err
+	}
+	return reading.Current, nil
+}
+
+// evictNodePods evicts the matched pods of the node, lowest priority and then
+// youngest first, until their power covers excess. Nothing is evicted within
+// nodeEvictionCooldown of the last evictions from the node. It returns the
+// number of pods evicted.
+func (r *PowerCappingConfigReconciler) evictNodePods(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, node string, pods []corev1.Pod, excess float64) (int32, error) {
+	if !r.mayEvict(node) {
+		log.Info("Waiting for the last evictions to settle", "node", node, "cooldown", nodeEvictionCooldown)
+		return 0, nil
+	}
+	var candidates []*corev1.Pod
+	for i := range pods {
+		if pods[i].Spec.NodeName == node {
+			candidates = append(candidates, &pods[i])
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if pi, pj := podPriority(candidates[i]), podPriority(candidates[j]); pi != pj {
+			return pi < pj
+		}
+		return candidates[j].CreationTimestamp.Before(&candidates[i].CreationTimestamp)
+	})
+
+	var evicted int32
+	for _, pod := range candidates {
+		if excess <= 0 {
+			return evicted, nil
+		}
+		power, err := r.measureCurrentPower(ctx, powerCappingConfig, *pod)
+		if err != nil {
+			return evicted, err
+		}
+		if err := actuator.Evict(ctx, r.Client, pod); err != nil {
+			return evicted, err
+		}
+		log.Info("Evicted pod to relieve node", "pod", pod.Name, "namespace", pod.Namespace, "node", node, "power", power)
+		r.recordEviction(node)
+		evicted++
+		excess -= power
+	}
+	if excess > 0 {
+		return evicted, fmt.Errorf("no matched pod left to evict from node %s", node)
+	}
+	return evicted, nil
+}
+
+// mayEvict reports whether pods may be evicted from the node, i.e. no pod was
+// evicted from it within nodeEvictionCooldown.
+func (r *PowerCappingConfigReconciler) mayEvict(node string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	last, ok := r.evictedNodes[node]
+	return !ok || r.now().Sub(last) >= nodeEvictionCooldown
+}
+
+// recordEviction records that a pod was just evicted from the node.
+func (r *PowerCappingConfigReconciler) recordEviction(node string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.evictedNodes == nil {
+		r.evictedNodes = make(map[string]time.Time)
+	}
+	r.evictedNodes[node] = r.now()
+}
+
+// podPriority returns the scheduling priority of the pod, 0 when it has none.
+func podPriority(pod *corev1.Pod) int32 {
+	if pod.Spec.Priority == nil {
+		return 0
+	}
+	return *pod.Spec.Priority
+}
+
+// nodePowerCapExceeded returns the nodes of the statuses consuming more than
+// the node power cap of the config.
+func nodePowerCapExceeded(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, statuses []powercappingv1alpha1.NodePowerStatus) []string {
+	spec := powerCappingConfig.Spec.NodePowerCap
+	if spec == nil {
+		return nil
+	}
+	var exceeded []string
+	for _, status := range statuses {
+		if status.PowerConsumption > spec.PowerCapInWatts {
+			exceeded = append(exceeded, fmt.Sprintf("%s %dW > %dW", status.Name, status.PowerConsumption, spec.PowerCapInWatts))
+		}
+	}
+	return exceeded
+}
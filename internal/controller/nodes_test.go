@@ -0,0 +1,157 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+	"github.com/Climatik-Project/Climatik-Project/internal/actuator"
+)
+
+var _ = Describe("PowerCappingConfig Controller", func() {
+	Context("When capping the power of nodes", func() {
+		ctx := context.Background()
+
+		newNode := func(name string, annotations map[string]string, taints ...corev1.Taint) *corev1.Node {
+			return &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"rack": "a"}, Annotations: annotations},
+				Spec:       corev1.NodeSpec{Taints: taints},
+			}
+		}
+		newPod := func(name string, priority int32, created time.Time) corev1.Pod {
+			pod := *newRunningPod(name, nil)
+			pod.CreationTimestamp = metav1.NewTime(created)
+			pod.Spec = corev1.PodSpec{NodeName: "node-hot", Priority: &priority}
+			return pod
+		}
+		newReconciler := func(power map[string]float64, objs ...client.Object) *PowerCappingConfigReconciler {
+			reconciler := newTestReconciler(objs...)
+			reconciler.PrometheusClient = &fakePodPower{power: power}
+			return reconciler
+		}
+		newConfig := func(action powercappingv1alpha1.NodePowerCapAction) *powercappingv1alpha1.PowerCappingConfig {
+			return newTestConfig(powercappingv1alpha1.PowerCappingConfigSpec{
+				NodePowerCap: &powercappingv1alpha1.NodePowerCapSpec{
+					NodeSelector:    &metav1.LabelSelector{MatchLabels: map[string]string{"rack": "a"}},
+					PowerCapInWatts: 1000,
+					Action:          action,
+				},
+			})
+		}
+		powerPressure := corev1.Taint{Key: actuator.PowerPressureTaintKey, Effect: corev1.TaintEffectNoSchedule}
+
+		It("should taint nodes over the cap and release the nodes it tainted once under it", func() {
+			reconciler := newReconciler(map[string]float64{"node-hot": 1200, "node-cool": 800, "node-other": 700},
+				newNode("node-hot", nil),
+				newNode("node-cool", map[string]string{actuator.TaintedByAnnotation: "default/config"}, powerPressure),
+				newNode("node-other", map[string]string{actuator.TaintedByAnnotation: "default/other"}, powerPressure),
+			)
+			config := newConfig("")
+
+			statuses, failed := reconciler.enforceNodePowerCap(ctx, config, nil)
+			Expect(failed).To(BeZero())
+			Expect(statuses).To(Equal([]powercappingv1alpha1.NodePowerStatus{
+				{Name: "node-cool", PowerConsumption: 800},
+				{Name: "node-hot", PowerConsumption: 1200, Action: powercappingv1alpha1.NodePowerCapActionTaint},
+				{Name: "node-other", PowerConsumption: 700},
+			}))
+			Expect(nodePowerCapExceeded(config, statuses)).To(Equal([]string{"node-hot 1200W > 1000W"}))
+
+			node := &corev1.Node{}
+			Expect(reconciler.Get(ctx, client.ObjectKey{Name: "node-hot"}, node)).To(Succeed())
+			Expect(node.Spec.Taints).To(ConsistOf(powerPressure))
+			Expect(node.Annotations).To(HaveKeyWithValue(actuator.TaintedByAnnotation, "default/config"))
+			Expect(reconciler.Get(ctx, client.ObjectKey{Name: "node-cool"}, node)).To(Succeed())
+			Expect(node.Spec.Taints).To(BeEmpty())
+			Expect(node.Annotations).NotTo(HaveKey(actuator.TaintedByAnnotation))
+			Expect(reconciler.Get(ctx, client.ObjectKey{Name: "node-other"}, node)).To(Succeed())
+			Expect(node.Spec.Taints).To(ConsistOf(powerPressure))
+		})
+
+		It("should uncordon the nodes it cordoned once they are no longer selected", func() {
+			reconciler := newReconciler(map[string]float64{"node-hot": 1200},
+				newNode("node-hot", nil),
+			)
+			config := newConfig(powercappingv1alpha1.NodePowerCapActionCordon)
+
+			statuses, failed := reconciler.enforceNodePowerCap(ctx, config, nil)
+			Expect(failed).To(BeZero())
+			Expect(statuses).To(HaveLen(1))
+			node := &corev1.Node{}
+			Expect(reconciler.Get(ctx, client.ObjectKey{Name: "node-hot"}, node)).To(Succeed())
+			Expect(node.Spec.Unschedulable).To(BeTrue())
+
+			config.Status.Nodes = statuses
+			config.Spec.NodePowerCap = nil
+			statuses, failed = reconciler.enforceNodePowerCap(ctx, config, nil)
+			Expect(failed).To(BeZero())
+			Expect(statuses).To(BeEmpty())
+			Expect(reconciler.Get(ctx, client.ObjectKey{Name: "node-hot"}, node)).To(Succeed())
+			Expect(node.Spec.Unschedulable).To(BeFalse())
+			Expect(node.Annotations).NotTo(HaveKey(actuator.CordonedByAnnotation))
+		})
+
+		It("should evict the lowest priority pods until the node fits in its cap", func() {
+			now := time.Now()
+			pods := []corev1.Pod{
+				newPod("pod-critical", 1000, now.Add(-time.Hour)),
+				newPod("pod-old", 0, now.Add(-time.Hour)),
+				newPod("pod-young", 0, now),
+			}
+			objs := []client.Object{newNode("node-hot", nil)}
+			for i := range pods {
+				objs = append(objs, pods[i].DeepCopy())
+			}
+			reconciler := newReconciler(map[string]float64{"node-hot": 1400, "pod-critical": 600, "pod-old": 250, "pod-young": 250}, objs...)
+			reconciler.clock = func() time.Time { return now }
+			config := newConfig(powercappingv1alpha1.NodePowerCapActionEvict)
+
+			statuses, failed := reconciler.enforceNodePowerCap(ctx, config, pods)
+			Expect(failed).To(BeZero())
+			Expect(statuses).To(Equal([]powercappingv1alpha1.NodePowerStatus{
+				{Name: "node-hot", PowerConsumption: 1400, Action: powercappingv1alpha1.NodePowerCapActionEvict, EvictedPods: 2},
+			}))
+			Expect(reconciler.Get(ctx, client.ObjectKey{Namespace: "default", Name: "pod-critical"}, &corev1.Pod{})).To(Succeed())
+			Expect(errors.IsNotFound(reconciler.Get(ctx, client.ObjectKey{Namespace: "default", Name: "pod-old"}, &corev1.Pod{}))).To(BeTrue())
+			Expect(errors.IsNotFound(reconciler.Get(ctx, client.ObjectKey{Namespace: "default", Name: "pod-young"}, &corev1.Pod{}))).To(BeTrue())
+
+			// The node is tainted so that the evicted pods are not scheduled back on it.
+			node := &corev1.Node{}
+			Expect(reconciler.Get(ctx, client.ObjectKey{Name: "node-hot"}, node)).To(Succeed())
+			Expect(node.Spec.Taints).To(ConsistOf(powerPressure))
+			Expect(node.Annotations).To(HaveKeyWithValue(actuator.TaintedByAnnotation, "default/config"))
+
+			// No more pods are evicted until the node settled.
+			statuses, failed = reconciler.enforceNodePowerCap(ctx, config, pods[:1])
+			Expect(failed).To(BeZero())
+			Expect(statuses[0].EvictedPods).To(BeZero())
+			reconciler.clock = func() time.Time { return now.Add(nodeEvictionCooldown) }
+			statuses, failed = reconciler.enforceNodePowerCap(ctx, config, pods[:1])
+			Expect(failed).To(BeZero())
+			Expect(statuses[0].EvictedPods).To(Equal(int32(1)))
+		})
+	})
+})
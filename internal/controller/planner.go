@@ -0,0 +1,204 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/proto"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+	"github.com/Climatik-Project/Climatik-Project/internal/planner"
+)
+
+// plannerSubscription is the watch of the recommendations of the Planner
+// service for a config. deployments identifies the deployments planned, with
+// their bounds and priorities, and request holds the inputs watched. plan
+// holds the latest replicas received for the deployments, nil until the first
+// plan arrives.
+type plannerSubscription struct {
+	deployments []*planner.Deployment
+	request     *planner.CalculateOptimalReplicasRequest
+	cancel      context.CancelFunc
+	plan        map[types.NamespacedName]int32
+}
+
+// planReplicas asks the Planner service for the replicas of the workloads of
+// the measured scale targets under the power budget. The replicas come from
+// the recommendations watched for the config once they have arrived, and from
+// a single call until then. It returns nil when no planner is configured or
+// the call fails or times out, in which case the replicas are computed
+// locally.
+func (r *PowerCappingConfigReconciler) planReplicas(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, targets []*scaleTarget, budget float64) map[types.NamespacedName]int32 {
+	if r.Planner == nil {
+		return nil
+	}
+	key := client.ObjectKeyFromObject(powerCappingConfig)
+	request := &planner.CalculateOptimalReplicasRequest{PowerCap: budget}
+	if r.CarbonIntensityProvider != nil {
+		if intensity, err := r.carbonIntensity(ctx, powerCappingConfig); err == nil {
+			request.CarbonIntensity = intensity.Value
+		}
+	}
+	for _, target := range targets {
+		if target.workload != nil {
+			request.Deployments = append(request.Deployments, &planner.Deployment{
+				Name:            target.workload.Name,
+				Namespace:       target.workload.Namespace,
+				MinReplicas:     target.workload.MinReplicas,
+				MaxReplicas:     target.workload.MaxReplicas,
+				Priority:        target.rank.Priority,
+				WattsPerReplica: target.powerPerReplica,
+				Pods:            target.pods,
+			})
+		}
+	}
+	if len(request.Deployments) == 0 {
+		r.forgetRecommendations(key)
+		return nil
+	}
+	if planned := r.watchRecommendations(key, request); planned != nil {
+		return planned
+	}
+
+	timeout := r.PlannerTimeout
+	if timeout <= 0 {
+		timeout = planner.DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	response, err := r.Planner.CalculateOptimalReplicas(ctx, request)
+	if err != nil {
+		log.Error(err, "Planner unavailable, computing replicas locally", "powerCappingConfig", powerCappingConfig.Name)
+		return nil
+	}
+
+	planned := plannedReplicas(response)
+	log.Info("Replicas planned", "powerCappingConfig", powerCappingConfig.Name, "deployments", len(planned), "powerCap", budget,
+		"predictedPower", response.PredictedTotalPower, "reason", response.Reason)
+	return planned
+}
+
+// plannedReplicas returns the replicas of a plan keyed by deployment.
+func plannedReplicas(response *planner.CalculateOptimalReplicasResponse) map[types.NamespacedName]int32 {
+	planned := make(map[types.NamespacedName]int32, len(response.DeploymentReplicas))
+	for _, replicas := range response.DeploymentReplicas {
+		planned[types.NamespacedName{Namespace: replicas.Namespace, Name: replicas.Name}] = replicas.OptimalReplicas
+	}
+	return planned
+}
+
+// watchRecommendations subscribes the config to the recommendations of the
+// Planner service for request and returns the latest plan received, if any.
+// The subscription is kept across reconciles; the power observed per replica
+// changes at every reconcile and is left out so that the planner follows it
+// on its own. When the power cap, the carbon intensity or the pods change,
+// the subscription is renewed with them and keeps its last plan until the new
+// stream answers. Only a change of the deployments, their bounds or
+// priorities discards the plan. Every new plan triggers a reconcile of the
+// config.
+func (r *PowerCappingConfigReconciler) watchRecommendations(key types.NamespacedName, request *planner.CalculateOptimalReplicasRequest) map[types.NamespacedName]int32 {
+	request = proto.Clone(request).(*planner.CalculateOptimalReplicasRequest)
+	for _, deployment := range request.Deployments {
+		deployment.WattsPerReplica = 0
+	}
+	deployments := plannedDeployments(request)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var plan map[types.NamespacedName]int32
+	if subscription, ok := r.subscriptions[key]; ok {
+		if proto.Equal(subscription.request, request) {
+			return subscription.plan
+		}
+		if sameDeployments(subscription.deployments, deployments) {
+			plan = subscription.plan
+		}
+		subscription.cancel()
+	}
+	if r.subscriptions == nil {
+		r.subscriptions = make(map[types.NamespacedName]*plannerSubscription)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	subscription := &plannerSubscription{deployments: deployments, request: request, cancel: cancel, plan: plan}
+	r.subscriptions[key] = subscription
+
+	backoff := r.PlannerBackoff
+	if backoff.Initial <= 0 {
+		backoff = planner.DefaultBackoff
+	}
+	watch := &planner.WatchRecommendationsRequest{Subscriber: key.String(), Request: request}
+	go planner.WatchRecommendations(ctx, r.Planner, watch, backoff, func(response *planner.CalculateOptimalReplicasResponse) {
+		log.Info("Replicas recommended", "powerCappingConfig", key, "deployments", len(response.DeploymentReplicas),
+			"predictedPower", response.PredictedTotalPower, "reason", response.Reason)
+		r.mu.Lock()
+		subscription.plan = plannedReplicas(response)
+		r.mu.Unlock()
+		if r.recommendations == nil {
+			return
+		}
+		config := &powercappingv1alpha1.PowerCappingConfig{}
+		config.SetNamespace(key.Namespace)
+		config.SetName(key.Name)
+		select {
+		case r.recommendations <- event.GenericEvent{Object: config}:
+		case <-ctx.Done():
+		}
+	})
+	return plan
+}
+
+// plannedDeployments returns the deployments of the request with only what
+// identifies their plan: their names, bounds and priorities.
+func plannedDeployments(request *planner.CalculateOptimalReplicasRequest) []*planner.Deployment {
+	deployments := make([]*planner.Deployment, 0, len(request.Deployments))
+	for _, deployment := range request.Deployments {
+		deployments = append(deployments, &planner.Deployment{
+			Name:        deployment.Name,
+			Namespace:   deployment.Namespace,
+			MinReplicas: deployment.MinReplicas,
+			MaxReplicas: deployment.MaxReplicas,
+			Priority:    deployment.Priority,
+		})
+	}
+	return deployments
+}
+
+// sameDeployments reports whether two lists hold the same planned deployments.
+func sameDeployments(a, b []*planner.Deployment) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !proto.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// forgetRecommendations stops watching the recommendations for the config.
+func (r *PowerCappingConfigReconciler) forgetRecommendations(key types.NamespacedName) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if subscription, ok := r.subscriptions[key]; ok {
+		subscription.cancel()
+		delete(r.subscriptions, key)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+	"github.com/Climatik-Project/Climatik-Project/internal/planner"
+)
+
+var _ = Describe("PowerCappingConfig Controller", func() {
+	Context("When bounding scale targets", func() {
+		ctx := context.Background()
+
+		Context("with a planner", func() {
+			podLabels := map[string]string{"app": "llm"}
+			newPlannedReconciler := func(plan *fakePlanner) *PowerCappingConfigReconciler {
+				reconciler := newTestReconciler(
+					newScaledObject("llm", "llm-server"),
+					&appsv1.Deployment{
+						ObjectMeta: metav1.ObjectMeta{Name: "llm-server", Namespace: "default"},
+						Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: podLabels}},
+					},
+					newRunningPod("llm-server-a", podLabels),
+				)
+				reconciler.Planner = plan
+				reconciler.PlannerTimeout = 50 * time.Millisecond
+				return reconciler
+			}
+			config := newTestConfig(powercappingv1alpha1.PowerCappingConfigSpec{
+				ScaledObjectRefs: []powercappingv1alpha1.ScaledObjectReference{
+					{Metadata: powercappingv1alpha1.ScaledObjectMetadata{Name: "llm"}},
+				},
+			})
+
+			It("should apply the replicas planned for the deployment", func() {
+				plan := &fakePlanner{replicas: map[string]int32{"llm-server": 7}}
+				statuses, failed := newPlannedReconciler(plan).enforceScaleTargets(ctx, config, scaleBudget{fixed: 500})
+				Expect(failed).To(BeZero())
+				Expect(statuses[0].MaxReplicas).To(Equal(int32(7)))
+				Expect(plan.request.PowerCap).To(Equal(500.0))
+				Expect(plan.request.Deployments).To(HaveLen(1))
+				Expect(plan.request.Deployments[0].Name).To(Equal("llm-server"))
+				Expect(plan.request.Deployments[0].Namespace).To(Equal("default"))
+				Expect(plan.request.Deployments[0].WattsPerReplica).To(BeNumerically(">", 0))
+				Expect(plan.request.Deployments[0].Pods).To(Equal([]string{"llm-server-a"}))
+			})
+
+			It("should compute the replicas locally when the planner fails", func() {
+				plan := &fakePlanner{err: errors.NewServiceUnavailable("planner down")}
+				statuses, failed := newPlannedReconciler(plan).enforceScaleTargets(ctx, config, scaleBudget{fixed: 500})
+				Expect(failed).To(BeZero())
+				Expect(statuses[0].MaxReplicas).To(Equal(int32(2)))
+			})
+
+			It("should apply the replicas recommended by the planner once received", func() {
+				plan := &fakePlanner{
+					replicas:        map[string]int32{"llm-server": 7},
+					recommendations: make(chan *planner.CalculateOptimalReplicasResponse),
+				}
+				reconciler := newPlannedReconciler(plan)
+				reconciler.recommendations = make(chan event.GenericEvent, 1)
+				defer reconciler.forgetRecommendations(client.ObjectKeyFromObject(config))
+
+				statuses, _ := reconciler.enforceScaleTargets(ctx, config, scaleBudget{fixed: 500})
+				Expect(statuses[0].MaxReplicas).To(Equal(int32(7)))
+
+				plan.recommendations <- &planner.CalculateOptimalReplicasResponse{
+					DeploymentReplicas: []*planner.DeploymentReplicas{{Name: "llm-server", Namespace: "default", OptimalReplicas: 3}},
+				}
+				var recommended event.GenericEvent
+				Eventually(reconciler.recommendations).Should(Receive(&recommended))
+				Expect(recommended.Object.GetName()).To(Equal("config"))
+
+				statuses, _ = reconciler.enforceScaleTargets(ctx, config, scaleBudget{fixed: 500})
+				Expect(statuses[0].MaxReplicas).To(Equal(int32(3)))
+			})
+
+			It("should keep the recommended replicas while following a new power cap", func() {
+				plan := &fakePlanner{
+					replicas:        map[string]int32{"llm-server": 7},
+					recommendations: make(chan *planner.CalculateOptimalReplicasResponse),
+					watches:         make(chan *planner.WatchRecommendationsRequest, 2),
+				}
+				reconciler := newPlannedReconciler(plan)
+				reconciler.recommendations = make(chan event.GenericEvent, 1)
+				defer reconciler.forgetRecommendations(client.ObjectKeyFromObject(config))
+
+				reconciler.enforceScaleTargets(ctx, config, scaleBudget{fixed: 500})
+				var watch *planner.WatchRecommendationsRequest
+				Eventually(plan.watches).Should(Receive(&watch))
+				Expect(watch.Request.PowerCap).To(Equal(500.0))
+				plan.recommendations <- &planner.CalculateOptimalReplicasResponse{
+					DeploymentReplicas: []*planner.DeploymentReplicas{{Name: "llm-server", Namespace: "default", OptimalReplicas: 3}},
+				}
+				Eventually(reconciler.recommendations).Should(Receive())
+
+				plan.replicas["llm-server"] = 9
+				statuses, _ := reconciler.enforceScaleTargets(ctx, config, scaleBudget{fixed: 600})
+				Expect(statuses[0].MaxReplicas).To(Equal(int32(3)))
+				Eventually(plan.watches).Should(Receive(&watch))
+				Expect(watch.Request.PowerCap).To(Equal(600.0))
+
+				plan.recommendations <- &planner.CalculateOptimalReplicasResponse{
+					DeploymentReplicas: []*planner.DeploymentReplicas{{Name: "llm-server", Namespace: "default", OptimalReplicas: 4}},
+				}
+				Eventually(reconciler.recommendations).Should(Receive())
+				statuses, _ = reconciler.enforceScaleTargets(ctx, config, scaleBudget{fixed: 600})
+				Expect(statuses[0].MaxReplicas).To(Equal(int32(4)))
+				Consistently(plan.watches).ShouldNot(Receive())
+			})
+
+			It("should compute the replicas locally when the planner times out", func() {
+				plan := &fakePlanner{replicas: map[string]int32{"llm-server": 7}, delay: time.Second}
+				statuses, failed := newPlannedReconciler(plan).enforceScaleTargets(ctx, config, scaleBudget{fixed: 500})
+				Expect(failed).To(BeZero())
+				Expect(statuses[0].MaxReplicas).To(Equal(int32(2)))
+			})
+		})
+	})
+})
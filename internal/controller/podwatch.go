@@ -0,0 +1,318 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+)
+
+// defaultPodWatchInterval is how often a watched pod is sampled when the
+// reconciler sets no PodWatchInterval.
+const defaultPodWatchInterval = 15 * time.Second
+
+// PodWatches owns one watcher per pod matched by a config, keyed by the UID of
+// the pod. A watcher runs until the pod stops matching or is deleted, and is
+// restarted when the generation of its config changes, so repeated pod and
+// config events never start a second watcher for the same pod. The zero value
+// is ready to use.
+type PodWatches struct {
+	mu      sync.Mutex
+	watches map[podWatchKey]*podWatch
+}
+
+// podWatchKey identifies the watcher of a pod for a config; a pod matched by
+// two configs is watched by each.
+type podWatchKey struct {
+	config types.NamespacedName
+	uid    types.UID
+}
+
+// podWatch is the watcher of a pod, started for a generation of its config.
+type podWatch struct {
+	config     types.NamespacedName
+	pod        types.NamespacedName
+	uid        types.UID
+	generation int64
+	started    time.Time
+	cancel     context.CancelFunc
+
+	// powerCap is the cap the pod was last evaluated against, and power
+	// and sampled its last sample. samples is the sliding window of
+	// samples, of which peak and average are the highest and mean power.
+	// exceeded is set from when a sample exceeds the cap until one is back
+	// under it by the hysteresis band, and moved once the cap following the
+	// window moved beyond the band. They are guarded by the mutex of
+	// PodWatches.
+	powerCap float64
+	power    float64
+	sampled  time.Time
+	samples  []powerSample
+	peak     float64
+	average  float64
+	exceeded bool
+	moved    bool
+}
+
+// powerSample is a sample of the power of a pod, in watts.
+type powerSample struct {
+	time  time.Time
+	power float64
+}
+
+// PodWatchStatus describes an active pod watcher.
+type PodWatchStatus struct {
+	Config          string    `json:"config"`
+	Pod             string    `json:"pod"`
+	UID             types.UID `json:"uid"`
+	Generation      int64     `json:"generation"`
+	Started         time.Time `json:"started"`
+	PowerCapInWatts float64   `json:"powerCapInWatts"`
+	PowerInWatts    float64   `json:"powerInWatts"`
+	Sampled         time.Time `json:"sampled"`
+	// PeakPowerInWatts and AveragePowerInWatts are the highest and mean
+	// power over the sliding window of samples.
+	PeakPowerInWatts    float64 `json:"peakPowerInWatts"`
+	AveragePowerInWatts float64 `json:"averagePowerInWatts"`
+	Exceeded            bool    `json:"exceeded"`
+}
+
+// Active returns the active watchers, by config and pod.
+func (w *PodWatches) Active() []PodWatchStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	statuses := make([]PodWatchStatus, 0, len(w.watches))
+	for _, watch := range w.watches {
+		statuses = append(statuses, PodWatchStatus{
+			Config:          watch.config.String(),
+			Pod:             watch.pod.String(),
+			UID:             watch.uid,
+			Generation:      watch.generation,
+			Started:         watch.started,
+			PowerCapInWatts: watch.powerCap,
+			PowerInWatts:    watch.power,
+			Sampled:         watch.sampled,
+
+			PeakPowerInWatts:    watch.peak,
+			AveragePowerInWatts: watch.average,
+			Exceeded:            watch.exceeded,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Config != statuses[j].Config {
+			return statuses[i].Config < statuses[j].Config
+		}
+		return statuses[i].Pod < statuses[j].Pod
+	})
+	return statuses
+}
+
+// ServeHTTP writes the active watchers as JSON, for debugging.
+func (w *PodWatches) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(w.Active()); err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// track keeps the watchers of the config to the pods: pods not watched yet, or
+// watched for another generation of the config, get a new watcher running
+// run, and the watchers of pods no longer given are stopped. The cap of every
+// watcher is updated from powerCaps.
+func (w *PodWatches) track(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, pods []corev1.Pod, powerCaps map[types.UID]int, run func(ctx context.Context, watch *podWatch)) {
+	key := client.ObjectKeyFromObject(powerCappingConfig)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.watches == nil {
+		w.watches = make(map[podWatchKey]*podWatch)
+	}
+
+	matched := make(map[types.UID]bool, len(pods))
+	for i := range pods {
+		pod := &pods[i]
+		matched[pod.UID] = true
+		watchKey := podWatchKey{config: key, uid: pod.UID}
+		watch, ok := w.watches[watchKey]
+		if ok && watch.generation != powerCappingConfig.Generation {
+			watch.cancel()
+			ok = false
+		}
+		if !ok {
+			ctx, cancel := context.WithCancel(context.Background())
+			watch = &podWatch{
+				config:     key,
+				pod:        client.ObjectKeyFromObject(pod),
+				uid:        pod.UID,
+				generation: powerCappingConfig.Generation,
+				started:    time.Now(),
+				cancel:     cancel,
+			}
+			w.watches[watchKey] = watch
+			go run(ctx, watch)
+		}
+		if powerCap := float64(powerCaps[pod.UID]); powerCap != watch.powerCap {
+			watch.powerCap = powerCap
+			watch.exceeded, watch.moved = false, false
+		}
+	}
+	for watchKey, watch := range w.watches {
+		if watchKey.config == key && !matched[watchKey.uid] {
+			watch.cancel()
+			delete(w.watches, watchKey)
+		}
+	}
+}
+
+// stopConfig stops the watchers of the config.
+func (w *PodWatches) stopConfig(key types.NamespacedName) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for watchKey, watch := range w.watches {
+		if watchKey.config == key {
+			watch.cancel()
+			delete(w.watches, watchKey)
+		}
+	}
+}
+
+// record adds a sample of the pod to its sliding window, dropping the samples
+// older than window, and reports whether the pod crossed its cap: it started
+// exceeding the cap, or it is back under the cap by the hysteresis band after
+// exceeding it. Samples within the band keep the pod as it was.
+func (w *PodWatches) record(watch *podWatch, power float64, sampled time.Time, window time.Duration, hysteresis float64) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	watch.power, watch.sampled = power, sampled
+	samples := append(watch.samples, powerSample{time: sampled, power: power})
+	for len(samples) > 1 && !samples[0].time.After(sampled.Add(-window)) {
+		samples = samples[1:]
+	}
+	watch.samples = samples
+	watch.peak, watch.average = 0, 0
+	for _, sample := range samples {
+		watch.peak = max(watch.peak, sample.power)
+		watch.average += sample.power / float64(len(samples))
+	}
+
+	switch {
+	case watch.powerCap <= 0:
+		return false
+	case !watch.exceeded && power > watch.powerCap:
+		watch.exceeded = true
+		return true
+	case watch.exceeded && power <= watch.powerCap*(1-hysteresis):
+		watch.exceeded = false
+		return true
+	}
+	return false
+}
+
+// moved reports whether the cap computed by powerCap from the peak and
+// average power of the pod over a full window just moved beyond the
+// hysteresis band of the cap the pod was last evaluated against. It is
+// reported once per cap.
+func (w *PodWatches) moved(watch *podWatch, powerCap func(peak, average float64) float64, window time.Duration, hysteresis float64) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if watch.moved || watch.powerCap <= 0 || watch.sampled.Sub(watch.started) < window {
+		return false
+	}
+	watch.moved = movedBeyond(watch.powerCap, powerCap(watch.peak, watch.average), hysteresis)
+	return watch.moved
+}
+
+// podWatches returns the pod watchers of the reconciler, created on first use.
+func (r *PowerCappingConfigReconciler) podWatches() *PodWatches {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.PodWatches == nil {
+		r.PodWatches = &PodWatches{}
+	}
+	return r.PodWatches
+}
+
+// watchCappedPods watches the pods of the config that have a power cap, and
+// stops watching the others.
+func (r *PowerCappingConfigReconciler) watchCappedPods(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, pods []corev1.Pod, powerCaps map[types.UID]int) {
+	capped := make([]corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if powerCaps[pod.UID] > 0 {
+			capped = append(capped, pod)
+		}
+	}
+	r.podWatches().track(powerCappingConfig, capped, powerCaps, r.watchPod(powerCappingConfig.DeepCopy()))
+}
+
+// watchPod returns a watcher sampling the current power of a pod every
+// PodWatchInterval into a sliding window of the sample window of the config.
+// The config is reconciled, instead of at the next periodic reconcile, as
+// soon as the pod crosses the cap it was last evaluated against, either way,
+// or the cap following its peak or average power over the window moves
+// beyond the hysteresis band, e.g. when a training job enters a more
+// demanding phase.
+func (r *PowerCappingConfigReconciler) watchPod(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) func(ctx context.Context, watch *podWatch) {
+	window := getSampleWindow(powerCappingConfig)
+	hysteresis := getHysteresis(powerCappingConfig)
+	followed := r.followedPowerCap(powerCappingConfig)
+	return func(ctx context.Context, watch *podWatch) {
+		interval := r.PodWatchInterval
+		if interval <= 0 {
+			interval = defaultPodWatchInterval
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			source, err := r.metricsSource(powerCappingConfig)
+			if err != nil {
+				log.Error(err, "Failed to watch pod", "pod", watch.pod)
+				return
+			}
+			reading, err := source.PodPower(ctx, []types.NamespacedName{watch.pod}, 0)
+			if err != nil {
+				log.V(1).Info("Failed to sample pod power", "pod", watch.pod, "error", err.Error())
+				continue
+			}
+			crossed := r.podWatches().record(watch, reading.Current, r.now(), window, hysteresis)
+			moved := followed != nil && r.podWatches().moved(watch, followed, window, hysteresis)
+			if !(crossed || moved) || r.podWatchEvents == nil {
+				continue
+			}
+			log.Info("Watched pod changed against its power cap", "pod", watch.pod, "power", reading.Current,
+				"crossed", crossed, "moved", moved, "powerCappingConfig", watch.config)
+			select {
+			case r.podWatchEvents <- event.GenericEvent{Object: powerCappingConfig}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
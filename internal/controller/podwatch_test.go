@@ -0,0 +1,166 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+)
+
+var _ = Describe("PowerCappingConfig Controller", func() {
+	Context("When watching pods", func() {
+		config := newTestConfig(powercappingv1alpha1.PowerCappingConfigSpec{})
+		config.Generation = 1
+		key := client.ObjectKeyFromObject(config)
+
+		// run counts the watchers started and records their contexts.
+		var mu sync.Mutex
+		var contexts []context.Context
+		run := func(ctx context.Context, watch *podWatch) {
+			mu.Lock()
+			defer mu.Unlock()
+			contexts = append(contexts, ctx)
+		}
+		started := func() []context.Context {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]context.Context(nil), contexts...)
+		}
+		BeforeEach(func() {
+			mu.Lock()
+			contexts = nil
+			mu.Unlock()
+		})
+
+		It("should keep a single watcher per pod across events", func() {
+			watches := &PodWatches{}
+			pods := []corev1.Pod{newTestPod("llm-a")}
+			watches.track(config, pods, map[types.UID]int{"uid-llm-a": 200}, run)
+			watches.track(config, pods, map[types.UID]int{"uid-llm-a": 180}, run)
+
+			Eventually(started).Should(HaveLen(1))
+			Consistently(started, "50ms").Should(HaveLen(1))
+			active := watches.Active()
+			Expect(active).To(HaveLen(1))
+			Expect(active[0].Pod).To(Equal("default/llm-a"))
+			Expect(active[0].PowerCapInWatts).To(Equal(180.0))
+
+			recorder := httptest.NewRecorder()
+			watches.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/pod-watches", nil))
+			Expect(recorder.Body.String()).To(ContainSubstring(`"uid":"uid-llm-a"`))
+		})
+
+		It("should restart the watchers when the config changes", func() {
+			watches := &PodWatches{}
+			pods := []corev1.Pod{newTestPod("llm-a")}
+			watches.track(config, pods, nil, run)
+			Eventually(started).Should(HaveLen(1))
+
+			changed := config.DeepCopy()
+			changed.Generation = 2
+			watches.track(changed, pods, nil, run)
+			Eventually(started).Should(HaveLen(2))
+			Expect(started()[0].Err()).To(MatchError(context.Canceled))
+			Expect(started()[1].Err()).NotTo(HaveOccurred())
+			Expect(watches.Active()[0].Generation).To(Equal(int64(2)))
+		})
+
+		It("should stop the watchers of pods gone and of deleted configs", func() {
+			watches := &PodWatches{}
+			watches.track(config, []corev1.Pod{newTestPod("llm-a"), newTestPod("llm-b")}, nil, run)
+			Eventually(started).Should(HaveLen(2))
+
+			watches.track(config, []corev1.Pod{newTestPod("llm-b")}, nil, run)
+			Expect(watches.Active()).To(HaveLen(1))
+			Expect(watches.Active()[0].Pod).To(Equal("default/llm-b"))
+
+			watches.stopConfig(key)
+			Expect(watches.Active()).To(BeEmpty())
+			for _, ctx := range started() {
+				Expect(ctx.Err()).To(MatchError(context.Canceled))
+			}
+		})
+
+		It("should reconcile the config once a watched pod exceeds its cap", func() {
+			reconciler := &PowerCappingConfigReconciler{
+				PrometheusClient: &fakePrometheus{current: 250},
+				PodWatchInterval: 10 * time.Millisecond,
+				podWatchEvents:   make(chan event.GenericEvent),
+			}
+			defer reconciler.podWatches().stopConfig(key)
+			pods := []corev1.Pod{newTestPod("llm-a"), newTestPod("llm-b")}
+			reconciler.watchCappedPods(config, pods, map[types.UID]int{"uid-llm-a": 200})
+			Expect(reconciler.podWatches().Active()).To(HaveLen(1))
+
+			var exceeded event.GenericEvent
+			Eventually(reconciler.podWatchEvents).Should(Receive(&exceeded))
+			Expect(exceeded.Object.GetName()).To(Equal("config"))
+			// The pod is not reported again while it stays over the cap.
+			Consistently(reconciler.podWatchEvents, "50ms").ShouldNot(Receive())
+			Expect(reconciler.podWatches().Active()[0].PowerInWatts).To(Equal(250.0))
+		})
+
+		It("should track the peak and average over a sliding window", func() {
+			watches := &PodWatches{}
+			pod := newTestPod("pod")
+			watches.track(config, []corev1.Pod{pod}, map[types.UID]int{pod.UID: 200}, func(context.Context, *podWatch) {})
+			defer watches.stopConfig(key)
+			watch := watches.watches[podWatchKey{config: key, uid: pod.UID}]
+			start := watch.started
+
+			// The pod crosses the cap going up, and coming back only once
+			// 10% under it.
+			for i, step := range []struct {
+				power   float64
+				crossed bool
+			}{
+				{power: 250, crossed: true},
+				{power: 195, crossed: false},
+				{power: 170, crossed: true},
+				{power: 190, crossed: false},
+				{power: 210, crossed: true},
+			} {
+				sampled := start.Add(time.Duration(i+1) * time.Second)
+				Expect(watches.record(watch, step.power, sampled, 3*time.Second, 0.1)).To(Equal(step.crossed), "sample %d", i)
+			}
+			status := watches.Active()[0]
+			Expect(status.Exceeded).To(BeTrue())
+			// Only the samples of the last 3 seconds are kept.
+			Expect(status.PeakPowerInWatts).To(Equal(210.0))
+			Expect(status.AveragePowerInWatts).To(BeNumerically("~", 190, 1e-9))
+
+			peak := func(peak, _ float64) float64 { return peak }
+			Expect(watches.moved(watch, peak, 10*time.Second, 0.05)).To(BeFalse(), "the window is not full yet")
+			Expect(watches.moved(watch, peak, 3*time.Second, 0.05)).To(BeFalse(), "210W is within 5% of 200W")
+			watches.record(watch, 260, start.Add(6*time.Second), 3*time.Second, 0.05)
+			Expect(watches.moved(watch, peak, 3*time.Second, 0.05)).To(BeTrue())
+			Expect(watches.moved(watch, peak, 3*time.Second, 0.05)).To(BeFalse(), "a moved cap is reported once")
+		})
+	})
+})
@@ -0,0 +1,430 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prom_api "github.com/prometheus/client_golang/api"
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+
+	"github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+	"github.com/Climatik-Project/Climatik-Project/internal/metrics"
+)
+
+// PowerBudgetReconciler reconciles a PowerBudget object
+type PowerBudgetReconciler struct {
+	client.Client
+	Scheme           *runtime.Scheme
+	PrometheusClient prom_v1.API
+	// MetricsSources, MetricsSource and MetricNames select the metrics
+	// source of each config as PowerCappingConfigReconciler does.
+	MetricsSources map[string]metrics.PowerMetricsSource
+	MetricsSource  string
+	MetricNames    *metrics.SharedNames
+}
+
+// budgetChild is a PowerCappingConfig sharing a power budget, with the power
+// consumption of its pods on the nodes of the budget as demand.
+type budgetChild struct {
+	key        types.NamespacedName
+	priority   int32
+	weight     int32
+	demand     float64
+	allocation float64
+}
+
+// budgetSelectors holds the parsed selectors of a PowerBudget. A nil
+// namespaces or nodes selects all of them.
+type budgetSelectors struct {
+	configs    labels.Selector
+	namespaces map[string]bool
+	nodes      map[string]bool
+}
+
+//+kubebuilder:rbac:groups=climatik-project.io,resources=powerbudgets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=climatik-project.io,resources=powerbudgets/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+
+// Reconcile divides the power cap of a PowerBudget between the
+// PowerCappingConfigs it selects and records the allocations in its status.
+// Each config reads its allocation from there and bounds its own power cap
+// with it, so that platform teams own the budget and application teams own
+// their configs. The budget is requeued after the default sample window to
+// follow the power consumption of the configs.
+func (r *PowerBudgetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	powerBudget := &powercappingv1alpha1.PowerBudget{}
+	if err := r.Get(ctx, req.NamespacedName, powerBudget); err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("PowerBudget resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get PowerBudget")
+		return ctrl.Result{}, err
+	}
+	log.Info("Reconcile", "powerBudget", req.Name)
+
+	selectors, err := r.budgetSelectors(ctx, powerBudget)
+	if err != nil {
+		if invalid, ok := err.(*selectorError); ok {
+			// A spec change is needed to recover, which triggers a new reconcile.
+			return ctrl.Result{}, r.reportInvalidSelector(ctx, powerBudget, invalid.Error())
+		}
+		log.Error(err, "Failed to resolve PowerBudget selectors", "powerBudget", req.Name)
+		return ctrl.Result{}, err
+	}
+
+	children, failed, err := r.budgetChildren(ctx, powerBudget, selectors)
+	if err != nil {
+		log.Error(err, "Failed to list PowerCappingConfigs for PowerBudget", "powerBudget", req.Name)
+		return ctrl.Result{}, err
+	}
+	allocatePowerBudget(float64(powerBudget.Spec.PowerCapInWatts), children)
+	if err := r.updateStatus(ctx, powerBudget, children, failed); err != nil {
+		log.Error(err, "Failed to update PowerBudget status", "powerBudget", req.Name)
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: defaultSampleWindow}, nil
+}
+
+// selectorError reports a selector of a PowerBudget that cannot be parsed.
+type selectorError struct {
+	field string
+	err   error
+}
+
+func (e *selectorError) Error() string {
+	return fmt.Sprintf("invalid %s: %v", e.field, e.err)
+}
+
+// budgetSelectors parses the selectors of the budget and resolves the
+// namespaces and nodes they select.
+func (r *PowerBudgetReconciler) budgetSelectors(ctx context.Context, powerBudget *powercappingv1alpha1.PowerBudget) (*budgetSelectors, error) {
+	spec := &powerBudget.Spec
+	selectors := &budgetSelectors{configs: labels.Everything()}
+	if spec.ConfigSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(spec.ConfigSelector)
+		if err != nil {
+			return nil, &selectorError{field: "configSelector", err: err}
+		}
+		selectors.configs = selector
+	}
+	if spec.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(spec.NamespaceSelector)
+		if err != nil {
+			return nil, &selectorError{field: "namespaceSelector", err: err}
+		}
+		namespaceList := &corev1.NamespaceList{}
+		if err := r.List(ctx, namespaceList, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, err
+		}
+		selectors.namespaces = make(map[string]bool, len(namespaceList.Items))
+		for _, namespace := range namespaceList.Items {
+			selectors.namespaces[namespace.Name] = true
+		}
+	}
+	if spec.NodeSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(spec.NodeSelector)
+		if err != nil {
+			return nil, &selectorError{field: "nodeSelector", err: err}
+		}
+		nodeList := &corev1.NodeList{}
+		if err := r.List(ctx, nodeList, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, err
+		}
+		selectors.nodes = make(map[string]bool, len(nodeList.Items))
+		for _, node := range nodeList.Items {
+			selectors.nodes[node.Name] = true
+		}
+	}
+	return selectors, nil
+}
+
+// budgetChildren returns the configs selected by the budget, with their
+// priority, weight and current power on the nodes of the budget, along with
+// the number of configs whose power could not be queried. Those count as
+// consuming nothing.
+func (r *PowerBudgetReconciler) budgetChildren(ctx context.Context, powerBudget *powercappingv1alpha1.PowerBudget, selectors *budgetSelectors) ([]budgetChild, int, error) {
+	configList := &powercappingv1alpha1.PowerCappingConfigList{}
+	if err := r.List(ctx, configList, client.MatchingLabelsSelector{Selector: selectors.configs}); err != nil {
+		return nil, 0, err
+	}
+	// The pods of a config are resolved and measured as the config does.
+	configs := &PowerCappingConfigReconciler{Client: r.Client, PrometheusClient: r.PrometheusClient,
+		MetricsSources: r.MetricsSources, MetricsSource: r.MetricsSource, MetricNames: r.MetricNames}
+
+	var children []budgetChild
+	failed := 0
+	for i := range configList.Items {
+		powerCappingConfig := &configList.Items[i]
+		if selectors.namespaces != nil && !selectors.namespaces[powerCappingConfig.Namespace] {
+			continue
+		}
+		if !powerCappingConfig.DeletionTimestamp.IsZero() {
+			continue
+		}
+		child := budgetChild{key: client.ObjectKeyFromObject(powerCappingConfig), weight: 1}
+		if share := budgetShare(powerBudget, powerCappingConfig); share != nil {
+			child.priority = share.Priority
+			if share.Weight > 0 {
+				child.weight = share.Weight
+			}
+		}
+
+		pods, err := configs.listTargetPods(ctx, powerCappingConfig)
+		if err != nil {
+			return nil, 0, err
+		}
+		if selectors.nodes != nil {
+			onNodes := pods[:0]
+			for _, pod := range pods {
+				if selectors.nodes[pod.Spec.NodeName] {
+					onNodes = append(onNodes, pod)
+				}
+			}
+			pods = onNodes
+		}
+		if len(pods) > 0 {
+			demand, err := configs.measureCurrentPower(ctx, powerCappingConfig, pods...)
+			if err != nil {
+				log.Error(err, "Failed to query config power", "powerBudget", powerBudget.Name, "powerCappingConfig", child.key)
+				failed++
+			} else {
+				child.demand = demand
+			}
+		}
+		children = append(children, child)
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].key.String() < children[j].key.String() })
+	return children, failed, nil
+}
+
+// budgetShare returns the first share of the budget matching the config, or nil.
+func budgetShare(powerBudget *powercappingv1alpha1.PowerBudget, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) *powercappingv1alpha1.PowerBudgetShare {
+	for i := range powerBudget.Spec.Shares {
+		share := &powerBudget.Spec.Shares[i]
+		if share.Namespace == powerCappingConfig.Namespace && (share.Name == "" || share.Name == powerCappingConfig.Name) {
+			return share
+		}
+	}
+	return nil
+}
+
+// allocatePowerBudget divides budget between the children. Priorities are
+// served in decreasing order: the children of a priority split what is left
+// by weight, each receiving no more than its demand. What is left once every
+// demand is met is split between all children by weight as headroom, so that
+// the allocations always add up to the budget.
+func allocatePowerBudget(budget float64, children []budgetChild) {
+	order := make([]int, len(children))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return children[order[i]].priority > children[order[j]].priority })
+
+	remaining := budget
+	for start := 0; start < len(order) && remaining > 0; {
+		end := start + 1
+		for end < len(order) && children[order[end]].priority == children[order[start]].priority {
+			end++
+		}
+		remaining = fillByWeight(children, order[start:end], remaining)
+		start = end
+	}
+	if remaining > 0 {
+		splitByWeight(children, order, remaining)
+	}
+}
+
+// fillByWeight splits available between the children of group by weight
+// without exceeding their demand, and returns what is left.
+func fillByWeight(children []budgetChild, group []int, available float64) float64 {
+	pending := append([]int(nil), group...)
+	for len(pending) > 0 && available > 0 {
+		var weights float64
+		for _, i := range pending {
+			weights += float64(children[i].weight)
+		}
+		if weights == 0 {
+			break
+		}
+		// Children whose demand is below their share are served in full and
+		// the rest is split again between the others.
+		unmet := pending[:0:0]
+		met := 0.0
+		for _, i := range pending {
+			need := children[i].demand - children[i].allocation
+			if need <= available*float64(children[i].weight)/weights {
+				children[i].allocation += need
+				met += need
+			} else {
+				unmet = append(unmet, i)
+			}
+		}
+		available -= met
+		if len(unmet) == len(pending) {
+			splitByWeight(children, unmet, available)
+			return 0
+		}
+		pending = unmet
+	}
+	return available
+}
+
+// splitByWeight adds available to the allocations of the children by weight.
+func splitByWeight(children []budgetChild, group []int, available float64) {
+	var weights float64
+	for _, i := range group {
+		weights += float64(children[i].weight)
+	}
+	if weights == 0 {
+		return
+	}
+	for _, i := range group {
+		children[i].allocation += available * float64(children[i].weight) / weights
+	}
+}
+
+// updateStatus writes the allocations of the budget to the status subresource.
+func (r *PowerBudgetReconciler) updateStatus(ctx context.Context, powerBudget *powercappingv1alpha1.PowerBudget, children []budgetChild, failed int) error {
+	patch := client.MergeFrom(powerBudget.DeepCopy())
+	status := &powerBudget.Status
+	generation := powerBudget.Generation
+
+	var currentPower float64
+	allocations := make([]powercappingv1alpha1.PowerBudgetAllocation, 0, len(children))
+	for _, child := range children {
+		currentPower += child.demand
+		allocations = append(allocations, powercappingv1alpha1.PowerBudgetAllocation{
+			Name:             child.key.Name,
+			Namespace:        child.key.Namespace,
+			Priority:         child.priority,
+			Weight:           child.weight,
+			PowerConsumption: int(child.demand),
+			PowerCapInWatts:  int(child.allocation),
+		})
+	}
+
+	now := metav1.Now()
+	status.ObservedGeneration = generation
+	status.CurrentPowerConsumption = int(currentPower)
+	status.Allocations = allocations
+	status.LastEvaluationTime = &now
+
+	switch {
+	case len(children) == 0:
+		setBudgetCondition(status, generation, v1alpha1.ConditionDegraded, metav1.ConditionFalse, "AsExpected", "No PowerCappingConfig is selected")
+		setBudgetCondition(status, generation, v1alpha1.ConditionReady, metav1.ConditionTrue, "NoConfigs", "No PowerCappingConfig is selected")
+	case failed > 0:
+		message := fmt.Sprintf("Power could not be queried for %d of %d configs", failed, len(children))
+		setBudgetCondition(status, generation, v1alpha1.ConditionDegraded, metav1.ConditionTrue, "QueryFailed", message)
+		setBudgetCondition(status, generation, v1alpha1.ConditionReady, metav1.ConditionFalse, "QueryFailed", message)
+	default:
+		setBudgetCondition(status, generation, v1alpha1.ConditionDegraded, metav1.ConditionFalse, "AsExpected", "All selected configs were measured")
+		setBudgetCondition(status, generation, v1alpha1.ConditionReady, metav1.ConditionTrue, "Allocated",
+			fmt.Sprintf("The budget is allocated to %d configs", len(children)))
+	}
+
+	if currentPower > float64(powerBudget.Spec.PowerCapInWatts) {
+		setBudgetCondition(status, generation, v1alpha1.ConditionCapped, metav1.ConditionTrue, "PowerBudgetExceeded",
+			fmt.Sprintf("The selected configs consume %.0fW > %dW", currentPower, powerBudget.Spec.PowerCapInWatts))
+	} else {
+		setBudgetCondition(status, generation, v1alpha1.ConditionCapped, metav1.ConditionFalse, "WithinPowerBudget", "The selected configs consume less than the budget")
+	}
+
+	return r.Status().Patch(ctx, powerBudget, patch)
+}
+
+// reportInvalidSelector marks the budget as not ready and drops its
+// allocations, which releases the configs it selected.
+func (r *PowerBudgetReconciler) reportInvalidSelector(ctx context.Context, powerBudget *powercappingv1alpha1.PowerBudget, message string) error {
+	patch := client.MergeFrom(powerBudget.DeepCopy())
+	status := &powerBudget.Status
+	generation := powerBudget.Generation
+
+	now := metav1.Now()
+	status.ObservedGeneration = generation
+	status.CurrentPowerConsumption = 0
+	status.Allocations = nil
+	status.LastEvaluationTime = &now
+	setBudgetCondition(status, generation, v1alpha1.ConditionReady, metav1.ConditionFalse, "InvalidSelector", message)
+	return r.Status().Patch(ctx, powerBudget, patch)
+}
+
+func setBudgetCondition(status *powercappingv1alpha1.PowerBudgetStatus, generation int64, conditionType string, conditionStatus metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// mapConfigToBudgets enqueues every PowerBudget when a config changes, since
+// any of them may select it. Budgets are few and cluster-scoped.
+func (r *PowerBudgetReconciler) mapConfigToBudgets(ctx context.Context, obj client.Object) []reconcile.Request {
+	budgetList := &powercappingv1alpha1.PowerBudgetList{}
+	if err := r.List(ctx, budgetList); err != nil {
+		log.Error(err, "Failed to list PowerBudgets for config", "powerCappingConfig", client.ObjectKeyFromObject(obj))
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(budgetList.Items))
+	for _, powerBudget := range budgetList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: powerBudget.Name}})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PowerBudgetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	log.Info("Setting up PowerBudgetReconciler")
+	if r.PrometheusClient == nil {
+		promClient, err := prom_api.NewClient(prom_api.Config{
+			Address: PrometheusURL,
+		})
+		if err != nil {
+			return err
+		}
+		r.PrometheusClient = prom_v1.NewAPI(promClient)
+	}
+
+	// Only new and deleted configs and spec changes move the allocations;
+	// power changes are followed by the periodic requeue.
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&powercappingv1alpha1.PowerBudget{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&powercappingv1alpha1.PowerCappingConfig{}, handler.EnqueueRequestsFromMapFunc(r.mapConfigToBudgets),
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}))).
+		Complete(r)
+}
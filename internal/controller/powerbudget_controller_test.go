@@ -0,0 +1,263 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+)
+
+var _ = Describe("PowerBudget Controller", func() {
+	Context("When allocating a power budget", func() {
+		allocations := func(children []budgetChild) []float64 {
+			values := make([]float64, 0, len(children))
+			for _, child := range children {
+				values = append(values, child.allocation)
+			}
+			return values
+		}
+
+		It("should give every config its demand and split the headroom by weight", func() {
+			children := []budgetChild{
+				{weight: 1, demand: 100},
+				{weight: 3, demand: 200},
+			}
+			allocatePowerBudget(700, children)
+			Expect(allocations(children)).To(Equal([]float64{200, 500}))
+		})
+
+		It("should serve higher priorities first", func() {
+			children := []budgetChild{
+				{priority: 0, weight: 1, demand: 400},
+				{priority: 10, weight: 1, demand: 300},
+			}
+			allocatePowerBudget(500, children)
+			Expect(allocations(children)).To(Equal([]float64{200, 300}))
+		})
+
+		It("should split a short budget by weight without exceeding demands", func() {
+			children := []budgetChild{
+				{weight: 1, demand: 50},
+				{weight: 1, demand: 400},
+				{weight: 2, demand: 400},
+			}
+			allocatePowerBudget(650, children)
+			Expect(allocations(children)).To(Equal([]float64{50, 200, 400}))
+		})
+
+		It("should give configs without demand only headroom", func() {
+			children := []budgetChild{
+				{weight: 1, demand: 0},
+				{weight: 1, demand: 600},
+			}
+			allocatePowerBudget(500, children)
+			Expect(allocations(children)).To(Equal([]float64{0, 500}))
+		})
+	})
+
+	Context("When reconciling a power budget", func() {
+		ctx := context.Background()
+
+		newPod := func(name, namespace, app, node string) *corev1.Pod {
+			return &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{labelKey: app}},
+				Spec:       corev1.PodSpec{NodeName: node},
+				Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+			}
+		}
+		newConfig := func(name, namespace string) *powercappingv1alpha1.PowerCappingConfig {
+			return &powercappingv1alpha1.PowerCappingConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{"tier": "budgeted"}},
+			}
+		}
+
+		It("should allocate the budget to the selected configs on the selected nodes", func() {
+			powerBudget := &powercappingv1alpha1.PowerBudget{
+				ObjectMeta: metav1.ObjectMeta{Name: "rack-a", Generation: 2},
+				Spec: powercappingv1alpha1.PowerBudgetSpec{
+					PowerCapInWatts: 1000,
+					NodeSelector:    &metav1.LabelSelector{MatchLabels: map[string]string{"rack": "a"}},
+					ConfigSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "budgeted"}},
+					Shares: []powercappingv1alpha1.PowerBudgetShare{
+						{Namespace: "serving", Priority: 10, Weight: 2},
+					},
+				},
+			}
+			unselected := newConfig("batch", "training")
+			unselected.Labels = nil
+			reconciler := &PowerBudgetReconciler{
+				Client: fake.NewClientBuilder().
+					WithScheme(newTestScheme()).
+					WithObjects(
+						powerBudget,
+						newConfig("llm", "serving"),
+						newConfig("trainer", "training"),
+						unselected,
+						&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a1", Labels: map[string]string{"rack": "a"}}},
+						&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-b1", Labels: map[string]string{"rack": "b"}}},
+						newPod("llm-one", "serving", "llm", "node-a1"),
+						newPod("llm-two", "serving", "llm", "node-b1"),
+						newPod("trainer-one", "training", "trainer", "node-a1"),
+						newPod("batch-one", "training", "batch", "node-a1"),
+					).
+					WithStatusSubresource(powerBudget).
+					Build(),
+				PrometheusClient: &fakePodPower{power: map[string]float64{
+					"llm-one":     300,
+					"llm-two":     500,
+					"trainer-one": 900,
+					"batch-one":   100,
+				}},
+			}
+
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "rack-a"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(defaultSampleWindow))
+
+			updated := &powercappingv1alpha1.PowerBudget{}
+			Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(powerBudget), updated)).To(Succeed())
+			Expect(updated.Status.ObservedGeneration).To(Equal(int64(2)))
+			Expect(updated.Status.CurrentPowerConsumption).To(Equal(1200))
+			Expect(updated.Status.Allocations).To(Equal([]powercappingv1alpha1.PowerBudgetAllocation{
+				{Name: "llm", Namespace: "serving", Priority: 10, Weight: 2, PowerConsumption: 300, PowerCapInWatts: 300},
+				{Name: "trainer", Namespace: "training", Weight: 1, PowerConsumption: 900, PowerCapInWatts: 700},
+			}))
+			Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, powercappingv1alpha1.ConditionReady)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, powercappingv1alpha1.ConditionCapped)).To(BeTrue())
+		})
+
+		It("should drop the allocations of a budget with an invalid selector", func() {
+			powerBudget := &powercappingv1alpha1.PowerBudget{
+				ObjectMeta: metav1.ObjectMeta{Name: "rack-a"},
+				Spec: powercappingv1alpha1.PowerBudgetSpec{
+					PowerCapInWatts: 1000,
+					NodeSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "rack", Operator: "Near"},
+					}},
+				},
+				Status: powercappingv1alpha1.PowerBudgetStatus{Allocations: []powercappingv1alpha1.PowerBudgetAllocation{
+					{Name: "llm", Namespace: "serving", PowerCapInWatts: 1000},
+				}},
+			}
+			reconciler := &PowerBudgetReconciler{
+				Client: fake.NewClientBuilder().
+					WithScheme(newTestScheme()).
+					WithObjects(powerBudget).
+					WithStatusSubresource(powerBudget).
+					Build(),
+			}
+
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "rack-a"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeZero())
+
+			updated := &powercappingv1alpha1.PowerBudget{}
+			Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(powerBudget), updated)).To(Succeed())
+			Expect(updated.Status.Allocations).To(BeEmpty())
+			condition := meta.FindStatusCondition(updated.Status.Conditions, powercappingv1alpha1.ConditionReady)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Reason).To(Equal("InvalidSelector"))
+		})
+	})
+
+	Context("When bounding a config by its power budget", func() {
+		ctx := context.Background()
+
+		It("should apply the tightest allocation to the pods of the config", func() {
+			config := &powercappingv1alpha1.PowerCappingConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "llm", Namespace: "serving"},
+				Spec: powercappingv1alpha1.PowerCappingConfigSpec{
+					PowerCappingSpec: powercappingv1alpha1.PowerCappingSpec{
+						Kind:                        powercappingv1alpha1.AbsolutePowerCapInWatts,
+						AbsolutePowerCapInWattsSpec: powercappingv1alpha1.AbsolutePowerCapInWattsSpec{PowerCapInWatts: 300},
+					},
+				},
+			}
+			allocated := func(name string, powerCap int) *powercappingv1alpha1.PowerBudget {
+				return &powercappingv1alpha1.PowerBudget{
+					ObjectMeta: metav1.ObjectMeta{Name: name},
+					Status: powercappingv1alpha1.PowerBudgetStatus{Allocations: []powercappingv1alpha1.PowerBudgetAllocation{
+						{Name: "llm", Namespace: "serving", PowerCapInWatts: powerCap},
+					}},
+				}
+			}
+			reconciler := &PowerCappingConfigReconciler{
+				Client: fake.NewClientBuilder().
+					WithScheme(newTestScheme()).
+					WithObjects(
+						config,
+						allocated("cluster", 800),
+						allocated("rack-a", 400),
+						&corev1.Pod{
+							ObjectMeta: metav1.ObjectMeta{Name: "llm-one", Namespace: "serving", Labels: map[string]string{labelKey: "llm"}},
+							Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+						},
+						&corev1.Pod{
+							ObjectMeta: metav1.ObjectMeta{Name: "llm-two", Namespace: "serving", Labels: map[string]string{labelKey: "llm"}},
+							Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+						},
+					).
+					WithStatusSubresource(config).
+					Build(),
+				PrometheusClient: &fakePrometheus{current: 100, peak: 150, average: 120},
+			}
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(config)})
+			Expect(err).NotTo(HaveOccurred())
+
+			updated := &powercappingv1alpha1.PowerCappingConfig{}
+			Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(config), updated)).To(Succeed())
+			Expect(updated.Status.PowerBudget).To(Equal("rack-a"))
+			Expect(updated.Status.BudgetPowerCapInWatts).To(Equal(400))
+			Expect(updated.Status.PowerCapInWatts).To(Equal(200))
+		})
+
+		It("should cap configs without a power cap kind at their allocation", func() {
+			config := &powercappingv1alpha1.PowerCappingConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "llm", Namespace: "serving"},
+				Spec: powercappingv1alpha1.PowerCappingConfigSpec{
+					PowerCappingSpec: powercappingv1alpha1.PowerCappingSpec{Scope: powercappingv1alpha1.PowerCappingScopeAggregate},
+				},
+			}
+			pods := []corev1.Pod{
+				{ObjectMeta: metav1.ObjectMeta{Name: "llm-one", Namespace: "serving", UID: "uid-one"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "llm-two", Namespace: "serving", UID: "uid-two"}},
+			}
+			reconciler := &PowerCappingConfigReconciler{PrometheusClient: &fakePrometheus{current: 100, peak: 150, average: 120}}
+
+			aggregate, failed := reconciler.enforceAggregatePowerCap(ctx, config, pods, defaultSampleWindow, 0, nil)
+			Expect(failed).To(BeZero())
+			Expect(aggregate).To(BeNil())
+
+			aggregate, failed = reconciler.enforceAggregatePowerCap(ctx, config, pods, defaultSampleWindow, 500, nil)
+			Expect(failed).To(BeZero())
+			Expect(aggregate.total.powerCap).To(Equal(500.0))
+			Expect(aggregate.pods[0].powerCap).To(Equal(250.0))
+		})
+	})
+})
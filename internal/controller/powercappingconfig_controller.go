@@ -19,35 +19,41 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/client-go/informers"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/cache"
 
 	prom_api "github.com/prometheus/client_golang/api"
 	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
-	"github.com/prometheus/common/model"
 
 	"github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
 	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
 	service "github.com/Climatik-Project/Climatik-Project/internal/alert"
-	mockConfig "github.com/Climatik-Project/Climatik-Project/internal/alert/tests"
+	"github.com/Climatik-Project/Climatik-Project/internal/carbon"
+	"github.com/Climatik-Project/Climatik-Project/internal/config"
+	"github.com/Climatik-Project/Climatik-Project/internal/metrics"
+	"github.com/Climatik-Project/Climatik-Project/internal/planner"
 )
 
 const (
-	labelKey              = "climatik-project.io"
-	defaultPowerCapHigh   = 90
-	defaultPowerCapMedium = 80
-	defaultPowerCapLow    = 50
+	labelKey            = "climatik-project.io"
+	defaultSampleWindow = v1alpha1.DefaultSampleWindowInSeconds * time.Second
 )
 
 var (
@@ -55,13 +61,128 @@ var (
 	log           = ctrl.Log.WithName("controller")
 )
 
+// podEvaluation holds the power figures measured for a single pod, in watts.
+type podEvaluation struct {
+	pod          *corev1.Pod
+	currentPower float64
+	peakPower    float64
+	averagePower float64
+	powerCap     float64
+	// forecastPower is the power predicted for the pod, or for all pods
+	// together in Aggregate scope, when the config acts on its forecast.
+	forecastPower float64
+	// followsPower is set when the power cap was computed from the power of
+	// the pods themselves, which falls with their replicas.
+	followsPower bool
+}
+
+// overCap reports whether the power, or the forecast acted on, exceeds the
+// power cap.
+func (e *podEvaluation) overCap() bool {
+	return max(e.currentPower, e.forecastPower) > e.powerCap
+}
+
+// configEvaluation is the outcome of evaluating a config against the pods it
+// targets and the nodes they run on.
+type configEvaluation struct {
+	matched int
+	// pods holds the per-pod evaluations in Pod scope.
+	pods []podEvaluation
+	// aggregate holds the evaluation of all pods together in Aggregate scope.
+	aggregate *aggregateEvaluation
+	// failed is the number of pods that could not be evaluated.
+	failed            int
+	temperatures      []nodeTemperature
+	temperatureFailed int
+	// nodes reports the nodes selected by the node power cap.
+	nodes      []powercappingv1alpha1.NodePowerStatus
+	nodeFailed int
+	// scaleTargets reports the bounds set on the referenced scale targets.
+	scaleTargets    []powercappingv1alpha1.ScaleTargetStatus
+	actuationFailed int
+	// carbonIntensity is the carbon intensity followed by a carbon-aware cap.
+	carbonIntensity float64
+	// scheduleWindow names the active schedule window and
+	// nextScheduleTransition is when the next one starts or it ends.
+	scheduleWindow         string
+	nextScheduleTransition time.Time
+	// budgetName names the PowerBudget whose allocation, budgetPowerCap,
+	// bounds the power cap of all pods together.
+	budgetName     string
+	budgetPowerCap float64
+	// forecast is the power predicted for all pods together, and
+	// forecastFailure explains why it could not be.
+	forecast        *powerForecast
+	forecastFailure string
+}
+
+// evaluated returns the number of pods that could be evaluated.
+func (e *configEvaluation) evaluated() int {
+	if e.aggregate != nil {
+		return len(e.aggregate.pods)
+	}
+	return len(e.pods)
+}
+
 // PowerCappingConfigReconciler reconciles a PowerCappingConfig object
 type PowerCappingConfigReconciler struct {
 	client.Client
 	Scheme           *runtime.Scheme
-	PodInformer      cache.SharedIndexInformer
 	PrometheusClient prom_v1.API
-	AlertService     *service.AlertService
+	// MetricsSources are the sources of power and temperature metrics by
+	// name, of which configs read MetricsSource unless they select another.
+	// Kepler and DCGM default to reading PrometheusClient, with the series
+	// and label names of MetricNames when set.
+	MetricsSources map[string]metrics.PowerMetricsSource
+	MetricsSource  string
+	MetricNames    *metrics.SharedNames
+	AlertService   *service.AlertService
+	// EfficiencyLevels give the percentages of the configs that set an
+	// efficiency level rather than a percentage, the defaults when nil.
+	EfficiencyLevels *config.SharedEfficiencyLevels
+	// Planner, when set, plans the replicas of the scale targets. Calls that
+	// fail or last longer than PlannerTimeout fall back to a local computation.
+	// Each config also watches the recommendations of the planner, and
+	// re-subscribes after PlannerBackoff when the watch breaks.
+	Planner        planner.PlannerClient
+	PlannerTimeout time.Duration
+	PlannerBackoff planner.Backoff
+	// CarbonIntensityProvider supplies the carbon intensity followed by
+	// carbon-aware power caps, in CarbonZone unless a config names its zone.
+	CarbonIntensityProvider carbon.CarbonIntensityProvider
+	CarbonZone              string
+	// PodWatches samples every capped pod each PodWatchInterval between
+	// reconciles, and reconciles its config as soon as the pod exceeds its
+	// cap. It is created on first use when nil.
+	PodWatches       *PodWatches
+	PodWatchInterval time.Duration
+
+	// alertedCaps remembers the last power cap alerted for each pod of a
+	// config so that periodic reconciles do not re-send identical alerts.
+	// alertedAggregates does the same for the aggregate cap of a config and
+	// alertedNodes for nodes exceeding the temperature threshold.
+	mu                sync.Mutex
+	alertedCaps       map[types.NamespacedName]map[types.UID]int
+	alertedAggregates map[types.NamespacedName]int
+	alertedNodes      map[types.NamespacedName]map[string]bool
+	// openAlerts holds the names of the pods of each config whose alert was
+	// created and not resolved yet, persisted in Status.AlertedPods, and
+	// resolveAttempts counts the attempts to resolve them on deletion.
+	openAlerts      map[types.NamespacedName]map[string]bool
+	resolveAttempts map[types.NamespacedName]int
+	// evictedNodes holds when pods were last evicted from each node.
+	evictedNodes map[string]time.Time
+
+	// subscriptions holds the watch of the planner recommendations of each
+	// config; every plan received is sent to recommendations to reconcile
+	// the config.
+	subscriptions   map[types.NamespacedName]*plannerSubscription
+	recommendations chan event.GenericEvent
+	// podWatchEvents receives the configs of watched pods exceeding their cap.
+	podWatchEvents chan event.GenericEvent
+
+	// clock returns the time at which schedules are evaluated, time.Now when nil.
+	clock func() time.Time
 }
 
 //+kubebuilder:rbac:groups=climatik-project.io,resources=powercappingconfigs,verbs=get;list;watch;create;update;patch;delete
@@ -69,174 +190,580 @@ type PowerCappingConfigReconciler struct {
 //+kubebuilder:rbac:groups=climatik-project.io,resources=powercappingconfigs/finalizers,verbs=update
 //+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=pods/status,verbs=get
+//+kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups=keda.sh,resources=scaledobjects,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
+//
+// Every reconcile resolves the pods targeted by the PowerCappingConfig, queries
+// their power consumption over the sample window, computes the power cap,
+// raises alerts and records the outcome in the status subresource. The config
+// is requeued after one sample window so that the evaluation keeps converging
+// even without watch events.
 func (r *PowerCappingConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	// Fetch the PowerCappingConfig instance
-	// List all the power capping configs instances
 	powerCappingConfig := &powercappingv1alpha1.PowerCappingConfig{}
 	err := r.Get(ctx, req.NamespacedName, powerCappingConfig)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			log.Info("PowerCappingConfig resource not found. Ignoring since object must be deleted")
+			r.forgetAlerts(req.NamespacedName, nil)
+			r.forgetAggregateAlert(req.NamespacedName)
+			r.forgetTemperatureAlerts(req.NamespacedName, nil)
+			r.forgetRecommendations(req.NamespacedName)
+			r.forgetOpenAlerts(req.NamespacedName)
+			r.podWatches().stopConfig(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		log.Error(err, "Failed to get PowerCappingConfig")
 		return ctrl.Result{}, err
 	}
-	log.Info("Reconcile", "powerCappingConfig:", fmt.Sprintf("powerCappingConfig %v", powerCappingConfig))
-	return ctrl.Result{}, nil
-}
-
-func (r *PowerCappingConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	log.Info("Setting up PowerCappingConfigReconciler")
-	// Create a new Kubernetes client
-	kubeClient, err := kubernetes.NewForConfig(mgr.GetConfig())
-	if err != nil {
-		log.Info("Failed to create Kubernetes client")
-		return err
+	log.Info("Reconcile", "powerCappingConfig", req.NamespacedName)
+
+	if !powerCappingConfig.DeletionTimestamp.IsZero() {
+		r.forgetAlerts(req.NamespacedName, nil)
+		r.forgetAggregateAlert(req.NamespacedName)
+		r.forgetTemperatureAlerts(req.NamespacedName, nil)
+		r.forgetRecommendations(req.NamespacedName)
+		r.podWatches().stopConfig(req.NamespacedName)
+		return ctrl.Result{}, r.finalize(ctx, powerCappingConfig)
+	}
+	if err := r.ensureFinalizer(ctx, powerCappingConfig); err != nil {
+		log.Error(err, "Failed to add finalizer", "powerCappingConfig", req.NamespacedName)
+		return ctrl.Result{}, err
 	}
 
-	// Create a new shared informer factory
-	factory := informers.NewSharedInformerFactory(kubeClient, 0)
-	log.Info("Shared informer factory created")
-	// Create a new pod informer
-	r.PodInformer = factory.Core().V1().Pods().Informer()
+	if message := unsupportedKindMessage(powerCappingConfig); message != "" {
+		log.Info("Unsupported PowerCappingConfig kind", "message", message)
+		r.forgetAlerts(req.NamespacedName, nil)
+		r.forgetAggregateAlert(req.NamespacedName)
+		r.forgetTemperatureAlerts(req.NamespacedName, nil)
+		r.forgetRecommendations(req.NamespacedName)
+		r.podWatches().stopConfig(req.NamespacedName)
+		// A spec change is needed to recover, which triggers a new reconcile.
+		return ctrl.Result{}, r.reportUnsupportedKind(ctx, powerCappingConfig, message)
+	}
 
-	// Add event handlers to the pod informer
-	r.PodInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc: r.handlePodAdd,
-		UpdateFunc: func(oldObj, newObj interface{}) {
-			r.handlePodAdd(newObj)
-		},
-		DeleteFunc: r.handlePodDelete,
-	})
-	log.Info("event handler created")
-	// Add the shared informer factory to the manager's runnable list
-	err = mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
-		factory.Start(ctx.Done())
-		return nil
-	}))
+	pods, err := r.listTargetPods(ctx, powerCappingConfig)
 	if err != nil {
-		return err
+		log.Error(err, "Failed to list pods for PowerCappingConfig", "powerCappingConfig", req.NamespacedName)
+		return ctrl.Result{}, err
 	}
-	log.Info("Pod informer created")
-	// start the informer
-	go factory.Start(context.Background().Done())
-	log.Info("Pod informer started")
-	// Wait for the caches to be synced before starting the reconciler
-	if ok := cache.WaitForCacheSync(context.Background().Done(), r.PodInformer.HasSynced); !ok {
-		return fmt.Errorf("failed to wait for caches to sync")
-	}
-	log.Info("Cache synced")
-	promClient, err := prom_api.NewClient(prom_api.Config{
-		Address: PrometheusURL,
-	})
+
+	window := getSampleWindow(powerCappingConfig)
+	result := &configEvaluation{matched: len(pods)}
+	budgetName, budget, err := r.budgetAllocation(ctx, powerCappingConfig)
 	if err != nil {
+		log.Error(err, "Failed to get PowerBudget allocation", "powerCappingConfig", req.NamespacedName)
+	}
+	result.budgetName, result.budgetPowerCap = budgetName, budget
+	scheduleWindow, nextTransition := r.scheduleWindow(powerCappingConfig)
+	if scheduleWindow != nil {
+		result.scheduleWindow = scheduleWindow.Name
+	}
+	result.nextScheduleTransition = nextTransition
+	if powerCappingConfig.Spec.PowerCappingSpec.Kind == v1alpha1.CarbonAwarePowerCapInWatts {
+		if intensity, err := r.carbonIntensity(ctx, powerCappingConfig); err != nil {
+			log.Error(err, "Failed to get carbon intensity", "powerCappingConfig", req.NamespacedName)
+		} else {
+			result.carbonIntensity = intensity.Value
+		}
+	}
+	if result.forecast, err = r.forecastPower(ctx, powerCappingConfig, pods); err != nil {
+		log.Error(err, "Failed to forecast power", "powerCappingConfig", req.NamespacedName)
+		result.forecastFailure = err.Error()
+	}
+	proactive := proactiveForecast(powerCappingConfig, result.forecast)
+	powerCaps := make(map[types.UID]int, len(pods))
+	if powerCappingConfig.Spec.PowerCappingSpec.Scope == v1alpha1.PowerCappingScopeAggregate {
+		r.forgetAlerts(req.NamespacedName, nil)
+		result.aggregate, result.failed = r.enforceAggregatePowerCap(ctx, powerCappingConfig, pods, window, budget, proactive)
+		if result.aggregate != nil {
+			for _, share := range result.aggregate.pods {
+				powerCaps[share.pod.UID] = int(share.powerCap)
+			}
+		}
+	} else {
+		r.forgetAggregateAlert(req.NamespacedName)
+		active := make(map[types.UID]bool, len(pods))
+		result.pods = make([]podEvaluation, 0, len(pods))
+		// The pods split the budget of the config evenly.
+		podBudget := 0.0
+		if len(pods) > 0 {
+			podBudget = budget / float64(len(pods))
+		}
+		for i := range pods {
+			pod := &pods[i]
+			active[pod.UID] = true
+			evaluation, err := r.enforcePowerCap(ctx, powerCappingConfig, pod, window, podBudget, proactive)
+			if err != nil {
+				log.Error(err, "Failed to enforce power cap", "pod", pod.Name, "namespace", pod.Namespace)
+				result.failed++
+				continue
+			}
+			if evaluation != nil {
+				result.pods = append(result.pods, *evaluation)
+				powerCaps[pod.UID] = int(evaluation.powerCap)
+			}
+		}
+		r.forgetAlerts(req.NamespacedName, active)
+	}
+	r.watchCappedPods(powerCappingConfig, pods, powerCaps)
+
+	// Scale targets no longer referenced are restored, and so are all of them
+	// once the cap is lifted. Without a budget to divide, e.g. while no pod
+	// matches, the bounds already set are kept.
+	refs := scaleTargetRefs(powerCappingConfig)
+	reported, restore := reportedScaleTargets(powerCappingConfig, refs)
+	if !isPowerCapped(powerCappingConfig) && budget <= 0 {
+		restore = append(restore, refs...)
+		reported = nil
+	}
+	result.scaleTargets, result.actuationFailed = r.restoreScaleTargets(ctx, restore)
+	if budget := result.powerBudget(); budget.total() > 0 {
+		statuses, failed := r.enforceScaleTargets(ctx, powerCappingConfig, budget)
+		result.scaleTargets = append(result.scaleTargets, statuses...)
+		result.actuationFailed += failed
+	} else {
+		r.forgetRecommendations(req.NamespacedName)
+		result.scaleTargets = append(result.scaleTargets, reported...)
+	}
+
+	result.temperatures, result.temperatureFailed = r.enforceTemperatureThreshold(ctx, powerCappingConfig, pods, powerCaps)
+	if hasTemperatureThreshold(powerCappingConfig) {
+		window = min(window, getTemperatureSampleWindow(powerCappingConfig))
+	} else {
+		r.forgetTemperatureAlerts(req.NamespacedName, nil)
+	}
+	result.nodes, result.nodeFailed = r.enforceNodePowerCap(ctx, powerCappingConfig, pods)
+
+	if err := r.updateStatus(ctx, powerCappingConfig, result); err != nil {
+		log.Error(err, "Failed to update PowerCappingConfig status", "powerCappingConfig", req.NamespacedName)
+		return ctrl.Result{}, err
+	}
+
+	// Requeue at the next schedule boundary so that the cap changes on time.
+	if !nextTransition.IsZero() {
+		window = min(window, max(nextTransition.Sub(r.now()), time.Second))
+	}
+
+	return ctrl.Result{RequeueAfter: window}, nil
+}
+
+func (r *PowerCappingConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	log.Info("Setting up PowerCappingConfigReconciler")
+	if r.PrometheusClient == nil {
+		promClient, err := prom_api.NewClient(prom_api.Config{
+			Address: PrometheusURL,
+		})
+		if err != nil {
+			return err
+		}
+		r.PrometheusClient = prom_v1.NewAPI(promClient)
+		log.Info("Prometheus client created", "url", PrometheusURL)
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &powercappingv1alpha1.PowerCappingConfig{},
+		targetNamespaceIndex, indexTargetNamespace); err != nil {
 		return err
 	}
-	r.PrometheusClient = prom_v1.NewAPI(promClient)
-	log.Info("Prometheus client created", "url", PrometheusURL)
+
+	// Status writes do not bump the generation, so filtering on it keeps the
+	// controller from re-triggering itself on every evaluation.
+	r.recommendations = make(chan event.GenericEvent)
+	r.podWatchEvents = make(chan event.GenericEvent)
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&powercappingv1alpha1.PowerCappingConfig{}).
+		For(&powercappingv1alpha1.PowerCappingConfig{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(r.mapPodToConfigs), builder.WithPredicates(podTargetingChanged)).
+		Watches(&powercappingv1alpha1.PowerBudget{}, handler.EnqueueRequestsFromMapFunc(r.mapBudgetToConfigs)).
+		WatchesRawSource(&source.Channel{Source: r.recommendations}, &handler.EnqueueRequestForObject{}).
+		WatchesRawSource(&source.Channel{Source: r.podWatchEvents}, &handler.EnqueueRequestForObject{}).
 		Complete(r)
 }
 
-func (r *PowerCappingConfigReconciler) getKeplerMetrics(ctx context.Context, podName, device string) (float64, string, error) {
-	query := fmt.Sprintf(`kepler_container_%s_joules_total{pod='%s'}`, device, podName)
-	result, warnings, err := r.PrometheusClient.Query(ctx, query, time.Now())
+// enforcePowerCap evaluates a single pod against the config and alerts when
+// the computed power cap moved beyond the hysteresis band of the config since
+// the last alert. The cap is bounded
+// by budget, the part of the PowerBudget allocation of the config given to the
+// pod, when it is positive. With a proactive forecast, the pod is also taken
+// to exceed the cap when its share of the forecast does. It returns nil
+// without error when the config does not cap power and has no budget.
+func (r *PowerCappingConfigReconciler) enforcePowerCap(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, pod *corev1.Pod, window time.Duration, budget float64, proactive *powerForecast) (*podEvaluation, error) {
+	spec := &powerCappingConfig.Spec.PowerCappingSpec
+	if !isPowerCapped(powerCappingConfig) && budget <= 0 {
+		return nil, nil
+	}
+
+	evaluation, err := r.measurePodPower(ctx, powerCappingConfig, pod, window)
 	if err != nil {
-		return 0, "", err
-	}
-	if len(warnings) > 0 {
-		log.Info("Prometheus query warnings", "warnings", warnings)
-	}
-	if result.Type() == model.ValVector {
-		vector := result.(model.Vector)
-		if len(vector) > 0 {
-			for _, sample := range vector {
-				for labelName, labelValue := range sample.Metric {
-					if string(labelName) == device {
-						return float64(sample.Value), string(labelValue), nil
-					}
-				}
+		return nil, err
+	}
+	evaluation.pod = pod
+	key := client.ObjectKeyFromObject(powerCappingConfig)
+
+	switch spec.Kind {
+	case v1alpha1.AbsolutePowerCapInWatts:
+		evaluation.powerCap = float64(spec.PowerCapInWatts)
+	case v1alpha1.RelativePowerCapOfPeakPowerConsumptionInPercentage:
+		evaluation.powerCap = r.calculatePowerCap(evaluation.peakPower, r.getPowerCapPercentage(powerCappingConfig))
+	case v1alpha1.RelativePowerCappingOfAveragePowerConsumptionInPercentage:
+		evaluation.powerCap = r.calculatePowerCap(evaluation.averagePower, r.getPowerCapPercentage(powerCappingConfig))
+	case v1alpha1.CarbonAwarePowerCapInWatts:
+		intensity, err := r.carbonIntensity(ctx, powerCappingConfig)
+		if err != nil {
+			return nil, err
+		}
+		evaluation.powerCap = carbonAwarePowerCap(&spec.CarbonAwarePowerCapSpec, intensity.Value)
+	}
+	scheduleWindow, _ := r.scheduleWindow(powerCappingConfig)
+	evaluation.powerCap = scheduledPowerCap(scheduleWindow, evaluation.powerCap)
+	budgeted := budgetedPowerCap(powerCappingConfig, evaluation.powerCap, budget)
+	fixed := budgeted != evaluation.powerCap
+	evaluation.powerCap = budgeted
+	evaluation.followsPower = !fixed && followsPodPower(spec.Kind, scheduleWindow)
+	if proactive != nil {
+		evaluation.forecastPower = evaluation.currentPower * proactive.growth
+	}
+
+	// Absolute, carbon-aware and budgeted caps do not follow the power of the
+	// pod, so alert only while the pod exceeds the cap, and resolve the alert
+	// and re-arm it once the pod is back under the cap by the hysteresis
+	// band. A new cap, e.g. from a new schedule window or carbon intensity,
+	// is alerted again.
+	hysteresis := getHysteresis(powerCappingConfig)
+	if fixed || spec.Kind == v1alpha1.AbsolutePowerCapInWatts || spec.Kind == v1alpha1.CarbonAwarePowerCapInWatts {
+		if !evaluation.overCap() {
+			if evaluation.underCap(hysteresis) && r.forgetPodAlert(key, pod.UID) {
+				r.resolveAlert(ctx, pod, powerCappingConfig)
 			}
+			return evaluation, nil
 		}
 	}
-	return 0, "", fmt.Errorf("no data with device %s returned from Prometheus query", device)
+
+	powerCap := int(evaluation.powerCap)
+	log.Info("Power cap calculated", "pod", pod.Name, "kind", spec.Kind, "currentPower", evaluation.currentPower,
+		"peakPower", evaluation.peakPower, "averagePower", evaluation.averagePower, "forecastPower", evaluation.forecastPower,
+		"powerCap", powerCap)
+	if r.shouldAlert(key, pod.UID, powerCap, hysteresis) {
+		deviceLabels := r.getPodDevices(ctx, powerCappingConfig, pod)
+		if err := r.createAlert(pod, powerCap, deviceLabels, powerCappingConfig); err != nil {
+			log.Error(err, "Failed to create alert", "pod", pod.Name)
+		}
+	}
+	return evaluation, nil
 }
 
-func (r *PowerCappingConfigReconciler) handlePodAdd(obj interface{}) {
-	pod, ok := obj.(*corev1.Pod)
-	if !ok {
-		return
+// unsupportedKindMessage describes the kinds of the config that the controller
+// cannot evaluate, or returns an empty string when all of them are supported.
+func unsupportedKindMessage(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) string {
+	var unsupported []string
+	if kind := powerCappingConfig.Spec.PowerCappingSpec.Kind; !isSupportedPowerCappingKind(kind) {
+		unsupported = append(unsupported, fmt.Sprintf("power capping kind %q", kind))
 	}
-	powerCapLabel := pod.Labels[labelKey]
-	if powerCapLabel != "" {
-		// retrieve power cap crd using the label
-		// calculate power cap based on the peak power usage
-		powerCappingConfig := &powercappingv1alpha1.PowerCappingConfig{}
-		err := r.Get(context.Background(), client.ObjectKey{
-			Name:      powerCapLabel,
-			Namespace: pod.Namespace,
-		}, powerCappingConfig)
-		if err != nil {
-			if errors.IsNotFound(err) {
-				log.Error(err, fmt.Sprintf("PowerCappingConfig %s not found in ns %s", powerCapLabel, pod.Namespace))
-				return
+	if kind := powerCappingConfig.Spec.TemperatureThresholdSpec.Kind; !isSupportedTemperatureThresholdKind(kind) {
+		unsupported = append(unsupported, fmt.Sprintf("temperature threshold kind %q", kind))
+	}
+	if len(unsupported) == 0 {
+		return ""
+	}
+	return "Unsupported " + strings.Join(unsupported, " and ")
+}
+
+// isPowerCapped reports whether the config caps power.
+func isPowerCapped(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) bool {
+	kind := powerCappingConfig.Spec.PowerCappingSpec.Kind
+	return kind != "" && kind != v1alpha1.NoPowerCappingSpec
+}
+
+// followsPodPower reports whether the power cap of the kind is computed from
+// the power of the pods it caps, unless the schedule window sets it in watts.
+func followsPodPower(kind v1alpha1.PowerCappingSpecKind, window *powercappingv1alpha1.ScheduleWindow) bool {
+	if window != nil && window.PowerCapInWatts > 0 {
+		return false
+	}
+	return kind == v1alpha1.RelativePowerCapOfPeakPowerConsumptionInPercentage ||
+		kind == v1alpha1.RelativePowerCappingOfAveragePowerConsumptionInPercentage
+}
+
+// hasTemperatureThreshold reports whether the config sets a temperature threshold.
+func hasTemperatureThreshold(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) bool {
+	kind := powerCappingConfig.Spec.TemperatureThresholdSpec.Kind
+	return kind != "" && kind != v1alpha1.NoTemperatureThreshold
+}
+
+// isSupportedPowerCappingKind reports whether the controller knows how to
+// evaluate the power capping kind. An empty kind means no power capping.
+func isSupportedPowerCappingKind(kind v1alpha1.PowerCappingSpecKind) bool {
+	switch kind {
+	case "",
+		v1alpha1.NoPowerCappingSpec,
+		v1alpha1.AbsolutePowerCapInWatts,
+		v1alpha1.RelativePowerCapOfPeakPowerConsumptionInPercentage,
+		v1alpha1.RelativePowerCappingOfAveragePowerConsumptionInPercentage,
+		v1alpha1.CarbonAwarePowerCapInWatts:
+		return true
+	default:
+		return false
+	}
+}
+
+// measurePodPower reads the current, peak and average power of a pod over
+// the sample window.
+func (r *PowerCappingConfigReconciler) measurePodPower(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, pod *corev1.Pod, window time.Duration) (*podEvaluation, error) {
+	reading, err := r.measurePods(ctx, powerCappingConfig, []corev1.Pod{*pod}, window)
+	if err != nil {
+		return nil, err
+	}
+	return &podEvaluation{
+		currentPower: reading.Current,
+		peakPower:    reading.Peak,
+		averagePower: reading.Average,
+	}, nil
+}
+
+// updateStatus writes the outcome of an evaluation to the status subresource.
+func (r *PowerCappingConfigReconciler) updateStatus(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, result *configEvaluation) error {
+	patch := client.MergeFrom(powerCappingConfig.DeepCopy())
+	status := &powerCappingConfig.Status
+	matched, failed := result.matched, result.failed
+
+	// totalPowerCap is the power cap of all pods together, against which
+	// the forecast is compared.
+	var currentPower, peakPower, averagePower, powerCap, totalPowerCap float64
+	var capped []string
+	var shares []powercappingv1alpha1.PodPowerShare
+	if aggregate := result.aggregate; aggregate != nil {
+		currentPower = aggregate.total.currentPower
+		peakPower = aggregate.total.peakPower
+		averagePower = aggregate.total.averagePower
+		powerCap = aggregate.total.powerCap
+		totalPowerCap = powerCap
+		if aggregate.exceeded() {
+			capped = append(capped, fmt.Sprintf("all pods %.0fW > %.0fW", currentPower, powerCap))
+		}
+		shares = aggregate.shares()
+	} else {
+		for _, evaluation := range result.pods {
+			currentPower += evaluation.currentPower
+			peakPower = max(peakPower, evaluation.peakPower)
+			averagePower += evaluation.averagePower
+			powerCap = max(powerCap, evaluation.powerCap)
+			totalPowerCap += evaluation.powerCap
+			if evaluation.currentPower > evaluation.powerCap {
+				capped = append(capped, fmt.Sprintf("%s %.0fW > %.0fW", evaluation.pod.Name, evaluation.currentPower, evaluation.powerCap))
 			}
-			log.Error(err, fmt.Sprintf("Failed to get PowerCappingConfig: %s in ns %s", powerCapLabel, pod.Namespace))
-			return
 		}
+		if len(result.pods) > 0 {
+			averagePower /= float64(len(result.pods))
+		}
+	}
 
-		// fetch the observation window from the CRD
-		// watch the pod power usage for the observation window
-		switch powerCappingConfig.Spec.PowerCappingSpec.Kind {
-		case v1alpha1.RelativePowerCapOfPeakPowerConsumptionInPercentage:
-			log.Info("RelativePowerCapOfPeakPowerConsumptionInPercentage", "sampleWindow", powerCappingConfig.Spec.PowerCappingSpec.RelativePowerCapInPercentageSpec.SampleWindow)
-			duration := time.Duration(powerCappingConfig.Spec.PowerCappingSpec.RelativePowerCapInPercentageSpec.SampleWindow) * time.Second
-			go func() {
-				ctx, cancel := context.WithCancel(context.Background())
-				defer cancel()
-				log.Info("Watching pod power usage", "pod", pod.Name, "duration", duration)
-				peakPower, err := r.watchPodPowerUsage(ctx, pod.Name, duration)
-				if err != nil {
-					log.Error(err, "Failed to watch pod power usage")
-					return
-				}
-
-				powerCapPercentage := getPowerCapPercentage(powerCapLabel)
-				powerCap := r.calculatePowerCap(peakPower, powerCapPercentage)
-				deviceLabels := r.getPodDevices(pod)
-				r.createAlert(pod, int(powerCap), deviceLabels)
-			}()
+	temperatures, temperatureFailed := result.temperatures, result.temperatureFailed
+	var currentTemperature, temperatureThreshold float64
+	var hot []string
+	for i, temperature := range temperatures {
+		currentTemperature = max(currentTemperature, temperature.current)
+		if i == 0 || temperature.threshold < temperatureThreshold {
+			temperatureThreshold = temperature.threshold
+		}
+		if temperature.exceeded() {
+			hot = append(hot, fmt.Sprintf("%s %.0fC > %.0fC", temperature.node, temperature.current, temperature.threshold))
 		}
 	}
+
+	now := metav1.Now()
+	status.ObservedGeneration = powerCappingConfig.Generation
+	status.MatchedPods = int32(matched)
+	status.AlertedPods = r.alertedPods(powerCappingConfig)
+	status.LastEvaluationTime = &now
+	status.CurrentPowerConsumption = int(currentPower)
+	status.ForecastPowerConsumption = 0
+	if result.forecast != nil {
+		status.ForecastPowerConsumption = int(result.forecast.power)
+	}
+	status.PeakPowerConsumption = int(peakPower)
+	status.AveragePowerConsumption = int(averagePower)
+	status.PowerCapInWatts = int(powerCap)
+	status.CarbonIntensity = int(result.carbonIntensity)
+	status.PowerBudget = result.budgetName
+	status.BudgetPowerCapInWatts = int(result.budgetPowerCap)
+	status.ActiveScheduleWindow = result.scheduleWindow
+	status.NextScheduleTransitionTime = nil
+	if !result.nextScheduleTransition.IsZero() {
+		status.NextScheduleTransitionTime = &metav1.Time{Time: result.nextScheduleTransition}
+	}
+	status.PodPowerShares = shares
+	status.ScaleTargets = result.scaleTargets
+	status.Nodes = result.nodes
+	status.CurrentTemperatureInCelsius = int(currentTemperature)
+	status.TemperatureThresholdInCelsius = int(temperatureThreshold)
+
+	generation := powerCappingConfig.Generation
+	switch {
+	case matched == 0:
+		setCondition(status, generation, v1alpha1.ConditionMetricsAvailable, metav1.ConditionUnknown, "NoMatchingPods", "No running pods are targeted by the config")
+	case result.evaluated() == 0 && failed > 0:
+		setCondition(status, generation, v1alpha1.ConditionMetricsAvailable, metav1.ConditionFalse, "QueryFailed", "Power metrics could not be queried for any pod")
+	default:
+		setCondition(status, generation, v1alpha1.ConditionMetricsAvailable, metav1.ConditionTrue, "MetricsQueried", "Power metrics are available")
+	}
+
+	switch {
+	case failed > 0:
+		setCondition(status, generation, v1alpha1.ConditionDegraded, metav1.ConditionTrue, "EvaluationFailed",
+			fmt.Sprintf("%d of %d pods could not be evaluated", failed, matched))
+		setCondition(status, generation, v1alpha1.ConditionReady, metav1.ConditionFalse, "EvaluationFailed",
+			fmt.Sprintf("%d of %d pods could not be evaluated", failed, matched))
+	case result.actuationFailed > 0:
+		message := fmt.Sprintf("%d of %d scale targets could not be updated", result.actuationFailed, len(result.scaleTargets))
+		setCondition(status, generation, v1alpha1.ConditionDegraded, metav1.ConditionTrue, "ActuationFailed", message)
+		setCondition(status, generation, v1alpha1.ConditionReady, metav1.ConditionFalse, "ActuationFailed", message)
+	case temperatureFailed > 0:
+		message := fmt.Sprintf("Temperature could not be queried for %d of %d nodes", temperatureFailed, temperatureFailed+len(temperatures))
+		setCondition(status, generation, v1alpha1.ConditionDegraded, metav1.ConditionTrue, "TemperatureQueryFailed", message)
+		setCondition(status, generation, v1alpha1.ConditionReady, metav1.ConditionFalse, "TemperatureQueryFailed", message)
+	case result.nodeFailed > 0:
+		message := fmt.Sprintf("%d of %d nodes could not be measured or relieved", result.nodeFailed, len(result.nodes))
+		setCondition(status, generation, v1alpha1.ConditionDegraded, metav1.ConditionTrue, "NodePowerCapFailed", message)
+		setCondition(status, generation, v1alpha1.ConditionReady, metav1.ConditionFalse, "NodePowerCapFailed", message)
+	default:
+		setCondition(status, generation, v1alpha1.ConditionDegraded, metav1.ConditionFalse, "AsExpected", "All matched pods were evaluated")
+		setCondition(status, generation, v1alpha1.ConditionReady, metav1.ConditionTrue, "Evaluated", "The power capping policy was evaluated")
+	}
+
+	if len(capped) > 0 {
+		setCondition(status, generation, v1alpha1.ConditionCapped, metav1.ConditionTrue, "PowerCapExceeded",
+			fmt.Sprintf("The power cap is exceeded by %s", strings.Join(capped, ", ")))
+	} else {
+		setCondition(status, generation, v1alpha1.ConditionCapped, metav1.ConditionFalse, "WithinPowerCap", "No pod exceeds its power cap")
+	}
+
+	switch {
+	case !hasTemperatureThreshold(powerCappingConfig):
+		meta.RemoveStatusCondition(&status.Conditions, v1alpha1.ConditionTemperatureThresholdExceeded)
+	case len(hot) > 0:
+		setCondition(status, generation, v1alpha1.ConditionTemperatureThresholdExceeded, metav1.ConditionTrue, "TemperatureThresholdExceeded",
+			fmt.Sprintf("%d nodes exceed the temperature threshold: %s", len(hot), strings.Join(hot, ", ")))
+	case len(temperatures) == 0:
+		setCondition(status, generation, v1alpha1.ConditionTemperatureThresholdExceeded, metav1.ConditionUnknown, "NoTemperatureMetrics", "No node temperature was evaluated")
+	default:
+		setCondition(status, generation, v1alpha1.ConditionTemperatureThresholdExceeded, metav1.ConditionFalse, "WithinTemperatureThreshold", "No node exceeds the temperature threshold")
+	}
+
+	exceededNodes := nodePowerCapExceeded(powerCappingConfig, result.nodes)
+	switch {
+	case powerCappingConfig.Spec.NodePowerCap == nil:
+		meta.RemoveStatusCondition(&status.Conditions, v1alpha1.ConditionNodePowerCapExceeded)
+	case len(exceededNodes) > 0:
+		setCondition(status, generation, v1alpha1.ConditionNodePowerCapExceeded, metav1.ConditionTrue, "NodePowerCapExceeded",
+			fmt.Sprintf("%d nodes exceed the node power cap: %s", len(exceededNodes), strings.Join(exceededNodes, ", ")))
+	default:
+		setCondition(status, generation, v1alpha1.ConditionNodePowerCapExceeded, metav1.ConditionFalse, "WithinNodePowerCap", "No node exceeds the node power cap")
+	}
+
+	switch {
+	case powerCappingConfig.Spec.Forecast == nil:
+		meta.RemoveStatusCondition(&status.Conditions, v1alpha1.ConditionPowerCapForecastExceeded)
+	case result.forecastFailure != "":
+		setCondition(status, generation, v1alpha1.ConditionPowerCapForecastExceeded, metav1.ConditionUnknown, "ForecastFailed", result.forecastFailure)
+	case result.forecast == nil:
+		setCondition(status, generation, v1alpha1.ConditionPowerCapForecastExceeded, metav1.ConditionUnknown, "NoMatchingPods", "No power was forecast")
+	case totalPowerCap <= 0:
+		setCondition(status, generation, v1alpha1.ConditionPowerCapForecastExceeded, metav1.ConditionUnknown, "NoPowerCap", "No power cap was evaluated")
+	case result.forecast.power > totalPowerCap:
+		setCondition(status, generation, v1alpha1.ConditionPowerCapForecastExceeded, metav1.ConditionTrue, "PowerCapForecastExceeded",
+			fmt.Sprintf("The power of all pods is forecast at %.0fW, above their power cap of %.0fW", result.forecast.power, totalPowerCap))
+	default:
+		setCondition(status, generation, v1alpha1.ConditionPowerCapForecastExceeded, metav1.ConditionFalse, "ForecastWithinPowerCap",
+			fmt.Sprintf("The power of all pods is forecast at %.0fW, within their power cap of %.0fW", result.forecast.power, totalPowerCap))
+	}
+
+	return r.Status().Patch(ctx, powerCappingConfig, patch)
 }
 
-func (r *PowerCappingConfigReconciler) handlePodDelete(obj interface{}) {
-	// Handle pod deletion if needed
+// reportUnsupportedKind marks the config as not ready because one of its
+// kinds cannot be evaluated.
+func (r *PowerCappingConfigReconciler) reportUnsupportedKind(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, message string) error {
+	patch := client.MergeFrom(powerCappingConfig.DeepCopy())
+	status := &powerCappingConfig.Status
+	generation := powerCappingConfig.Generation
+
+	now := metav1.Now()
+	status.ObservedGeneration = generation
+	status.LastEvaluationTime = &now
+	setCondition(status, generation, v1alpha1.ConditionReady, metav1.ConditionFalse, "UnsupportedKind", message)
+	setCondition(status, generation, v1alpha1.ConditionDegraded, metav1.ConditionTrue, "UnsupportedKind", message)
+	setCondition(status, generation, v1alpha1.ConditionCapped, metav1.ConditionUnknown, "UnsupportedKind", message)
+
+	return r.Status().Patch(ctx, powerCappingConfig, patch)
 }
 
-func (r *PowerCappingConfigReconciler) getPodDevices(pod *corev1.Pod) map[string]string {
-	devices := make(map[string]string, 2)
-	ctx := context.Background()
-	for _, v := range []string{"package", "gpu"} {
-		_, label, err := r.getKeplerMetrics(ctx, pod.Name, v)
-		if err != nil {
-			log.Error(err, "Failed to get Kepler metrics")
-			return nil
-		}
-		devices[v] = label
+func setCondition(status *powercappingv1alpha1.PowerCappingConfigStatus, generation int64, conditionType string, conditionStatus metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// shouldAlert records powerCap as the latest cap alerted for the pod and
+// reports whether the pod was not alerted yet or the cap moved beyond the
+// hysteresis band of the previously alerted one.
+func (r *PowerCappingConfigReconciler) shouldAlert(key types.NamespacedName, uid types.UID, powerCap int, hysteresis float64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.alertedCaps == nil {
+		r.alertedCaps = make(map[types.NamespacedName]map[types.UID]int)
+	}
+	caps, ok := r.alertedCaps[key]
+	if !ok {
+		caps = make(map[types.UID]int)
+		r.alertedCaps[key] = caps
 	}
-	return devices
+	if last, ok := caps[uid]; ok && !movedBeyond(float64(last), float64(powerCap), hysteresis) {
+		return false
+	}
+	caps[uid] = powerCap
+	return true
 }
 
-func (r *PowerCappingConfigReconciler) createAlert(pod *corev1.Pod, powerCap int, deviceLabels map[string]string) error {
-	mockConfig := mockConfig.NewMockPowerCappingConfig()
+// forgetPodAlert drops the alert bookkeeping of a single pod so that the
+// next call to shouldAlert for it alerts again, and reports whether the pod
+// was alerted.
+func (r *PowerCappingConfigReconciler) forgetPodAlert(key types.NamespacedName, uid types.UID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.alertedCaps[key][uid]
+	delete(r.alertedCaps[key], uid)
+	return ok
+}
 
-	return r.AlertService.SendAlert(pod.Name, powerCap, deviceLabels, mockConfig)
+// forgetAlerts drops the alert bookkeeping of pods that are no longer
+// targeted by the config. A nil active set forgets the whole config.
+func (r *PowerCappingConfigReconciler) forgetAlerts(key types.NamespacedName, active map[types.UID]bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if active == nil {
+		delete(r.alertedCaps, key)
+		return
+	}
+	for uid := range r.alertedCaps[key] {
+		if !active[uid] {
+			delete(r.alertedCaps[key], uid)
+		}
+	}
+}
+
+func (r *PowerCappingConfigReconciler) createAlert(pod *corev1.Pod, powerCap int, deviceLabels map[string]string, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) error {
+	r.recordAlert(powerCappingConfig, pod.Name, true)
+	return r.AlertService.SendAlert(pod.Name, powerCap, deviceLabels, powerCappingConfig)
 }
 
 func getEnv(key, fallback string) string {
@@ -247,67 +774,37 @@ func getEnv(key, fallback string) string {
 	return value
 }
 
-func (r *PowerCappingConfigReconciler) watchPodPowerUsage(ctx context.Context, podName string, window time.Duration) (float64, error) {
-	ticker := time.NewTicker(window)
-	defer ticker.Stop()
-
-	peakPower := float64(0)
-
-	for {
-		select {
-		case <-ctx.Done():
-			return peakPower, nil
-		case <-ticker.C:
-			currentPower, err := r.queryPodPeakPower(ctx, podName, window.String())
-			if err != nil {
-				return 0, err
-			}
-			log.Info("Current power usage", "power", currentPower)
-			if currentPower > peakPower {
-				peakPower = currentPower
-			}
-			ticker.Stop()
-			return peakPower, nil
-		}
-	}
+// calculatePowerCap returns powerCapPercentage percent of the reference power,
+// which is the peak or the average power over the sample window.
+func (r *PowerCappingConfigReconciler) calculatePowerCap(referencePower float64, powerCapPercentage int) float64 {
+	return referencePower * float64(powerCapPercentage) / 100
 }
 
-func (r *PowerCappingConfigReconciler) queryPodPeakPower(ctx context.Context, podName, window string) (float64, error) {
-	// sample query: max_over_time(sum(rate(kepler_container_joules_total{pod_name=~"stress-7d796cb489-fbw68"}[1m]))[1m:])
-	query := fmt.Sprintf(`max_over_time(sum(rate(kepler_container_joules_total{pod_name=~"%s"}[1m]))[%s:])`, podName, window)
-	result, warnings, err := r.PrometheusClient.Query(ctx, query, time.Now())
-	if err != nil {
-		return 0, err
-	}
-	if len(warnings) > 0 {
-		log.Info("Prometheus query warnings", "warnings", warnings)
-	}
-	log.Info("Prometheus query result", "result", result)
-	vectorResult, ok := result.(model.Vector)
-	if !ok {
-		log.Info("Prometheus query result error", "result", result)
+// getSampleWindow returns the observation window of the config, falling back
+// to defaultSampleWindow when none is set.
+func getSampleWindow(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) time.Duration {
+	sampleWindow := powerCappingConfig.Spec.PowerCappingSpec.RelativePowerCapInPercentageSpec.SampleWindow
+	if sampleWindow <= 0 {
+		return defaultSampleWindow
 	}
-
-	for _, sample := range vectorResult {
-		log.Info("Prometheus query sample", "sample", sample)
-		return float64(sample.Value), nil
-	}
-	return 0, fmt.Errorf("no data returned from Prometheus query")
+	return time.Duration(sampleWindow) * time.Second
 }
 
-func (r *PowerCappingConfigReconciler) calculatePowerCap(peakPower float64, powerCapPercentage int) float64 {
-	return peakPower * float64(powerCapPercentage) / 100
+// getPowerCapPercentage prefers the explicit percentage of the spec and
+// otherwise derives it from the efficiency level.
+func (r *PowerCappingConfigReconciler) getPowerCapPercentage(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) int {
+	if percentage := powerCappingConfig.Spec.PowerCappingSpec.RelativePowerCapInPercentageSpec.PowerCapPercentage; percentage > 0 {
+		return percentage
+	}
+	return r.efficiencyLevelPercentage(powerCappingConfig)
 }
 
-func getPowerCapPercentage(label string) int {
-	switch label {
-	case "high":
-		return defaultPowerCapHigh
-	case "medium":
-		return defaultPowerCapMedium
-	case "low":
-		return defaultPowerCapLow
-	default:
-		return 0
+// efficiencyLevelPercentage returns the percentage of the efficiency level of
+// the config as currently configured on the operator.
+func (r *PowerCappingConfigReconciler) efficiencyLevelPercentage(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) int {
+	levels := config.DefaultEfficiencyLevels()
+	if r.EfficiencyLevels != nil {
+		levels = r.EfficiencyLevels.Load()
 	}
+	return levels.PowerCapPercentage(powerCappingConfig.Spec.EfficiencyLevel)
 }
@@ -22,12 +22,14 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+	"github.com/Climatik-Project/Climatik-Project/internal/config"
 )
 
 var _ = Describe("PowerCappingConfig Controller", func() {
@@ -65,6 +67,16 @@ var _ = Describe("PowerCappingConfig Controller", func() {
 
 			By("Cleanup the specific resource instance PowerCappingConfig")
 			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+			By("Reconciling the deletion to remove the finalizer")
+			controllerReconciler := &PowerCappingConfigReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			err = k8sClient.Get(ctx, typeNamespacedName, resource)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
 		})
 		It("should successfully reconcile the resource", func() {
 			By("Reconciling the created resource")
@@ -73,12 +85,109 @@ var _ = Describe("PowerCappingConfig Controller", func() {
 				Scheme: k8sClient.Scheme(),
 			}
 
-			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Requeueing after the default sample window")
+			Expect(result.RequeueAfter).To(Equal(defaultSampleWindow))
+
+			By("Recording the evaluation in the status")
+			resource := &powercappingv1alpha1.PowerCappingConfig{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.ObservedGeneration).To(Equal(resource.Generation))
+			Expect(resource.Status.MatchedPods).To(BeZero())
+			Expect(resource.Status.LastEvaluationTime).NotTo(BeNil())
+			Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, powercappingv1alpha1.ConditionReady)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(resource.Status.Conditions, powercappingv1alpha1.ConditionCapped)).To(BeTrue())
+		})
+
+		It("should report an unsupported power capping kind", func() {
+			resource := &powercappingv1alpha1.PowerCappingConfig{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.PowerCappingSpec.Kind = "Unknown"
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			controllerReconciler := &PowerCappingConfigReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
 				NamespacedName: typeNamespacedName,
 			})
 			Expect(err).NotTo(HaveOccurred())
-			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
-			// Example: If you expect a certain status condition after reconciliation, verify it here.
+			Expect(result.RequeueAfter).To(BeZero())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			ready := meta.FindStatusCondition(resource.Status.Conditions, powercappingv1alpha1.ConditionReady)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+			Expect(ready.Reason).To(Equal("UnsupportedKind"))
+		})
+	})
+
+	Context("When computing the power cap of a pod", func() {
+		ctx := context.Background()
+		pod := newTestPod("pod")
+
+		newConfig := func(spec powercappingv1alpha1.PowerCappingSpec) *powercappingv1alpha1.PowerCappingConfig {
+			return newTestConfig(powercappingv1alpha1.PowerCappingConfigSpec{PowerCappingSpec: spec})
+		}
+
+		It("should use the configured watts for AbsolutePowerCapInWatts", func() {
+			config := newConfig(powercappingv1alpha1.PowerCappingSpec{
+				Kind:                        powercappingv1alpha1.AbsolutePowerCapInWatts,
+				AbsolutePowerCapInWattsSpec: powercappingv1alpha1.AbsolutePowerCapInWattsSpec{PowerCapInWatts: 300},
+			})
+			evaluation, err := newTestReconciler().enforcePowerCap(ctx, config, &pod, defaultSampleWindow, 0, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(evaluation.powerCap).To(Equal(300.0))
+		})
+
+		It("should scale the peak power for the peak-based kind", func() {
+			config := newConfig(powercappingv1alpha1.PowerCappingSpec{
+				Kind:                             powercappingv1alpha1.RelativePowerCapOfPeakPowerConsumptionInPercentage,
+				RelativePowerCapInPercentageSpec: powercappingv1alpha1.RelativePowerCapInPercentageSpec{PowerCapPercentage: 50},
+			})
+			evaluation, err := newTestReconciler().enforcePowerCap(ctx, config, &pod, defaultSampleWindow, 0, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(evaluation.powerCap).To(Equal(200.0))
+		})
+
+		It("should scale the average power for the average-based kind", func() {
+			config := newConfig(powercappingv1alpha1.PowerCappingSpec{
+				Kind:                             powercappingv1alpha1.RelativePowerCappingOfAveragePowerConsumptionInPercentage,
+				RelativePowerCapInPercentageSpec: powercappingv1alpha1.RelativePowerCapInPercentageSpec{PowerCapPercentage: 50},
+			})
+			evaluation, err := newTestReconciler().enforcePowerCap(ctx, config, &pod, defaultSampleWindow, 0, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(evaluation.powerCap).To(Equal(100.0))
+		})
+
+		It("should follow the reloaded percentage of the efficiency level", func() {
+			powerCappingConfig := newConfig(powercappingv1alpha1.PowerCappingSpec{
+				Kind: powercappingv1alpha1.RelativePowerCapOfPeakPowerConsumptionInPercentage,
+			})
+			powerCappingConfig.Spec.EfficiencyLevel = powercappingv1alpha1.EfficiencyLevelHigh
+			levels := config.NewSharedEfficiencyLevels(config.EfficiencyLevels{High: 50, Medium: 40, Low: 30})
+			reconciler := newTestReconciler()
+			reconciler.EfficiencyLevels = levels
+			evaluation, err := reconciler.enforcePowerCap(ctx, powerCappingConfig, &pod, defaultSampleWindow, 0, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(evaluation.powerCap).To(Equal(200.0))
+
+			levels.Store(config.EfficiencyLevels{High: 25, Medium: 40, Low: 30})
+			evaluation, err = reconciler.enforcePowerCap(ctx, powerCappingConfig, &pod, defaultSampleWindow, 0, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(evaluation.powerCap).To(Equal(100.0))
+		})
+
+		It("should not evaluate configs without power capping", func() {
+			config := newConfig(powercappingv1alpha1.PowerCappingSpec{Kind: powercappingv1alpha1.NoPowerCappingSpec})
+			evaluation, err := newTestReconciler().enforcePowerCap(ctx, config, &pod, defaultSampleWindow, 0, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(evaluation).To(BeNil())
 		})
 	})
 })
@@ -0,0 +1,337 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+	"github.com/Climatik-Project/Climatik-Project/internal/actuator"
+	"github.com/Climatik-Project/Climatik-Project/internal/strategy"
+)
+
+// scaleTarget is a workload bounded by an actuator together with its
+// measured power consumption, in watts.
+type scaleTarget struct {
+	status          powercappingv1alpha1.ScaleTargetStatus
+	actuator        actuator.Actuator
+	workload        *actuator.Workload
+	rank            scaleTargetRank
+	pods            []string
+	power           float64
+	powerPerReplica float64
+}
+
+// scaleTargetRefs returns the ScaledObjects, workloads and autoscalers
+// referenced by the config.
+func scaleTargetRefs(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) []actuator.Reference {
+	spec := &powerCappingConfig.Spec
+	refs := make([]actuator.Reference, 0, len(spec.ScaledObjectRefs)+len(spec.ScaleTargetRefs))
+	for _, ref := range spec.ScaledObjectRefs {
+		kind := ref.Kind
+		if kind == "" {
+			kind = actuator.ScaledObjectKind
+		}
+		refs = append(refs, actuator.Reference{
+			APIVersion: ref.APIVersion,
+			Kind:       kind,
+			Namespace:  powerCappingConfig.Namespace,
+			Name:       ref.Metadata.Name,
+		})
+	}
+	for _, ref := range spec.ScaleTargetRefs {
+		refs = append(refs, actuator.Reference{
+			APIVersion: ref.APIVersion,
+			Kind:       ref.Kind,
+			Namespace:  powerCappingConfig.Namespace,
+			Name:       ref.Name,
+		})
+	}
+	return refs
+}
+
+// scaleTargetRank is the priority and weight of a scale target.
+type scaleTargetRank struct {
+	Priority int32
+	Weight   int32
+}
+
+// scaleTargetKey identifies a scale target within the namespace of a config.
+func scaleTargetKey(kind, name string) string {
+	return kind + "/" + name
+}
+
+// scaleTargetRanks returns the rank of every scale target referenced by the
+// config, keyed by scaleTargetKey.
+func scaleTargetRanks(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) map[string]scaleTargetRank {
+	spec := &powerCappingConfig.Spec
+	ranks := make(map[string]scaleTargetRank, len(spec.ScaledObjectRefs)+len(spec.ScaleTargetRefs))
+	for _, ref := range spec.ScaledObjectRefs {
+		kind := ref.Kind
+		if kind == "" {
+			kind = actuator.ScaledObjectKind
+		}
+		ranks[scaleTargetKey(kind, ref.Metadata.Name)] = scaleTargetRank{Priority: ref.Priority, Weight: ref.Weight}
+	}
+	for _, ref := range spec.ScaleTargetRefs {
+		ranks[scaleTargetKey(ref.Kind, ref.Name)] = scaleTargetRank{Priority: ref.Priority, Weight: ref.Weight}
+	}
+	return ranks
+}
+
+// reportedScaleTargets splits the scale targets reported in the status of the
+// config into those still referenced, whose statuses are returned, and those
+// no longer referenced, whose references are returned so that they can be
+// restored.
+func reportedScaleTargets(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, refs []actuator.Reference) ([]powercappingv1alpha1.ScaleTargetStatus, []actuator.Reference) {
+	referenced := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		referenced[scaleTargetKey(ref.Kind, ref.Name)] = true
+	}
+	var current []powercappingv1alpha1.ScaleTargetStatus
+	var stale []actuator.Reference
+	for _, status := range powerCappingConfig.Status.ScaleTargets {
+		if referenced[scaleTargetKey(status.Kind, status.Name)] {
+			current = append(current, status)
+			continue
+		}
+		stale = append(stale, actuator.Reference{
+			APIVersion: status.APIVersion,
+			Kind:       status.Kind,
+			Namespace:  powerCappingConfig.Namespace,
+			Name:       status.Name,
+		})
+	}
+	return current, stale
+}
+
+// scaleBudget is the power budget of the scale targets of a config, in watts,
+// split by whether it comes from caps following the power of the pods.
+type scaleBudget struct {
+	// fixed is the sum of the caps set in watts: absolute, carbon-aware,
+	// schedule window and PowerBudget caps.
+	fixed float64
+	// relative is the sum of the caps computed from the peak or average
+	// power of the pods.
+	relative float64
+}
+
+// add adds the power cap of the evaluation to the budget.
+func (b *scaleBudget) add(evaluation *podEvaluation) {
+	if evaluation.followsPower {
+		b.relative += evaluation.powerCap
+	} else {
+		b.fixed += evaluation.powerCap
+	}
+}
+
+// total returns the budget as measured.
+func (b scaleBudget) total() float64 {
+	return b.fixed + b.relative
+}
+
+// watts returns the budget divided between the targets. The relative part is
+// a percentage of the power of the running replicas, so dividing it by their
+// power per replica would bound the targets below their running replicas and
+// lower the bound again on every evaluation. It is therefore scaled from the
+// running replicas of the targets to their original replicas, those found
+// before the operator first bounded them.
+func (b scaleBudget) watts(targets []*scaleTarget) float64 {
+	if b.relative <= 0 {
+		return b.fixed
+	}
+	var running, original float64
+	for _, target := range targets {
+		running += target.power
+		original += float64(max(target.workload.MaxReplicas, target.status.Replicas)) * target.powerPerReplica
+	}
+	if running <= 0 {
+		return b.total()
+	}
+	return b.fixed + b.relative*original/running
+}
+
+// enforceScaleTargets allocates the power budget of the config between the
+// referenced scale targets with the strategy of the config and bounds their
+// replicas to their allocation, or to the replicas planned by the Planner
+// service when one is configured. It returns the status of every scale
+// target and the number that could not be updated.
+func (r *PowerCappingConfigReconciler) enforceScaleTargets(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, budget scaleBudget) ([]powercappingv1alpha1.ScaleTargetStatus, int) {
+	refs := scaleTargetRefs(powerCappingConfig)
+	if len(refs) == 0 {
+		return nil, 0
+	}
+
+	allocator, err := strategy.Get(powerCappingConfig.Spec.Strategy)
+	if err != nil {
+		log.Error(err, "Falling back to the default strategy", "powerCappingConfig", powerCappingConfig.Name)
+		allocator, _ = strategy.Get(strategy.Default)
+	}
+	ranks := scaleTargetRanks(powerCappingConfig)
+
+	targets := make([]*scaleTarget, 0, len(refs))
+	failed := 0
+	for _, ref := range refs {
+		target := &scaleTarget{
+			status: powercappingv1alpha1.ScaleTargetStatus{APIVersion: ref.APIVersion, Kind: ref.Kind, Name: ref.Name},
+		}
+		targets = append(targets, target)
+		var err error
+		if target.actuator, err = actuator.NewActuator(r.Client, ref); err == nil {
+			err = r.measureScaleTarget(ctx, powerCappingConfig, target)
+		}
+		if err != nil {
+			log.Error(err, "Failed to measure scale target", "kind", ref.Kind, "name", ref.Name)
+			target.status.Message = err.Error()
+			failed++
+		}
+	}
+
+	// Only the targets whose power per replica is known compete for the budget.
+	allocated := make([]*scaleTarget, 0, len(targets))
+	candidates := make([]strategy.Target, 0, len(targets))
+	for _, target := range targets {
+		if target.workload == nil {
+			continue
+		}
+		if target.powerPerReplica <= 0 {
+			target.status.Message = "No power is reported for the scale target"
+			continue
+		}
+		rank := ranks[scaleTargetKey(target.status.Kind, target.status.Name)]
+		target.rank = rank
+		allocated = append(allocated, target)
+		candidates = append(candidates, strategy.Target{
+			Name:            target.status.Name,
+			Power:           target.power,
+			PowerPerReplica: target.powerPerReplica,
+			MinReplicas:     target.workload.MinReplicas,
+			MaxReplicas:     target.workload.MaxReplicas,
+			Priority:        rank.Priority,
+			Weight:          rank.Weight,
+		})
+	}
+	watts := budget.watts(allocated)
+	var replicas []int32
+	if len(candidates) > 0 {
+		replicas = allocator.Allocate(watts, candidates)
+	}
+
+	planned := r.planReplicas(ctx, powerCappingConfig, allocated, watts)
+	for i, target := range allocated {
+		maxReplicas := replicas[i]
+		if plannedReplicas, ok := planned[types.NamespacedName{Namespace: target.workload.Namespace, Name: target.workload.Name}]; ok {
+			maxReplicas = max(plannedReplicas, target.workload.MinReplicas, 1)
+		}
+		target.status.PowerBudgetInWatts = int(float64(maxReplicas) * target.powerPerReplica)
+		if err := target.actuator.LimitReplicas(ctx, maxReplicas); err != nil {
+			log.Error(err, "Failed to limit replicas", "kind", target.status.Kind, "name", target.status.Name)
+			target.status.Message = err.Error()
+			failed++
+			continue
+		}
+		log.Info("Replicas limited", "kind", target.status.Kind, "name", target.status.Name, "maxReplicas", maxReplicas,
+			"powerPerReplica", target.powerPerReplica, "budget", watts)
+		target.status.MaxReplicas = maxReplicas
+	}
+
+	statuses := make([]powercappingv1alpha1.ScaleTargetStatus, 0, len(targets))
+	for _, target := range targets {
+		statuses = append(statuses, target.status)
+	}
+	return statuses, failed
+}
+
+// restoreScaleTargets restores the original replicas of the scale targets. It
+// returns the status of those that could not be restored, so that they stay
+// reported and are retried, and their number.
+func (r *PowerCappingConfigReconciler) restoreScaleTargets(ctx context.Context, refs []actuator.Reference) ([]powercappingv1alpha1.ScaleTargetStatus, int) {
+	var statuses []powercappingv1alpha1.ScaleTargetStatus
+	for _, ref := range refs {
+		target, err := actuator.NewActuator(r.Client, ref)
+		if err == nil {
+			err = target.Restore(ctx)
+		}
+		if err != nil {
+			log.Error(err, "Failed to restore replicas", "kind", ref.Kind, "name", ref.Name)
+			statuses = append(statuses, powercappingv1alpha1.ScaleTargetStatus{
+				APIVersion: ref.APIVersion,
+				Kind:       ref.Kind,
+				Name:       ref.Name,
+				Message:    err.Error(),
+			})
+			continue
+		}
+		log.Info("Replicas restored", "kind", ref.Kind, "name", ref.Name)
+	}
+	return statuses, len(statuses)
+}
+
+// measureScaleTarget resolves the workload of the target and queries the
+// current power of its running pods.
+func (r *PowerCappingConfigReconciler) measureScaleTarget(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, target *scaleTarget) error {
+	workload, err := target.actuator.Workload(ctx)
+	if err != nil {
+		return err
+	}
+	target.workload = workload
+	target.status.MaxReplicas = workload.MaxReplicas
+
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(workload.Namespace), client.MatchingLabelsSelector{Selector: workload.Selector}); err != nil {
+		return err
+	}
+	pods := make([]corev1.Pod, 0, len(podList.Items))
+	for _, pod := range podList.Items {
+		if pod.DeletionTimestamp == nil && pod.Status.Phase == corev1.PodRunning {
+			pods = append(pods, pod)
+		}
+	}
+	target.status.Replicas = int32(len(pods))
+	if len(pods) == 0 {
+		return nil
+	}
+	for _, pod := range pods {
+		target.pods = append(target.pods, pod.Name)
+	}
+
+	power, err := r.measureCurrentPower(ctx, powerCappingConfig, pods...)
+	if err != nil {
+		return err
+	}
+	target.power = power
+	target.powerPerReplica = power / float64(len(pods))
+	target.status.PowerPerReplicaInWatts = int(target.powerPerReplica)
+	return nil
+}
+
+// powerBudget returns the power available to the workloads of the config:
+// the aggregate cap in Aggregate scope, otherwise the sum of the pod caps.
+func (e *configEvaluation) powerBudget() scaleBudget {
+	var budget scaleBudget
+	if e.aggregate != nil {
+		budget.add(&e.aggregate.total)
+		return budget
+	}
+	for i := range e.pods {
+		budget.add(&e.pods[i])
+	}
+	return budget
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+)
+
+var _ = Describe("PowerCappingConfig Controller", func() {
+	Context("When bounding scale targets", func() {
+		ctx := context.Background()
+		podLabels := map[string]string{"app": "llm"}
+
+		newDeployment := func(name string, replicas *int32, podLabels map[string]string) *appsv1.Deployment {
+			return &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: replicas,
+					Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+				},
+			}
+		}
+		// newSteadyReconciler returns a reconciler of objs and of the given
+		// number of running pods of the llm-server, each steadily drawing 250W.
+		newSteadyReconciler := func(pods int, objs ...client.Object) *PowerCappingConfigReconciler {
+			power := make(map[string]float64, pods)
+			for i := 0; i < pods; i++ {
+				pod := newRunningPod(fmt.Sprintf("llm-server-%d", i), podLabels)
+				power[pod.Name] = 250
+				objs = append(objs, pod)
+			}
+			reconciler := newTestReconciler(objs...)
+			reconciler.PrometheusClient = &fakePodPower{power: power}
+			return reconciler
+		}
+		// newRelativeConfig returns a config capping the pods of the
+		// llm-server at 80% of their peak power.
+		newRelativeConfig := func() *powercappingv1alpha1.PowerCappingConfig {
+			return newTestConfig(powercappingv1alpha1.PowerCappingConfigSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+				PowerCappingSpec: powercappingv1alpha1.PowerCappingSpec{
+					Kind:                             powercappingv1alpha1.RelativePowerCapOfPeakPowerConsumptionInPercentage,
+					RelativePowerCapInPercentageSpec: powercappingv1alpha1.RelativePowerCapInPercentageSpec{PowerCapPercentage: 80},
+				},
+			})
+		}
+		// evaluate reconciles the config, stops the running pods beyond the
+		// replicas bounded by the config and returns the bound.
+		evaluate := func(reconciler *PowerCappingConfigReconciler, config *powercappingv1alpha1.PowerCappingConfig) int32 {
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(config)})
+			Expect(err).NotTo(HaveOccurred())
+			updated := &powercappingv1alpha1.PowerCappingConfig{}
+			Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(config), updated)).To(Succeed())
+			Expect(updated.Status.ScaleTargets).To(HaveLen(1))
+			bound := updated.Status.ScaleTargets[0].MaxReplicas
+
+			pods := &corev1.PodList{}
+			Expect(reconciler.List(ctx, pods, client.MatchingLabels(podLabels))).To(Succeed())
+			for i := int(bound); i < len(pods.Items); i++ {
+				Expect(reconciler.Delete(ctx, &pods.Items[i])).To(Succeed())
+			}
+			return bound
+		}
+
+		It("should set maxReplicaCount to the replicas that fit in the budget", func() {
+			reconciler := newTestReconciler(
+				newScaledObject("llm", "llm-server"),
+				newDeployment("llm-server", nil, podLabels),
+				newRunningPod("llm-server-a", podLabels),
+				newRunningPod("llm-server-b", podLabels),
+			)
+			config := newTestConfig(powercappingv1alpha1.PowerCappingConfigSpec{
+				ScaledObjectRefs: []powercappingv1alpha1.ScaledObjectReference{
+					{Metadata: powercappingv1alpha1.ScaledObjectMetadata{Name: "llm"}},
+				},
+			})
+
+			statuses, failed := reconciler.enforceScaleTargets(ctx, config, scaleBudget{fixed: 500})
+			Expect(failed).To(BeZero())
+			Expect(statuses).To(HaveLen(1))
+			Expect(statuses[0].Kind).To(Equal("ScaledObject"))
+			Expect(statuses[0].Replicas).To(Equal(int32(2)))
+			Expect(statuses[0].PowerPerReplicaInWatts).To(Equal(125))
+			Expect(statuses[0].MaxReplicas).To(Equal(int32(4)))
+
+			scaledObject := &unstructured.Unstructured{}
+			scaledObject.SetGroupVersionKind(scaledObjectGVK)
+			Expect(reconciler.Get(ctx, types.NamespacedName{Name: "llm", Namespace: "default"}, scaledObject)).To(Succeed())
+			maxReplicas, _, _ := unstructured.NestedInt64(scaledObject.Object, "spec", "maxReplicaCount")
+			Expect(maxReplicas).To(Equal(int64(4)))
+		})
+
+		It("should keep maxReplicaCount steady under a relative cap across evaluations", func() {
+			config := newRelativeConfig()
+			config.Spec.ScaledObjectRefs = []powercappingv1alpha1.ScaledObjectReference{
+				{Metadata: powercappingv1alpha1.ScaledObjectMetadata{Name: "llm"}},
+			}
+			reconciler := newSteadyReconciler(5, config,
+				newScaledObject("llm", "llm-server"),
+				newDeployment("llm-server", nil, podLabels),
+			)
+
+			// The cap is 80% of the 10 replicas allowed before the first bound.
+			for i := 0; i < 4; i++ {
+				Expect(evaluate(reconciler, config)).To(Equal(int32(8)), "evaluation %d", i)
+			}
+		})
+
+		It("should report ScaledObjects that cannot be found", func() {
+			config := newTestConfig(powercappingv1alpha1.PowerCappingConfigSpec{
+				ScaledObjectRefs: []powercappingv1alpha1.ScaledObjectReference{
+					{Metadata: powercappingv1alpha1.ScaledObjectMetadata{Name: "missing"}},
+				},
+			})
+			statuses, failed := newTestReconciler().enforceScaleTargets(ctx, config, scaleBudget{fixed: 500})
+			Expect(failed).To(Equal(1))
+			Expect(statuses[0].Message).NotTo(BeEmpty())
+		})
+
+		It("should bound and restore the replicas of a Deployment", func() {
+			replicas := int32(6)
+			reconciler := newTestReconciler(
+				newDeployment("llm-server", &replicas, podLabels),
+				newRunningPod("llm-server-a", podLabels),
+			)
+			config := newTestConfig(powercappingv1alpha1.PowerCappingConfigSpec{
+				ScaleTargetRefs: []powercappingv1alpha1.ScaleTargetReference{{Kind: "Deployment", Name: "llm-server"}},
+			})
+			key := types.NamespacedName{Name: "llm-server", Namespace: "default"}
+
+			statuses, failed := reconciler.enforceScaleTargets(ctx, config, scaleBudget{fixed: 500})
+			Expect(failed).To(BeZero())
+			Expect(statuses[0].MaxReplicas).To(Equal(int32(2)))
+			deployment := &appsv1.Deployment{}
+			Expect(reconciler.Get(ctx, key, deployment)).To(Succeed())
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(2)))
+
+			statuses, failed = reconciler.restoreScaleTargets(ctx, scaleTargetRefs(config))
+			Expect(failed).To(BeZero())
+			Expect(statuses).To(BeEmpty())
+			deployment = &appsv1.Deployment{}
+			Expect(reconciler.Get(ctx, key, deployment)).To(Succeed())
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(6)))
+		})
+
+		It("should keep the replicas of a Deployment steady under a relative cap across evaluations", func() {
+			replicas := int32(5)
+			config := newRelativeConfig()
+			config.Spec.ScaleTargetRefs = []powercappingv1alpha1.ScaleTargetReference{{Kind: "Deployment", Name: "llm-server"}}
+			reconciler := newSteadyReconciler(5, config, newDeployment("llm-server", &replicas, podLabels))
+
+			// The cap is 80% of the 5 replicas set before the first bound.
+			for i := 0; i < 4; i++ {
+				Expect(evaluate(reconciler, config)).To(Equal(int32(4)), "evaluation %d", i)
+				deployment := &appsv1.Deployment{}
+				Expect(reconciler.Get(ctx, types.NamespacedName{Name: "llm-server", Namespace: "default"}, deployment)).To(Succeed())
+				Expect(*deployment.Spec.Replicas).To(Equal(int32(4)))
+			}
+		})
+
+		It("should restore scale targets no longer referenced", func() {
+			config := newTestConfig(powercappingv1alpha1.PowerCappingConfigSpec{
+				ScaleTargetRefs: []powercappingv1alpha1.ScaleTargetReference{{Kind: "StatefulSet", Name: "kept"}},
+			})
+			config.Status.ScaleTargets = []powercappingv1alpha1.ScaleTargetStatus{
+				{Kind: "StatefulSet", Name: "kept", MaxReplicas: 3},
+				{Kind: "HorizontalPodAutoscaler", Name: "removed", MaxReplicas: 2},
+			}
+			reported, stale := reportedScaleTargets(config, scaleTargetRefs(config))
+			Expect(reported).To(HaveLen(1))
+			Expect(reported[0].Name).To(Equal("kept"))
+			Expect(stale).To(HaveLen(1))
+			Expect(stale[0].Name).To(Equal("removed"))
+			Expect(stale[0].Namespace).To(Equal("default"))
+		})
+
+		It("should allocate replicas with the strategy of the config", func() {
+			chatLabels := map[string]string{"app": "chat"}
+			reconciler := newTestReconciler(
+				newDeployment("llm-server", nil, podLabels),
+				newDeployment("chat-server", nil, chatLabels),
+				newRunningPod("llm-server-a", podLabels),
+				newRunningPod("chat-server-a", chatLabels),
+			)
+			config := newTestConfig(powercappingv1alpha1.PowerCappingConfigSpec{
+				Strategy: powercappingv1alpha1.StrategyPriority,
+				ScaleTargetRefs: []powercappingv1alpha1.ScaleTargetReference{
+					{Kind: "Deployment", Name: "llm-server", Priority: 1},
+					{Kind: "Deployment", Name: "chat-server", Priority: 10},
+				},
+			})
+
+			statuses, failed := reconciler.enforceScaleTargets(ctx, config, scaleBudget{fixed: 1000})
+			Expect(failed).To(BeZero())
+			Expect(statuses).To(HaveLen(2))
+			Expect(statuses[0].MaxReplicas).To(Equal(int32(1)))
+			Expect(statuses[0].PowerBudgetInWatts).To(Equal(250))
+			Expect(statuses[1].MaxReplicas).To(Equal(int32(3)))
+		})
+	})
+})
@@ -0,0 +1,53 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	"time"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+)
+
+// now returns the current time of the reconciler.
+func (r *PowerCappingConfigReconciler) now() time.Time {
+	if r.clock != nil {
+		return r.clock()
+	}
+	return time.Now()
+}
+
+// scheduleWindow returns the schedule window of the config active now, or nil
+// when none is, along with the time at which the next window starts or the
+// active one ends. The time is zero when the config has no schedule.
+func (r *PowerCappingConfigReconciler) scheduleWindow(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) (*powercappingv1alpha1.ScheduleWindow, time.Time) {
+	if powerCappingConfig.Spec.Schedule == nil {
+		return nil, time.Time{}
+	}
+	return powerCappingConfig.Spec.Schedule.ActiveWindow(r.now())
+}
+
+// scheduledPowerCap returns the power cap in effect during the window: the
+// cap of the window when it sets one, or the power cap scaled by the window.
+func scheduledPowerCap(window *powercappingv1alpha1.ScheduleWindow, powerCap float64) float64 {
+	switch {
+	case window == nil:
+		return powerCap
+	case window.PowerCapInWatts > 0:
+		return float64(window.PowerCapInWatts)
+	default:
+		return powerCap * float64(window.PowerCapPercentage) / 100
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+)
+
+var _ = Describe("PowerCappingConfig Controller", func() {
+	Context("When computing the power cap of a pod", func() {
+		ctx := context.Background()
+		pod := newTestPod("pod")
+
+		newConfig := func(spec powercappingv1alpha1.PowerCappingSpec, windows ...powercappingv1alpha1.ScheduleWindow) *powercappingv1alpha1.PowerCappingConfig {
+			config := newTestConfig(powercappingv1alpha1.PowerCappingConfigSpec{PowerCappingSpec: spec})
+			config.Spec.Schedule = &powercappingv1alpha1.PowerCappingSchedule{Windows: windows}
+			return config
+		}
+
+		It("should apply the active schedule window to the power cap", func() {
+			config := newConfig(powercappingv1alpha1.PowerCappingSpec{
+				Kind:                        powercappingv1alpha1.AbsolutePowerCapInWatts,
+				AbsolutePowerCapInWattsSpec: powercappingv1alpha1.AbsolutePowerCapInWattsSpec{PowerCapInWatts: 300},
+			},
+				powercappingv1alpha1.ScheduleWindow{Name: "peak", Start: "16:00", End: "21:00", PowerCapPercentage: 50},
+				powercappingv1alpha1.ScheduleWindow{Name: "night", Start: "22:00", End: "06:00", PowerCapInWatts: 400},
+			)
+			reconciler := newTestReconciler()
+
+			reconciler.clock = func() time.Time { return time.Date(2024, time.May, 6, 17, 0, 0, 0, time.UTC) }
+			evaluation, err := reconciler.enforcePowerCap(ctx, config, &pod, defaultSampleWindow, 0, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(evaluation.powerCap).To(Equal(150.0))
+
+			reconciler.clock = func() time.Time { return time.Date(2024, time.May, 6, 23, 0, 0, 0, time.UTC) }
+			evaluation, err = reconciler.enforcePowerCap(ctx, config, &pod, defaultSampleWindow, 0, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(evaluation.powerCap).To(Equal(400.0))
+
+			reconciler.clock = func() time.Time { return time.Date(2024, time.May, 6, 12, 0, 0, 0, time.UTC) }
+			evaluation, err = reconciler.enforcePowerCap(ctx, config, &pod, defaultSampleWindow, 0, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(evaluation.powerCap).To(Equal(300.0))
+		})
+
+		It("should requeue at the next schedule boundary and report the active window", func() {
+			config := newConfig(powercappingv1alpha1.PowerCappingSpec{Kind: powercappingv1alpha1.NoPowerCappingSpec},
+				powercappingv1alpha1.ScheduleWindow{Name: "peak", Start: "16:00", End: "21:00", PowerCapPercentage: 50},
+			)
+			reconciler := newTestReconciler(config)
+			reconciler.clock = func() time.Time { return time.Date(2024, time.May, 6, 20, 59, 30, 0, time.UTC) }
+
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(config)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(30 * time.Second))
+
+			updated := &powercappingv1alpha1.PowerCappingConfig{}
+			Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(config), updated)).To(Succeed())
+			Expect(updated.Status.ActiveScheduleWindow).To(Equal("peak"))
+			Expect(updated.Status.NextScheduleTransitionTime.Time.Equal(time.Date(2024, time.May, 6, 21, 0, 0, 0, time.UTC))).To(BeTrue())
+		})
+	})
+})
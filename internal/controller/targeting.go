@@ -0,0 +1,173 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	"context"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+)
+
+const (
+	// targetNamespaceIndex indexes PowerCappingConfigs by the namespace whose
+	// pods they target, or allNamespaces when they use a namespace selector.
+	targetNamespaceIndex = "spec.targetNamespace"
+	allNamespaces        = "*"
+)
+
+// indexTargetNamespace is the IndexerFunc of targetNamespaceIndex.
+func indexTargetNamespace(obj client.Object) []string {
+	powerCappingConfig, ok := obj.(*powercappingv1alpha1.PowerCappingConfig)
+	if !ok {
+		return nil
+	}
+	if powerCappingConfig.Spec.NamespaceSelector != nil {
+		return []string{allNamespaces}
+	}
+	return []string{powerCappingConfig.Namespace}
+}
+
+// podSelector returns the pod selector of the config. Configs without a
+// selector target the pods labelled with their name.
+func podSelector(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) (labels.Selector, error) {
+	if powerCappingConfig.Spec.Selector == nil {
+		return labels.SelectorFromSet(labels.Set{labelKey: powerCappingConfig.Name}), nil
+	}
+	return metav1.LabelSelectorAsSelector(powerCappingConfig.Spec.Selector)
+}
+
+// targetNamespaces returns the namespaces whose pods the config targets.
+func (r *PowerCappingConfigReconciler) targetNamespaces(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) ([]string, error) {
+	if powerCappingConfig.Spec.NamespaceSelector == nil {
+		return []string{powerCappingConfig.Namespace}, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(powerCappingConfig.Spec.NamespaceSelector)
+	if err != nil {
+		return nil, err
+	}
+	namespaceList := &corev1.NamespaceList{}
+	if err := r.List(ctx, namespaceList, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	namespaces := make([]string, 0, len(namespaceList.Items))
+	for _, namespace := range namespaceList.Items {
+		namespaces = append(namespaces, namespace.Name)
+	}
+	return namespaces, nil
+}
+
+// listTargetPods returns the running pods selected by the config.
+func (r *PowerCappingConfigReconciler) listTargetPods(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) ([]corev1.Pod, error) {
+	selector, err := podSelector(powerCappingConfig)
+	if err != nil {
+		return nil, err
+	}
+	namespaces, err := r.targetNamespaces(ctx, powerCappingConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var pods []corev1.Pod
+	for _, namespace := range namespaces {
+		podList := &corev1.PodList{}
+		err := r.List(ctx, podList,
+			client.InNamespace(namespace),
+			client.MatchingLabelsSelector{Selector: selector},
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, pod := range podList.Items {
+			if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+				continue
+			}
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+// mapPodToConfigs enqueues the PowerCappingConfigs that select the pod.
+func (r *PowerCappingConfigReconciler) mapPodToConfigs(ctx context.Context, obj client.Object) []reconcile.Request {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return nil
+	}
+
+	var candidates []powercappingv1alpha1.PowerCappingConfig
+	for _, value := range []string{pod.Namespace, allNamespaces} {
+		configList := &powercappingv1alpha1.PowerCappingConfigList{}
+		if err := r.List(ctx, configList, client.MatchingFields{targetNamespaceIndex: value}); err != nil {
+			log.Error(err, "Failed to list PowerCappingConfigs for pod", "pod", pod.Name, "namespace", pod.Namespace)
+			return nil
+		}
+		candidates = append(candidates, configList.Items...)
+	}
+
+	var namespace *corev1.Namespace
+	var requests []reconcile.Request
+	for i := range candidates {
+		powerCappingConfig := &candidates[i]
+		selector, err := podSelector(powerCappingConfig)
+		if err != nil || !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		if powerCappingConfig.Spec.NamespaceSelector != nil {
+			if namespace == nil {
+				namespace = &corev1.Namespace{}
+				if err := r.Get(ctx, client.ObjectKey{Name: pod.Namespace}, namespace); err != nil {
+					log.Error(err, "Failed to get namespace of pod", "pod", pod.Name, "namespace", pod.Namespace)
+					return requests
+				}
+			}
+			namespaceSelector, err := metav1.LabelSelectorAsSelector(powerCappingConfig.Spec.NamespaceSelector)
+			if err != nil || !namespaceSelector.Matches(labels.Set(namespace.Labels)) {
+				continue
+			}
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(powerCappingConfig)})
+	}
+	return requests
+}
+
+// podTargetingChanged lets through the pod events that can change the set of
+// pods targeted by a config or the nodes they run on. Other status updates are
+// frequent and are covered by the periodic requeue.
+var podTargetingChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldPod, ok := e.ObjectOld.(*corev1.Pod)
+		if !ok {
+			return false
+		}
+		newPod, ok := e.ObjectNew.(*corev1.Pod)
+		if !ok {
+			return false
+		}
+		return !reflect.DeepEqual(oldPod.Labels, newPod.Labels) ||
+			oldPod.Status.Phase != newPod.Status.Phase ||
+			oldPod.Spec.NodeName != newPod.Spec.NodeName ||
+			(oldPod.DeletionTimestamp == nil) != (newPod.DeletionTimestamp == nil)
+	},
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+)
+
+var _ = Describe("PowerCappingConfig Controller", func() {
+	Context("When mapping pods to configs", func() {
+		ctx := context.Background()
+
+		newPod := func(namespace string, podLabels map[string]string) *corev1.Pod {
+			pod := newRunningPod("pod", podLabels)
+			pod.Namespace = namespace
+			return pod
+		}
+
+		It("should map pods to configs by selector and namespace selector", func() {
+			reconciler := newTestReconciler(
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"power": "capped"}}},
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+				&powercappingv1alpha1.PowerCappingConfig{
+					ObjectMeta: metav1.ObjectMeta{Name: "by-selector", Namespace: "team-a"},
+					Spec: powercappingv1alpha1.PowerCappingConfigSpec{
+						Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "llm"}},
+					},
+				},
+				&powercappingv1alpha1.PowerCappingConfig{
+					ObjectMeta: metav1.ObjectMeta{Name: "by-namespace", Namespace: "default"},
+					Spec: powercappingv1alpha1.PowerCappingConfigSpec{
+						Selector:          &metav1.LabelSelector{MatchLabels: map[string]string{"app": "llm"}},
+						NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"power": "capped"}},
+					},
+				},
+				&powercappingv1alpha1.PowerCappingConfig{
+					ObjectMeta: metav1.ObjectMeta{Name: "by-label", Namespace: "team-a"},
+				},
+			)
+
+			requests := reconciler.mapPodToConfigs(ctx, newPod("team-a", map[string]string{"app": "llm"}))
+			Expect(requests).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "by-selector", Namespace: "team-a"}},
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "by-namespace", Namespace: "default"}},
+			))
+
+			requests = reconciler.mapPodToConfigs(ctx, newPod("team-a", map[string]string{labelKey: "by-label"}))
+			Expect(requests).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "by-label", Namespace: "team-a"}},
+			))
+
+			Expect(reconciler.mapPodToConfigs(ctx, newPod("team-b", map[string]string{"app": "llm"}))).To(BeEmpty())
+		})
+
+		It("should list the running pods of all selected namespaces", func() {
+			running := newPod("team-a", map[string]string{"app": "llm"})
+			pending := newPod("team-b", map[string]string{"app": "llm"})
+			pending.Name = "pending"
+			pending.Status.Phase = corev1.PodPending
+			reconciler := newTestReconciler(
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+				running, pending,
+			)
+			config := newTestConfig(powercappingv1alpha1.PowerCappingConfigSpec{
+				Selector:          &metav1.LabelSelector{MatchLabels: map[string]string{"app": "llm"}},
+				NamespaceSelector: &metav1.LabelSelector{},
+			})
+
+			pods, err := reconciler.listTargetPods(ctx, config)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pods).To(HaveLen(1))
+			Expect(pods[0].Namespace).To(Equal("team-a"))
+		})
+	})
+})
@@ -0,0 +1,238 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+)
+
+// nodeTemperature holds the temperatures measured for a node, in celsius.
+type nodeTemperature struct {
+	node      string
+	current   float64
+	peak      float64
+	average   float64
+	threshold float64
+}
+
+func (t *nodeTemperature) exceeded() bool {
+	return t.current > t.threshold
+}
+
+// cooled reports whether the temperature is back under the threshold by the
+// hysteresis band. Between the threshold and the band an alert raised for
+// exceeding the threshold is kept rather than resolved.
+func (t *nodeTemperature) cooled(hysteresis float64) bool {
+	return t.current <= t.threshold*(1-hysteresis)
+}
+
+// enforceTemperatureThreshold evaluates the nodes running the matched pods
+// against the temperature threshold of the config and alerts for the pods of
+// nodes that started exceeding it, and resolves the alerts once the nodes are
+// back under it by the hysteresis band. powerCaps carries the power cap
+// computed for each pod, which is forwarded with the alert. It returns the
+// evaluated nodes and the number of nodes that could not be evaluated.
+func (r *PowerCappingConfigReconciler) enforceTemperatureThreshold(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, pods []corev1.Pod, powerCaps map[types.UID]int) ([]nodeTemperature, int) {
+	spec := &powerCappingConfig.Spec.TemperatureThresholdSpec
+	if spec.Kind == "" || spec.Kind == v1alpha1.NoTemperatureThreshold {
+		return nil, 0
+	}
+	key := client.ObjectKeyFromObject(powerCappingConfig)
+	window := getTemperatureSampleWindow(powerCappingConfig)
+	hysteresis := getHysteresis(powerCappingConfig)
+
+	podsByNode := make(map[string][]*corev1.Pod)
+	for i := range pods {
+		if node := pods[i].Spec.NodeName; node != "" {
+			podsByNode[node] = append(podsByNode[node], &pods[i])
+		}
+	}
+	nodes := make([]string, 0, len(podsByNode))
+	for node := range podsByNode {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	temperatures := make([]nodeTemperature, 0, len(nodes))
+	failed := 0
+	for _, node := range nodes {
+		temperature, err := r.measureNodeTemperature(ctx, powerCappingConfig, node, window)
+		if err != nil {
+			log.Error(err, "Failed to query node temperature", "node", node)
+			failed++
+			continue
+		}
+		temperature.threshold = r.calculateTemperatureThreshold(powerCappingConfig, temperature)
+		temperatures = append(temperatures, *temperature)
+
+		if !temperature.exceeded() {
+			if temperature.cooled(hysteresis) && r.forgetTemperatureAlert(key, node) {
+				for _, pod := range podsByNode[node] {
+					r.resolveAlert(ctx, pod, powerCappingConfig)
+				}
+			}
+			continue
+		}
+		log.Info("Temperature threshold exceeded", "node", node, "temperature", temperature.current, "threshold", temperature.threshold)
+		if !r.shouldAlertTemperature(key, node) {
+			continue
+		}
+		for _, pod := range podsByNode[node] {
+			devices := r.getPodDevices(ctx, powerCappingConfig, pod)
+			if devices == nil {
+				devices = make(map[string]string, 3)
+			}
+			devices["node"] = node
+			devices["temperature"] = fmt.Sprintf("%.1f", temperature.current)
+			devices["temperatureThreshold"] = fmt.Sprintf("%.1f", temperature.threshold)
+			if err := r.createAlert(pod, powerCaps[pod.UID], devices, powerCappingConfig); err != nil {
+				log.Error(err, "Failed to create alert", "pod", pod.Name)
+			}
+		}
+	}
+	r.forgetTemperatureAlerts(key, nodes)
+	return temperatures, failed
+}
+
+// measureNodeTemperature reads the current, peak and average temperature
+// of a node over the sample window.
+func (r *PowerCappingConfigReconciler) measureNodeTemperature(ctx context.Context, powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, node string, window time.Duration) (*nodeTemperature, error) {
+	source, err := r.metricsSource(powerCappingConfig)
+	if err != nil {
+		return nil, err
+	}
+	reading, err := source.NodeTemperature(ctx, node, window)
+	if err != nil {
+		return nil, err
+	}
+	return &nodeTemperature{node: node, current: reading.Current, peak: reading.Peak, average: reading.Average}, nil
+}
+
+// calculateTemperatureThreshold returns the absolute threshold of the config,
+// or the configured percentage of the node's peak or average temperature.
+func (r *PowerCappingConfigReconciler) calculateTemperatureThreshold(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig, temperature *nodeTemperature) float64 {
+	spec := &powerCappingConfig.Spec.TemperatureThresholdSpec
+	percentage := spec.TemperatureThresholdPercentage
+	if percentage <= 0 {
+		percentage = r.efficiencyLevelPercentage(powerCappingConfig)
+	}
+	switch spec.Kind {
+	case v1alpha1.AbsoluteTemperatureThresholdInCelsius:
+		return float64(spec.TemperatureThresholdInCelsius)
+	case v1alpha1.RelativeTemperatureThresholdOfPeakTemperatureInPercentage:
+		return temperature.peak * float64(percentage) / 100
+	case v1alpha1.RelativeTemperatureThresholdOfAverageTemperatureInPercentage:
+		return temperature.average * float64(percentage) / 100
+	default:
+		return 0
+	}
+}
+
+// isSupportedTemperatureThresholdKind reports whether the controller knows how
+// to evaluate the temperature threshold kind. An empty kind means no threshold.
+func isSupportedTemperatureThresholdKind(kind v1alpha1.TemperatureThresholdKind) bool {
+	switch kind {
+	case "",
+		v1alpha1.NoTemperatureThreshold,
+		v1alpha1.AbsoluteTemperatureThresholdInCelsius,
+		v1alpha1.RelativeTemperatureThresholdOfPeakTemperatureInPercentage,
+		v1alpha1.RelativeTemperatureThresholdOfAverageTemperatureInPercentage:
+		return true
+	default:
+		return false
+	}
+}
+
+// getTemperatureSampleWindow returns the observation window of the temperature
+// threshold, falling back to defaultSampleWindow when none is set.
+func getTemperatureSampleWindow(powerCappingConfig *powercappingv1alpha1.PowerCappingConfig) time.Duration {
+	sampleWindow := powerCappingConfig.Spec.TemperatureThresholdSpec.RelativeTemperatureThresholdInPercentageSpec.SampleWindow
+	if sampleWindow <= 0 {
+		return defaultSampleWindow
+	}
+	return time.Duration(sampleWindow) * time.Second
+}
+
+// shouldAlertTemperature records that the node exceeds the threshold of the
+// config and reports whether it did not already do so.
+func (r *PowerCappingConfigReconciler) shouldAlertTemperature(key types.NamespacedName, node string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.alertedNodes == nil {
+		r.alertedNodes = make(map[types.NamespacedName]map[string]bool)
+	}
+	nodes, ok := r.alertedNodes[key]
+	if !ok {
+		nodes = make(map[string]bool)
+		r.alertedNodes[key] = nodes
+	}
+	if nodes[node] {
+		return false
+	}
+	nodes[node] = true
+	return true
+}
+
+// forgetTemperatureAlert re-arms the temperature alert of a node and reports
+// whether the node was alerted.
+func (r *PowerCappingConfigReconciler) forgetTemperatureAlert(key types.NamespacedName, node string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	alerted := r.alertedNodes[key][node]
+	delete(r.alertedNodes[key], node)
+	return alerted
+}
+
+// stillAlerted reports whether the pod, or the aggregate of the config, is
+// alerted for exceeding its power cap, or the node of the pod for exceeding
+// the temperature threshold.
+func (r *PowerCappingConfigReconciler) stillAlerted(key types.NamespacedName, pod *corev1.Pod) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, capped := r.alertedCaps[key][pod.UID]
+	_, aggregate := r.alertedAggregates[key]
+	return capped || aggregate || r.alertedNodes[key][pod.Spec.NodeName]
+}
+
+// forgetTemperatureAlerts drops the temperature alert bookkeeping of nodes
+// that no longer run matched pods. A nil node list forgets the whole config.
+func (r *PowerCappingConfigReconciler) forgetTemperatureAlerts(key types.NamespacedName, nodes []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if nodes == nil {
+		delete(r.alertedNodes, key)
+		return
+	}
+	active := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		active[node] = true
+	}
+	for node := range r.alertedNodes[key] {
+		if !active[node] {
+			delete(r.alertedNodes[key], node)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	powercappingv1alpha1 "github.com/Climatik-Project/Climatik-Project/api/v1alpha1"
+)
+
+var _ = Describe("PowerCappingConfig Controller", func() {
+	Context("When evaluating the temperature of nodes", func() {
+		ctx := context.Background()
+		pods := []corev1.Pod{newTestPod("pod-a"), newTestPod("pod-b")}
+		for i := range pods {
+			pods[i].Spec.NodeName = "node"
+		}
+
+		newReconciler := func() *PowerCappingConfigReconciler {
+			reconciler := newTestReconciler()
+			reconciler.PrometheusClient = &fakePrometheus{current: 75, peak: 90, average: 60}
+			return reconciler
+		}
+		newConfig := func(spec powercappingv1alpha1.TemperatureThresholdSpec) *powercappingv1alpha1.PowerCappingConfig {
+			return newTestConfig(powercappingv1alpha1.PowerCappingConfigSpec{TemperatureThresholdSpec: spec})
+		}
+
+		It("should evaluate each node once against an absolute threshold", func() {
+			config := newConfig(powercappingv1alpha1.TemperatureThresholdSpec{
+				Kind: powercappingv1alpha1.AbsoluteTemperatureThresholdInCelsius,
+				AbsoluteTemperatureThresholdInCelsiusSpec: powercappingv1alpha1.AbsoluteTemperatureThresholdInCelsiusSpec{TemperatureThresholdInCelsius: 80},
+			})
+			temperatures, failed := newReconciler().enforceTemperatureThreshold(ctx, config, pods, nil)
+			Expect(failed).To(BeZero())
+			Expect(temperatures).To(HaveLen(1))
+			Expect(temperatures[0].node).To(Equal("node"))
+			Expect(temperatures[0].threshold).To(Equal(80.0))
+			Expect(temperatures[0].exceeded()).To(BeFalse())
+		})
+
+		It("should scale the average temperature and alert once while exceeded", func() {
+			config := newConfig(powercappingv1alpha1.TemperatureThresholdSpec{
+				Kind: powercappingv1alpha1.RelativeTemperatureThresholdOfAverageTemperatureInPercentage,
+				RelativeTemperatureThresholdInPercentageSpec: powercappingv1alpha1.RelativeTemperatureThresholdInPercentageSpec{TemperatureThresholdPercentage: 100},
+			})
+			reconciler := newReconciler()
+			temperatures, failed := reconciler.enforceTemperatureThreshold(ctx, config, pods, nil)
+			Expect(failed).To(BeZero())
+			Expect(temperatures).To(HaveLen(1))
+			Expect(temperatures[0].threshold).To(Equal(60.0))
+			Expect(temperatures[0].exceeded()).To(BeTrue())
+			Expect(reconciler.shouldAlertTemperature(types.NamespacedName{Name: "config", Namespace: "default"}, "node")).To(BeFalse())
+		})
+
+		It("should resolve the alerts once the node is back under the threshold by the hysteresis band", func() {
+			config := newConfig(powercappingv1alpha1.TemperatureThresholdSpec{
+				Kind: powercappingv1alpha1.AbsoluteTemperatureThresholdInCelsius,
+				AbsoluteTemperatureThresholdInCelsiusSpec: powercappingv1alpha1.AbsoluteTemperatureThresholdInCelsiusSpec{TemperatureThresholdInCelsius: 70},
+			})
+			prometheus := &fakePrometheus{current: 75, peak: 90, average: 60}
+			alerts := &fakeAlertManager{}
+			reconciler := newReconciler()
+			reconciler.PrometheusClient = prometheus
+			reconciler.AlertService = newAlertService(alerts)
+			key := types.NamespacedName{Name: "config", Namespace: "default"}
+
+			reconciler.enforceTemperatureThreshold(ctx, config, pods, nil)
+			Expect(reconciler.alertedPods(config)).To(Equal([]string{"pod-a", "pod-b"}))
+			// pod-b is also over its power cap.
+			reconciler.shouldAlert(key, pods[1].UID, 100, 0.05)
+
+			// 68°C is within 5% of the threshold.
+			prometheus.current = 68
+			reconciler.enforceTemperatureThreshold(ctx, config, pods, nil)
+			Expect(alerts.resolved).To(BeEmpty())
+
+			prometheus.current = 60
+			reconciler.enforceTemperatureThreshold(ctx, config, pods, nil)
+			Expect(alerts.resolved).To(Equal([]string{"pod-a"}))
+			Expect(reconciler.alertedPods(config)).To(Equal([]string{"pod-b"}))
+		})
+
+		It("should not evaluate configs without a temperature threshold", func() {
+			config := newConfig(powercappingv1alpha1.TemperatureThresholdSpec{Kind: powercappingv1alpha1.NoTemperatureThreshold})
+			temperatures, failed := newReconciler().enforceTemperatureThreshold(ctx, config, pods, nil)
+			Expect(failed).To(BeZero())
+			Expect(temperatures).To(BeEmpty())
+		})
+	})
+})
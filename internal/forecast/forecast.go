@@ -0,0 +1,63 @@
+// forecast.go
+package forecast
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Sample is a value of a series at a point in time, e.g. the power of a set
+// of pods in watts.
+type Sample struct {
+	Time  time.Time
+	Value float64
+}
+
+// ErrNotEnoughSamples is returned when a series is too short for a model.
+var ErrNotEnoughSamples = errors.New("not enough samples to forecast")
+
+// Forecaster predicts the value of a series horizon after its last sample.
+// Samples are in chronological order and, for models following the steps of
+// the series, at a regular interval as returned by a range query.
+type Forecaster interface {
+	Forecast(samples []Sample, horizon time.Duration) (float64, error)
+}
+
+// Names of the built-in forecasting methods.
+const (
+	HoltWinters = "HoltWinters"
+	LinearTrend = "LinearTrend"
+)
+
+// Default is the method used when none is selected.
+const Default = HoltWinters
+
+// New returns the forecaster of method, or of the default method when method
+// is empty. seasonLength is the number of samples in a season of HoltWinters,
+// or zero to follow the level and trend of the series only.
+func New(method string, seasonLength int) (Forecaster, error) {
+	switch method {
+	case "", HoltWinters:
+		return NewHoltWinters(seasonLength), nil
+	case LinearTrend:
+		return LinearTrendForecaster{}, nil
+	default:
+		return nil, fmt.Errorf("unknown forecasting method %q", method)
+	}
+}
+
+// stepsAhead returns the horizon in steps of the series, taking the step as
+// the mean interval between its samples. A series of less than two samples has
+// no step, and the horizon is then zero steps.
+func stepsAhead(samples []Sample, horizon time.Duration) float64 {
+	if len(samples) < 2 {
+		return 0
+	}
+	span := samples[len(samples)-1].Time.Sub(samples[0].Time)
+	if span <= 0 {
+		return 0
+	}
+	step := span / time.Duration(len(samples)-1)
+	return float64(horizon) / float64(step)
+}
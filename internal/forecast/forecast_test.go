@@ -0,0 +1,109 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// series samples value every minute from a fixed time.
+func series(n int, value func(i int) float64) []Sample {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	samples := make([]Sample, n)
+	for i := range samples {
+		samples[i] = Sample{Time: start.Add(time.Duration(i) * time.Minute), Value: value(i)}
+	}
+	return samples
+}
+
+func TestLinearTrendExtrapolatesTheLine(t *testing.T) {
+	samples := series(30, func(i int) float64 { return 100 + 2*float64(i) })
+
+	forecast, err := LinearTrendForecaster{}.Forecast(samples, 15*time.Minute)
+	require.NoError(t, err)
+	// The last sample is at minute 29, so the forecast is at minute 44.
+	assert.InDelta(t, 188, forecast, 1e-9)
+}
+
+func TestLinearTrendFitsNoisySamples(t *testing.T) {
+	samples := series(60, func(i int) float64 {
+		noise := 5.0
+		if i%2 == 1 {
+			noise = -5
+		}
+		return 300 + float64(i) + noise
+	})
+
+	forecast, err := LinearTrendForecaster{}.Forecast(samples, 10*time.Minute)
+	require.NoError(t, err)
+	assert.InDelta(t, 369, forecast, 1)
+}
+
+func TestHoltWintersFollowsTheTrend(t *testing.T) {
+	samples := series(30, func(i int) float64 { return 100 + 2*float64(i) })
+
+	forecast, err := NewHoltWinters(0).Forecast(samples, 15*time.Minute)
+	require.NoError(t, err)
+	assert.InDelta(t, 188, forecast, 1e-9)
+}
+
+func TestHoltWintersRepeatsTheSeason(t *testing.T) {
+	// A daily pattern of 24 hourly samples peaking at noon.
+	daily := func(i int) float64 { return 500 + 200*math.Sin(2*math.Pi*float64(i%24-6)/24) }
+	samples := make([]Sample, 72)
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i := range samples {
+		samples[i] = Sample{Time: start.Add(time.Duration(i) * time.Hour), Value: daily(i)}
+	}
+
+	// The last sample is at 23:00, so 13 hours ahead is noon.
+	forecast, err := NewHoltWinters(24).Forecast(samples, 13*time.Hour)
+	require.NoError(t, err)
+	assert.InDelta(t, 700, forecast, 1e-6)
+}
+
+func TestHoltWintersFollowsTheTrendOfTheSeason(t *testing.T) {
+	samples := series(48, func(i int) float64 {
+		return 400 + float64(i) + 50*math.Sin(2*math.Pi*float64(i%12)/12)
+	})
+
+	forecast, err := NewHoltWinters(12).Forecast(samples, 12*time.Minute)
+	require.NoError(t, err)
+	// One season after the last sample, the pattern is back at its value
+	// and the trend has added twelve more watts.
+	assert.InDelta(t, samples[47].Value+12, forecast, 5)
+}
+
+func TestForecastNeedsEnoughSamples(t *testing.T) {
+	samples := series(30, func(i int) float64 { return 100 })
+
+	_, err := NewHoltWinters(24).Forecast(samples, time.Minute)
+	assert.ErrorIs(t, err, ErrNotEnoughSamples)
+	_, err = NewHoltWinters(0).Forecast(samples[:1], time.Minute)
+	assert.ErrorIs(t, err, ErrNotEnoughSamples)
+	_, err = LinearTrendForecaster{}.Forecast(samples[:1], time.Minute)
+	assert.ErrorIs(t, err, ErrNotEnoughSamples)
+}
+
+func TestForecastEmptySeries(t *testing.T) {
+	for _, forecaster := range []Forecaster{NewHoltWinters(0), NewHoltWinters(24), LinearTrendForecaster{}} {
+		_, err := forecaster.Forecast(nil, time.Minute)
+		assert.ErrorIs(t, err, ErrNotEnoughSamples)
+	}
+}
+
+func TestNew(t *testing.T) {
+	forecaster, err := New("", 12)
+	require.NoError(t, err)
+	assert.Equal(t, NewHoltWinters(12), forecaster)
+
+	forecaster, err = New(LinearTrend, 0)
+	require.NoError(t, err)
+	assert.Equal(t, LinearTrendForecaster{}, forecaster)
+
+	_, err = New("Prophet", 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,90 @@
+// holtwinters.go
+package forecast
+
+import (
+	"math"
+	"time"
+)
+
+// Smoothing factors of NewHoltWinters. The level follows the power quickly,
+// while the trend and the seasonal pattern change slowly so that a burst does
+// not turn into a lasting slope.
+const (
+	DefaultAlpha = 0.5
+	DefaultBeta  = 0.1
+	DefaultGamma = 0.3
+)
+
+// HoltWintersForecaster is triple exponential smoothing with an additive
+// seasonal component. Alpha, Beta and Gamma, between 0 and 1, smooth the
+// level, the trend and the seasonal component. Without a season it is Holt's
+// linear method, which needs two samples; with one it needs two full seasons.
+type HoltWintersForecaster struct {
+	Alpha, Beta, Gamma float64
+	// SeasonLength is the number of samples in a season, e.g. 1440 for a
+	// daily pattern sampled every minute, or zero for no season.
+	SeasonLength int
+}
+
+// NewHoltWinters returns a Holt-Winters forecaster with the default smoothing
+// factors.
+func NewHoltWinters(seasonLength int) *HoltWintersForecaster {
+	return &HoltWintersForecaster{Alpha: DefaultAlpha, Beta: DefaultBeta, Gamma: DefaultGamma, SeasonLength: seasonLength}
+}
+
+func (f *HoltWintersForecaster) Forecast(samples []Sample, horizon time.Duration) (float64, error) {
+	steps := stepsAhead(samples, horizon)
+	if f.SeasonLength <= 0 {
+		return f.forecastTrend(samples, steps)
+	}
+	return f.forecastSeasonal(samples, steps)
+}
+
+// forecastTrend smooths the level and trend of the series from its first two
+// samples on.
+func (f *HoltWintersForecaster) forecastTrend(samples []Sample, steps float64) (float64, error) {
+	if len(samples) < 2 {
+		return 0, ErrNotEnoughSamples
+	}
+	level := samples[0].Value
+	trend := samples[1].Value - samples[0].Value
+	for _, sample := range samples[1:] {
+		previous := level
+		level = f.Alpha*sample.Value + (1-f.Alpha)*(level+trend)
+		trend = f.Beta*(level-previous) + (1-f.Beta)*trend
+	}
+	return level + steps*trend, nil
+}
+
+// forecastSeasonal initializes the level and seasonal component from the
+// first season and the trend from the difference with the second one, then
+// smooths the rest of the series.
+func (f *HoltWintersForecaster) forecastSeasonal(samples []Sample, steps float64) (float64, error) {
+	m := f.SeasonLength
+	if len(samples) < 2*m {
+		return 0, ErrNotEnoughSamples
+	}
+	var first, second float64
+	for i := 0; i < m; i++ {
+		first += samples[i].Value
+		second += samples[m+i].Value
+	}
+	level := first / float64(m)
+	trend := (second - first) / float64(m*m)
+	seasonal := make([]float64, m)
+	for i := 0; i < m; i++ {
+		seasonal[i] = samples[i].Value - level
+	}
+
+	for t := m; t < len(samples); t++ {
+		previous := level
+		season := seasonal[t%m]
+		level = f.Alpha*(samples[t].Value-season) + (1-f.Alpha)*(level+trend)
+		trend = f.Beta*(level-previous) + (1-f.Beta)*trend
+		seasonal[t%m] = f.Gamma*(samples[t].Value-level) + (1-f.Gamma)*season
+	}
+
+	// The seasonal component of the predicted step, counted from the last sample.
+	ahead := int(math.Round(steps))
+	return level + steps*trend + seasonal[(len(samples)-1+ahead)%m], nil
+}
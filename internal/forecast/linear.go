@@ -0,0 +1,36 @@
+// linear.go
+package forecast
+
+import "time"
+
+// LinearTrendForecaster extrapolates the least squares line through the
+// samples. It does not need the samples to be evenly spaced.
+type LinearTrendForecaster struct{}
+
+func (LinearTrendForecaster) Forecast(samples []Sample, horizon time.Duration) (float64, error) {
+	if len(samples) < 2 {
+		return 0, ErrNotEnoughSamples
+	}
+
+	// Times are taken in seconds since the first sample to keep the sums small.
+	origin := samples[0].Time
+	n := float64(len(samples))
+	var sumX, sumY, sumXX, sumXY float64
+	for _, sample := range samples {
+		x := sample.Time.Sub(origin).Seconds()
+		sumX += x
+		sumY += sample.Value
+		sumXX += x * x
+		sumXY += x * sample.Value
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		// All samples are at the same time.
+		return 0, ErrNotEnoughSamples
+	}
+	slope := (n*sumXY - sumX*sumY) / denominator
+	intercept := (sumY - slope*sumX) / n
+
+	x := samples[len(samples)-1].Time.Add(horizon).Sub(origin).Seconds()
+	return intercept + slope*x, nil
+}
@@ -0,0 +1,49 @@
+// client.go
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+
+	prom_api "github.com/prometheus/client_golang/api"
+)
+
+// ReloadableClient is a Prometheus client whose address can change while it
+// is in use, e.g. when the operator configuration is reloaded. Requests in
+// flight complete against the address they were built for.
+type ReloadableClient struct {
+	client atomic.Value // prom_api.Client
+}
+
+// NewReloadableClient returns a client of the Prometheus at address.
+func NewReloadableClient(address string) (*ReloadableClient, error) {
+	c := &ReloadableClient{}
+	if err := c.SetAddress(address); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// SetAddress points the client at the Prometheus at address.
+func (c *ReloadableClient) SetAddress(address string) error {
+	client, err := prom_api.NewClient(prom_api.Config{Address: address})
+	if err != nil {
+		return err
+	}
+	c.client.Store(client)
+	return nil
+}
+
+func (c *ReloadableClient) current() prom_api.Client {
+	return c.client.Load().(prom_api.Client)
+}
+
+func (c *ReloadableClient) URL(ep string, args map[string]string) *url.URL {
+	return c.current().URL(ep, args)
+}
+
+func (c *ReloadableClient) Do(ctx context.Context, req *http.Request) (*http.Response, []byte, error) {
+	return c.current().Do(ctx, req)
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// prometheusServer answers every instant query with value.
+func prometheusServer(t *testing.T, value float64) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(rw, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[0,"%g"]}]}}`, value)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestReloadableClient(t *testing.T) {
+	client, err := NewReloadableClient(prometheusServer(t, 250).URL)
+	require.NoError(t, err)
+	api := prom_v1.NewAPI(client)
+
+	result, _, err := api.Query(context.Background(), "up", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, model.SampleValue(250), result.(model.Vector)[0].Value)
+
+	require.NoError(t, client.SetAddress(prometheusServer(t, 400).URL))
+	result, _, err = api.Query(context.Background(), "up", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, model.SampleValue(400), result.(model.Vector)[0].Value)
+
+	assert.Error(t, client.SetAddress("://prometheus"))
+}
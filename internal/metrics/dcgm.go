@@ -0,0 +1,78 @@
+// dcgm.go
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/Climatik-Project/Climatik-Project/internal/promql"
+)
+
+// DCGMSource reads the gauges of the NVIDIA DCGM exporter from Prometheus, for
+// clusters running GPU workloads without Kepler. The exporter must map GPUs
+// to pods, which it labels with pod and namespace, and labels every GPU with
+// its node in Hostname. Only GPUs are measured, so the power of a node is the
+// power of its GPUs.
+type DCGMSource struct {
+	prometheus
+	// Names are the names of the series read, DefaultNames by default.
+	Names Names
+}
+
+// NewDCGMSource returns a source reading DCGM exporter metrics through api.
+func NewDCGMSource(api prom_v1.API) *DCGMSource {
+	return &DCGMSource{prometheus: prometheus{api: api}, Names: DefaultNames()}
+}
+
+func (s *DCGMSource) PodPower(ctx context.Context, pods []types.NamespacedName, window time.Duration) (*Reading, error) {
+	if len(pods) == 0 {
+		return nil, fmt.Errorf("no pods to measure")
+	}
+	return s.reading(ctx, s.podPowerQuery(pods), window)
+}
+
+func (s *DCGMSource) PodPowerHistory(ctx context.Context, pods []types.NamespacedName, lookback, step time.Duration) ([]Sample, error) {
+	if len(pods) == 0 {
+		return nil, fmt.Errorf("no pods to measure")
+	}
+	return s.history(ctx, s.podPowerQuery(pods), lookback, step)
+}
+
+func (s *DCGMSource) NodePower(ctx context.Context, node string, window time.Duration) (*Reading, error) {
+	return s.GPUPower(ctx, node, window)
+}
+
+func (s *DCGMSource) GPUPower(ctx context.Context, node string, window time.Duration) (*Reading, error) {
+	names := &s.Names.DCGM
+	return s.reading(ctx, promql.Sum(promql.Selector(names.PowerUsage, promql.Equal(names.NodeLabel, node))), window)
+}
+
+func (s *DCGMSource) NodeTemperature(ctx context.Context, node string, window time.Duration) (*Reading, error) {
+	return s.firstReading(ctx, nodeTemperatureQueries(&s.Names, node), node, "temperature", window)
+}
+
+// PodDevices returns the GPU of the pod and its model.
+func (s *DCGMSource) PodDevices(ctx context.Context, pod types.NamespacedName) (map[string]string, error) {
+	names := &s.Names.DCGM
+	vector, err := s.vector(ctx, promql.Selector(names.PowerUsage,
+		promql.Equal(names.NamespaceLabel, pod.Namespace), promql.Equal(names.PodLabel, pod.Name)))
+	if err != nil {
+		return nil, err
+	}
+	metric := vector[0].Metric
+	return map[string]string{
+		"gpu":       string(metric[model.LabelName(names.GPULabel)]),
+		"modelName": string(metric[model.LabelName(names.ModelLabel)]),
+	}, nil
+}
+
+// podPowerQuery sums the power of the GPUs of the pods.
+func (s *DCGMSource) podPowerQuery(pods []types.NamespacedName) string {
+	names := &s.Names.DCGM
+	return promql.Sum(promql.Or(podSelectors(names.PowerUsage, names.PodLabel, names.NamespaceLabel, pods)...))
+}
@@ -0,0 +1,234 @@
+// file.go
+package metrics
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Kinds of the entries of a metrics file.
+const (
+	kindPod         = "pod"
+	kindNode        = "node"
+	kindGPU         = "gpu"
+	kindTemperature = "temperature"
+)
+
+// entry is a measurement of a metrics file. Name is namespace/name for pods
+// and the node name otherwise.
+type entry struct {
+	Time  time.Time `json:"time"`
+	Kind  string    `json:"kind"`
+	Name  string    `json:"name"`
+	Value float64   `json:"value"`
+}
+
+type seriesKey struct {
+	kind, name string
+}
+
+// FileSource answers from a static time series of measurements, e.g. power
+// recorded in production replayed in a test cluster. The value of a series at
+// a point in time is its latest entry that is not later, so a file may only
+// hold the changes of a series.
+type FileSource struct {
+	// Now returns the current time, time.Now when nil.
+	Now func() time.Time
+
+	series map[seriesKey][]Sample
+}
+
+// NewFileSource loads the time series at path. A .json file holds an array of
+// {"time", "kind", "name", "value"} objects; any other file is read as CSV
+// with the columns time, kind, name and value, after an optional header.
+// Times are in RFC 3339, kinds are pod, node, gpu or temperature, and values
+// are in watts or celsius.
+func NewFileSource(path string) (*FileSource, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []entry
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.NewDecoder(file).Decode(&entries)
+	} else {
+		entries, err = readEntries(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metrics from %s: %w", path, err)
+	}
+
+	series := make(map[seriesKey][]Sample)
+	for _, entry := range entries {
+		switch entry.Kind {
+		case kindPod, kindNode, kindGPU, kindTemperature:
+		default:
+			return nil, fmt.Errorf("failed to read metrics from %s: unknown kind %q", path, entry.Kind)
+		}
+		key := seriesKey{kind: entry.Kind, name: entry.Name}
+		series[key] = append(series[key], Sample{Time: entry.Time, Value: entry.Value})
+	}
+	for _, samples := range series {
+		sort.SliceStable(samples, func(i, j int) bool { return samples[i].Time.Before(samples[j].Time) })
+	}
+	return &FileSource{series: series}, nil
+}
+
+func readEntries(r io.Reader) ([]entry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]entry, 0, len(records))
+	for i, record := range records {
+		t, err := time.Parse(time.RFC3339, record[0])
+		if err != nil {
+			if i == 0 {
+				// header
+				continue
+			}
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		value, err := strconv.ParseFloat(record[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		entries = append(entries, entry{Time: t, Kind: record[1], Name: record[2], Value: value})
+	}
+	return entries, nil
+}
+
+// Replay shifts all series so that the earliest entry is at start, e.g. the
+// start of the operator, to play a recording back from then on.
+func (s *FileSource) Replay(start time.Time) {
+	var earliest time.Time
+	for _, samples := range s.series {
+		if earliest.IsZero() || samples[0].Time.Before(earliest) {
+			earliest = samples[0].Time
+		}
+	}
+	offset := start.Sub(earliest)
+	for _, samples := range s.series {
+		for i := range samples {
+			samples[i].Time = samples[i].Time.Add(offset)
+		}
+	}
+}
+
+func (s *FileSource) PodPower(ctx context.Context, pods []types.NamespacedName, window time.Duration) (*Reading, error) {
+	if len(pods) == 0 {
+		return nil, fmt.Errorf("no pods to measure")
+	}
+	return s.reading(podKeys(pods), window)
+}
+
+func (s *FileSource) PodPowerHistory(ctx context.Context, pods []types.NamespacedName, lookback, step time.Duration) ([]Sample, error) {
+	if len(pods) == 0 {
+		return nil, fmt.Errorf("no pods to measure")
+	}
+	keys := podKeys(pods)
+	now := s.now()
+	var samples []Sample
+	for t := now.Add(-lookback); !t.After(now); t = t.Add(step) {
+		if value, ok := s.sum(keys, t); ok {
+			samples = append(samples, Sample{Time: t, Value: value})
+		}
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no pod metrics for %s", keys[0].name)
+	}
+	return samples, nil
+}
+
+func (s *FileSource) NodePower(ctx context.Context, node string, window time.Duration) (*Reading, error) {
+	return s.reading([]seriesKey{{kind: kindNode, name: node}}, window)
+}
+
+func (s *FileSource) GPUPower(ctx context.Context, node string, window time.Duration) (*Reading, error) {
+	return s.reading([]seriesKey{{kind: kindGPU, name: node}}, window)
+}
+
+func (s *FileSource) NodeTemperature(ctx context.Context, node string, window time.Duration) (*Reading, error) {
+	return s.reading([]seriesKey{{kind: kindTemperature, name: node}}, window)
+}
+
+func (s *FileSource) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// reading returns the sum of the series now and, with a window, its peak and
+// average at the entries within the window.
+func (s *FileSource) reading(keys []seriesKey, window time.Duration) (*Reading, error) {
+	now := s.now()
+	current, ok := s.sum(keys, now)
+	if !ok {
+		return nil, fmt.Errorf("no %s metrics for %s at %s", keys[0].kind, keys[0].name, now.Format(time.RFC3339))
+	}
+	reading := &Reading{Current: current, Peak: current, Average: current}
+	if window <= 0 {
+		return reading, nil
+	}
+
+	total, count := current, 1
+	start := now.Add(-window)
+	seen := map[int64]bool{now.UnixNano(): true}
+	for _, key := range keys {
+		for _, sample := range s.series[key] {
+			if !sample.Time.After(start) || sample.Time.After(now) || seen[sample.Time.UnixNano()] {
+				continue
+			}
+			seen[sample.Time.UnixNano()] = true
+			if value, ok := s.sum(keys, sample.Time); ok {
+				reading.Peak = max(reading.Peak, value)
+				total += value
+				count++
+			}
+		}
+	}
+	reading.Average = total / float64(count)
+	return reading, nil
+}
+
+// sum returns the sum of the values of the series at t, and false when none
+// of them has a value yet.
+func (s *FileSource) sum(keys []seriesKey, t time.Time) (float64, bool) {
+	var total float64
+	found := false
+	for _, key := range keys {
+		samples := s.series[key]
+		// The first entry after t follows the value at t.
+		i := sort.Search(len(samples), func(i int) bool { return samples[i].Time.After(t) })
+		if i > 0 {
+			total += samples[i-1].Value
+			found = true
+		}
+	}
+	return total, found
+}
+
+func podKeys(pods []types.NamespacedName) []seriesKey {
+	keys := make([]seriesKey, 0, len(pods))
+	for _, pod := range pods {
+		keys = append(keys, seriesKey{kind: kindPod, name: pod.String()})
+	}
+	return keys
+}
@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func at(value string) func() time.Time {
+	return func() time.Time {
+		t, _ := time.Parse(time.RFC3339, value)
+		return t
+	}
+}
+
+const recording = `time,kind,name,value
+2024-05-01T00:00:00Z,pod,default/llm-a,100
+2024-05-01T00:00:00Z,pod,default/llm.b,200
+2024-05-01T00:01:00Z,pod,default/llm-a,300
+2024-05-01T00:02:00Z,pod,default/llm.b,100
+2024-05-01T00:00:00Z,node,node-a,900
+2024-05-01T00:00:00Z,temperature,node-a,65
+`
+
+func TestFileSourcePodPower(t *testing.T) {
+	source, err := NewFileSource(writeFile(t, "power.csv", recording))
+	require.NoError(t, err)
+	source.Now = at("2024-05-01T00:02:30Z")
+
+	// The pods sum to 300W, then 500W and 400W.
+	reading, err := source.PodPower(context.Background(), pods, 5*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 400.0, reading.Current)
+	assert.Equal(t, 500.0, reading.Peak)
+	assert.InDelta(t, 400.0, reading.Average, 1e-9)
+
+	reading, err = source.PodPower(context.Background(), pods[:1], 0)
+	require.NoError(t, err)
+	assert.Equal(t, &Reading{Current: 300, Peak: 300, Average: 300}, reading)
+
+	samples, err := source.PodPowerHistory(context.Background(), pods, 2*time.Minute, time.Minute)
+	require.NoError(t, err)
+	values := make([]float64, 0, len(samples))
+	for _, sample := range samples {
+		values = append(values, sample.Value)
+	}
+	assert.Equal(t, []float64{300, 500, 400}, values)
+}
+
+func TestFileSourceNodes(t *testing.T) {
+	source, err := NewFileSource(writeFile(t, "power.json", `[
+  {"time": "2024-05-01T00:00:00Z", "kind": "node", "name": "node-a", "value": 900},
+  {"time": "2024-05-01T00:00:00Z", "kind": "gpu", "name": "node-a", "value": 600},
+  {"time": "2024-05-01T00:00:00Z", "kind": "temperature", "name": "node-a", "value": 65}
+]`))
+	require.NoError(t, err)
+	source.Now = at("2024-05-01T00:01:00Z")
+	ctx := context.Background()
+
+	reading, err := source.NodePower(ctx, "node-a", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 900.0, reading.Current)
+	reading, err = source.GPUPower(ctx, "node-a", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 600.0, reading.Current)
+	reading, err = source.NodeTemperature(ctx, "node-a", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 65.0, reading.Current)
+
+	_, err = source.NodePower(ctx, "node-b", 0)
+	assert.Error(t, err)
+}
+
+func TestFileSourceReplay(t *testing.T) {
+	source, err := NewFileSource(writeFile(t, "power.csv", recording))
+	require.NoError(t, err)
+	source.Replay(at("2024-06-01T12:00:00Z")())
+
+	source.Now = at("2024-06-01T12:01:30Z")
+	reading, err := source.PodPower(context.Background(), pods, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 500.0, reading.Current)
+
+	source.Now = at("2024-06-01T11:59:00Z")
+	_, err = source.PodPower(context.Background(), pods, 0)
+	assert.Error(t, err)
+}
+
+func TestFileSourceInvalid(t *testing.T) {
+	_, err := NewFileSource(writeFile(t, "power.csv", "2024-05-01T00:00:00Z,pod,default/llm,high\n"))
+	assert.Error(t, err)
+	_, err = NewFileSource(writeFile(t, "power.csv", "2024-05-01T00:00:00Z,rack,rack-a,100\n"))
+	assert.Error(t, err)
+	_, err = NewFileSource(filepath.Join(t.TempDir(), "missing.csv"))
+	assert.Error(t, err)
+}
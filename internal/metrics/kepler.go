@@ -0,0 +1,103 @@
+// kepler.go
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/Climatik-Project/Climatik-Project/internal/promql"
+)
+
+// KeplerSource reads the energy counters exported by Kepler from Prometheus.
+// Kepler does not measure temperatures, which are read from the DCGM or
+// node-exporter series of the same Prometheus.
+type KeplerSource struct {
+	prometheus
+	// Names are the names of the series read, DefaultNames by default.
+	Names Names
+}
+
+// NewKeplerSource returns a source reading Kepler metrics through api.
+func NewKeplerSource(api prom_v1.API) *KeplerSource {
+	return &KeplerSource{prometheus: prometheus{api: api}, Names: DefaultNames()}
+}
+
+func (s *KeplerSource) PodPower(ctx context.Context, pods []types.NamespacedName, window time.Duration) (*Reading, error) {
+	if len(pods) == 0 {
+		return nil, fmt.Errorf("no pods to measure")
+	}
+	return s.reading(ctx, s.podPowerQuery(pods), window)
+}
+
+func (s *KeplerSource) PodPowerHistory(ctx context.Context, pods []types.NamespacedName, lookback, step time.Duration) ([]Sample, error) {
+	if len(pods) == 0 {
+		return nil, fmt.Errorf("no pods to measure")
+	}
+	return s.history(ctx, s.podPowerQuery(pods), lookback, step)
+}
+
+// NodePower tries the platform series, which covers the whole node as seen by
+// its power supply, and falls back to package and DRAM on nodes without
+// platform sensors. Kepler labels its node series with the node name.
+func (s *KeplerSource) NodePower(ctx context.Context, node string, window time.Duration) (*Reading, error) {
+	return s.firstReading(ctx, []string{
+		s.nodePowerQuery(s.Names.Kepler.NodePlatformJoules, node),
+		s.nodePowerQuery(s.Names.Kepler.NodePackageJoules, node) + " + " + s.nodePowerQuery(s.Names.Kepler.NodeDRAMJoules, node),
+	}, node, "power", window)
+}
+
+func (s *KeplerSource) GPUPower(ctx context.Context, node string, window time.Duration) (*Reading, error) {
+	return s.reading(ctx, s.nodePowerQuery(s.Names.Kepler.NodeGPUJoules, node), window)
+}
+
+func (s *KeplerSource) NodeTemperature(ctx context.Context, node string, window time.Duration) (*Reading, error) {
+	return s.firstReading(ctx, nodeTemperatureQueries(&s.Names, node), node, "temperature", window)
+}
+
+// PodDevices returns the CPU package and the GPU the pod was measured on.
+func (s *KeplerSource) PodDevices(ctx context.Context, pod types.NamespacedName) (map[string]string, error) {
+	names := &s.Names.Kepler
+	devices := make(map[string]string, 2)
+	for _, device := range []struct{ name, metric, label string }{
+		{"package", names.ContainerPackageJoules, names.PackageLabel},
+		{"gpu", names.ContainerGPUJoules, names.GPULabel},
+	} {
+		vector, err := s.vector(ctx, promql.Selector(device.metric,
+			promql.Equal(names.NamespaceLabel, pod.Namespace), promql.Equal(names.PodLabel, pod.Name)))
+		if err != nil {
+			return nil, err
+		}
+		for _, sample := range vector {
+			if label, ok := sample.Metric[model.LabelName(device.label)]; ok {
+				devices[device.name] = string(label)
+				break
+			}
+		}
+		if _, ok := devices[device.name]; !ok {
+			return nil, fmt.Errorf("no data with device %s returned from Prometheus query", device.name)
+		}
+	}
+	return devices, nil
+}
+
+// podPowerQuery sums the power of the pods, e.g.
+// sum(rate(kepler_container_joules_total{container_namespace="default",pod_name="stress-7d796cb489-fbw68"}[1m])).
+func (s *KeplerSource) podPowerQuery(pods []types.NamespacedName) string {
+	names := &s.Names.Kepler
+	selectors := podSelectors(names.ContainerJoules, names.PodLabel, names.NamespaceLabel, pods)
+	rates := make([]string, 0, len(selectors))
+	for _, selector := range selectors {
+		rates = append(rates, promql.Rate(selector, rateWindow))
+	}
+	return promql.Sum(promql.Or(rates...))
+}
+
+// nodePowerQuery sums the power of the energy counter of the node.
+func (s *KeplerSource) nodePowerQuery(metric, node string) string {
+	return promql.Sum(promql.Rate(promql.Selector(metric, promql.Equal(s.Names.Kepler.NodeLabel, node)), rateWindow))
+}
@@ -0,0 +1,179 @@
+// names.go
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/Climatik-Project/Climatik-Project/internal/promql"
+)
+
+// Names are the names of the series read from Prometheus and of their labels,
+// which differ between exporter versions and relabeling setups.
+type Names struct {
+	Kepler       KeplerNames       `json:"kepler"`
+	DCGM         DCGMNames         `json:"dcgm"`
+	NodeExporter NodeExporterNames `json:"nodeExporter"`
+	Requests     RequestNames      `json:"requests"`
+}
+
+// KeplerNames are the names of the Kepler energy counters and of their labels.
+type KeplerNames struct {
+	ContainerJoules        string `json:"containerJoules"`
+	ContainerPackageJoules string `json:"containerPackageJoules"`
+	ContainerGPUJoules     string `json:"containerGPUJoules"`
+	NodePlatformJoules     string `json:"nodePlatformJoules"`
+	NodePackageJoules      string `json:"nodePackageJoules"`
+	NodeDRAMJoules         string `json:"nodeDRAMJoules"`
+	NodeGPUJoules          string `json:"nodeGPUJoules"`
+
+	PodLabel       string `json:"podLabel"`
+	NamespaceLabel string `json:"namespaceLabel"`
+	NodeLabel      string `json:"nodeLabel"`
+	PackageLabel   string `json:"packageLabel"`
+	GPULabel       string `json:"gpuLabel"`
+}
+
+// DCGMNames are the names of the DCGM exporter gauges and of their labels.
+type DCGMNames struct {
+	PowerUsage     string `json:"powerUsage"`
+	GPUTemperature string `json:"gpuTemperature"`
+
+	PodLabel       string `json:"podLabel"`
+	NamespaceLabel string `json:"namespaceLabel"`
+	NodeLabel      string `json:"nodeLabel"`
+	GPULabel       string `json:"gpuLabel"`
+	ModelLabel     string `json:"modelLabel"`
+}
+
+// NodeExporterNames are the names of the node-exporter series giving the
+// temperatures of nodes, and of their labels.
+type NodeExporterNames struct {
+	HwmonTemperature string `json:"hwmonTemperature"`
+	UnameInfo        string `json:"unameInfo"`
+
+	InstanceLabel string `json:"instanceLabel"`
+	NodenameLabel string `json:"nodenameLabel"`
+}
+
+// RequestNames are the names of the counter of the requests served by pods and
+// of its labels, which weigh deployments by their load in the Planner.
+type RequestNames struct {
+	RequestsTotal string `json:"requestsTotal"`
+
+	PodLabel       string `json:"podLabel"`
+	NamespaceLabel string `json:"namespaceLabel"`
+}
+
+// DefaultNames returns the names exported by Kepler, the DCGM exporter and
+// node-exporter out of the box, and the request counter of the usual HTTP
+// instrumentation.
+func DefaultNames() Names {
+	return Names{
+		Kepler: KeplerNames{
+			ContainerJoules:        "kepler_container_joules_total",
+			ContainerPackageJoules: "kepler_container_package_joules_total",
+			ContainerGPUJoules:     "kepler_container_gpu_joules_total",
+			NodePlatformJoules:     "kepler_node_platform_joules_total",
+			NodePackageJoules:      "kepler_node_package_joules_total",
+			NodeDRAMJoules:         "kepler_node_dram_joules_total",
+			NodeGPUJoules:          "kepler_node_gpu_joules_total",
+			PodLabel:               "pod_name",
+			NamespaceLabel:         "container_namespace",
+			NodeLabel:              "instance",
+			PackageLabel:           "package",
+			GPULabel:               "gpu",
+		},
+		DCGM: DCGMNames{
+			PowerUsage:     "DCGM_FI_DEV_POWER_USAGE",
+			GPUTemperature: "DCGM_FI_DEV_GPU_TEMP",
+			PodLabel:       "pod",
+			NamespaceLabel: "namespace",
+			NodeLabel:      "Hostname",
+			GPULabel:       "gpu",
+			ModelLabel:     "modelName",
+		},
+		NodeExporter: NodeExporterNames{
+			HwmonTemperature: "node_hwmon_temp_celsius",
+			UnameInfo:        "node_uname_info",
+			InstanceLabel:    "instance",
+			NodenameLabel:    "nodename",
+		},
+		Requests: RequestNames{
+			RequestsTotal:  "http_requests_total",
+			PodLabel:       "pod",
+			NamespaceLabel: "namespace",
+		},
+	}
+}
+
+// SharedNames holds the names read by the sources built on demand, replaced as
+// a whole when the operator configuration is reloaded.
+type SharedNames struct {
+	names atomic.Pointer[Names]
+}
+
+// NewSharedNames returns shared names holding names.
+func NewSharedNames(names Names) *SharedNames {
+	s := &SharedNames{}
+	s.Store(names)
+	return s
+}
+
+// Load returns the names held.
+func (s *SharedNames) Load() Names {
+	return *s.names.Load()
+}
+
+// Store replaces the names held.
+func (s *SharedNames) Store(names Names) {
+	s.names.Store(&names)
+}
+
+// Validate fails when a name is not a valid metric or label name, since it
+// would not be possible to query it.
+func (n *Names) Validate() error {
+	metrics := []struct{ field, name string }{
+		{"kepler.containerJoules", n.Kepler.ContainerJoules},
+		{"kepler.containerPackageJoules", n.Kepler.ContainerPackageJoules},
+		{"kepler.containerGPUJoules", n.Kepler.ContainerGPUJoules},
+		{"kepler.nodePlatformJoules", n.Kepler.NodePlatformJoules},
+		{"kepler.nodePackageJoules", n.Kepler.NodePackageJoules},
+		{"kepler.nodeDRAMJoules", n.Kepler.NodeDRAMJoules},
+		{"kepler.nodeGPUJoules", n.Kepler.NodeGPUJoules},
+		{"dcgm.powerUsage", n.DCGM.PowerUsage},
+		{"dcgm.gpuTemperature", n.DCGM.GPUTemperature},
+		{"nodeExporter.hwmonTemperature", n.NodeExporter.HwmonTemperature},
+		{"nodeExporter.unameInfo", n.NodeExporter.UnameInfo},
+		{"requests.requestsTotal", n.Requests.RequestsTotal},
+	}
+	labels := []struct{ field, name string }{
+		{"kepler.podLabel", n.Kepler.PodLabel},
+		{"kepler.namespaceLabel", n.Kepler.NamespaceLabel},
+		{"kepler.nodeLabel", n.Kepler.NodeLabel},
+		{"kepler.packageLabel", n.Kepler.PackageLabel},
+		{"kepler.gpuLabel", n.Kepler.GPULabel},
+		{"dcgm.podLabel", n.DCGM.PodLabel},
+		{"dcgm.namespaceLabel", n.DCGM.NamespaceLabel},
+		{"dcgm.nodeLabel", n.DCGM.NodeLabel},
+		{"dcgm.gpuLabel", n.DCGM.GPULabel},
+		{"dcgm.modelLabel", n.DCGM.ModelLabel},
+		{"nodeExporter.instanceLabel", n.NodeExporter.InstanceLabel},
+		{"nodeExporter.nodenameLabel", n.NodeExporter.NodenameLabel},
+		{"requests.podLabel", n.Requests.PodLabel},
+		{"requests.namespaceLabel", n.Requests.NamespaceLabel},
+	}
+	var errs []error
+	for _, metric := range metrics {
+		if err := promql.ValidateMetricName(metric.name); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", metric.field, err))
+		}
+	}
+	for _, label := range labels {
+		if err := promql.ValidateLabelName(label.name); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", label.field, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateNames(t *testing.T) {
+	names := DefaultNames()
+	assert.NoError(t, names.Validate())
+
+	names.DCGM.PowerUsage = `DCGM_FI_DEV_POWER_USAGE{pod=~".*"}`
+	names.DCGM.NodeLabel = "host-name"
+	err := names.Validate()
+	assert.ErrorContains(t, err, "dcgm.powerUsage")
+	assert.ErrorContains(t, err, "dcgm.nodeLabel")
+}
+
+func TestSharedNames(t *testing.T) {
+	names := DefaultNames()
+	shared := NewSharedNames(names)
+	names.Kepler.PodLabel = "pod"
+	assert.Equal(t, DefaultNames(), shared.Load())
+
+	shared.Store(names)
+	assert.Equal(t, "pod", shared.Load().Kepler.PodLabel)
+}
@@ -0,0 +1,150 @@
+// prometheus.go
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/Climatik-Project/Climatik-Project/internal/promql"
+)
+
+var log = ctrl.Log.WithName("metrics")
+
+// rateWindow is the range over which the rate of energy counters is taken.
+const rateWindow = time.Minute
+
+// nodeTemperatureQueries returns the temperature series of a node, tried in
+// order. DCGM labels GPU temperatures with the node; node-exporter hwmon
+// series only carry the instance, so they are joined with the uname info
+// series to select the node.
+func nodeTemperatureQueries(names *Names, node string) []string {
+	dcgm, exporter := &names.DCGM, &names.NodeExporter
+	return []string{
+		promql.Max(promql.Selector(dcgm.GPUTemperature, promql.Equal(dcgm.NodeLabel, node))),
+		promql.Max(fmt.Sprintf("%s * on(%s) group_left(%s) %s", exporter.HwmonTemperature, exporter.InstanceLabel,
+			exporter.NodenameLabel, promql.Selector(exporter.UnameInfo, promql.Equal(exporter.NodenameLabel, node)))),
+	}
+}
+
+// prometheus runs the queries of the sources reading Prometheus.
+type prometheus struct {
+	api prom_v1.API
+}
+
+// reading runs query for the current value and, with a window, for its peak
+// and average over the window.
+func (p *prometheus) reading(ctx context.Context, query string, window time.Duration) (*Reading, error) {
+	current, err := p.value(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return p.overWindow(ctx, query, current, window)
+}
+
+// overWindow completes the current value of query with its peak and average
+// over the window.
+func (p *prometheus) overWindow(ctx context.Context, query string, current float64, window time.Duration) (*Reading, error) {
+	reading := &Reading{Current: current, Peak: current, Average: current}
+	if window <= 0 {
+		return reading, nil
+	}
+	var err error
+	if reading.Peak, err = p.value(ctx, promql.MaxOverTime(query, window)); err != nil {
+		return nil, err
+	}
+	if reading.Average, err = p.value(ctx, promql.AvgOverTime(query, window)); err != nil {
+		return nil, err
+	}
+	return reading, nil
+}
+
+// firstReading returns the reading of the first of the queries that has data
+// for the node.
+func (p *prometheus) firstReading(ctx context.Context, queries []string, node, what string, window time.Duration) (*Reading, error) {
+	var lastErr error
+	for _, query := range queries {
+		current, err := p.value(ctx, query)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return p.overWindow(ctx, query, current, window)
+	}
+	return nil, fmt.Errorf("no %s metrics for node %s: %w", what, node, lastErr)
+}
+
+// value runs an instant query and returns the value of its first sample.
+func (p *prometheus) value(ctx context.Context, query string) (float64, error) {
+	vector, err := p.vector(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	return float64(vector[0].Value), nil
+}
+
+// vector runs an instant query and returns its samples, failing when it has
+// none.
+func (p *prometheus) vector(ctx context.Context, query string) (model.Vector, error) {
+	result, warnings, err := p.api.Query(ctx, query, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if len(warnings) > 0 {
+		log.Info("Prometheus query warnings", "warnings", warnings)
+	}
+	log.Info("Prometheus query result", "query", query, "result", result)
+	if vector, ok := result.(model.Vector); ok && len(vector) > 0 {
+		return vector, nil
+	}
+	return nil, fmt.Errorf("no data returned from Prometheus query")
+}
+
+// history runs a range query over lookback ending now and returns the samples
+// of its first series.
+func (p *prometheus) history(ctx context.Context, query string, lookback, step time.Duration) ([]Sample, error) {
+	end := time.Now()
+	result, warnings, err := p.api.QueryRange(ctx, query, prom_v1.Range{Start: end.Add(-lookback), End: end, Step: step})
+	if err != nil {
+		return nil, err
+	}
+	if len(warnings) > 0 {
+		log.Info("Prometheus query warnings", "warnings", warnings)
+	}
+	matrix, ok := result.(model.Matrix)
+	if !ok || len(matrix) == 0 || len(matrix[0].Values) == 0 {
+		return nil, fmt.Errorf("no data returned from Prometheus range query")
+	}
+	samples := make([]Sample, 0, len(matrix[0].Values))
+	for _, pair := range matrix[0].Values {
+		samples = append(samples, Sample{Time: pair.Timestamp.Time(), Value: float64(pair.Value)})
+	}
+	return samples, nil
+}
+
+// podSelectors selects the series of metric of the pods, with a selector per
+// namespace matching the names of its pods exactly, so that pods of the same
+// name in other namespaces are left out.
+func podSelectors(metric, podLabel, namespaceLabel string, pods []types.NamespacedName) []string {
+	names := make(map[string][]string)
+	for _, pod := range pods {
+		names[pod.Namespace] = append(names[pod.Namespace], pod.Name)
+	}
+	namespaces := make([]string, 0, len(names))
+	for namespace := range names {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	selectors := make([]string, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		selectors = append(selectors, promql.Selector(metric,
+			promql.Equal(namespaceLabel, namespace), promql.OneOf(podLabel, names[namespace]...)))
+	}
+	return selectors
+}
@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// fakeAPI answers the queries containing a key of values with its value and
+// labels, the key found first in the query winning, and records the queries.
+type fakeAPI struct {
+	prom_v1.API
+	values  map[string]float64
+	labels  model.Metric
+	queries []string
+}
+
+func (f *fakeAPI) Query(ctx context.Context, query string, ts time.Time, opts ...prom_v1.Option) (model.Value, prom_v1.Warnings, error) {
+	f.queries = append(f.queries, query)
+	first := -1
+	var value float64
+	for key, v := range f.values {
+		if i := strings.Index(query, key); i >= 0 && (first < 0 || i < first) {
+			first, value = i, v
+		}
+	}
+	if first < 0 {
+		return model.Vector{}, nil, nil
+	}
+	return model.Vector{&model.Sample{Metric: f.labels, Value: model.SampleValue(value)}}, nil, nil
+}
+
+func (f *fakeAPI) QueryRange(ctx context.Context, query string, r prom_v1.Range, opts ...prom_v1.Option) (model.Value, prom_v1.Warnings, error) {
+	f.queries = append(f.queries, query)
+	stream := &model.SampleStream{}
+	for t := r.Start; !t.After(r.End); t = t.Add(r.Step) {
+		stream.Values = append(stream.Values, model.SamplePair{Timestamp: model.TimeFromUnixNano(t.UnixNano()), Value: 100})
+	}
+	return model.Matrix{stream}, nil, nil
+}
+
+var pods = []types.NamespacedName{{Namespace: "default", Name: "llm-a"}, {Namespace: "default", Name: "llm.b"}}
+
+func TestKeplerPodPower(t *testing.T) {
+	api := &fakeAPI{values: map[string]float64{"max_over_time": 400, "avg_over_time": 200, "kepler_container_joules_total": 250}}
+	source := NewKeplerSource(api)
+
+	reading, err := source.PodPower(context.Background(), pods, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, &Reading{Current: 250, Peak: 400, Average: 200}, reading)
+	assert.Equal(t, []string{
+		`sum(rate(kepler_container_joules_total{container_namespace="default",pod_name=~"llm-a|llm\\.b"}[1m]))`,
+		`max_over_time((sum(rate(kepler_container_joules_total{container_namespace="default",pod_name=~"llm-a|llm\\.b"}[1m])))[1m:])`,
+		`avg_over_time((sum(rate(kepler_container_joules_total{container_namespace="default",pod_name=~"llm-a|llm\\.b"}[1m])))[1m:])`,
+	}, api.queries)
+
+	api.queries = nil
+	reading, err = source.PodPower(context.Background(), pods[:1], 0)
+	require.NoError(t, err)
+	assert.Equal(t, &Reading{Current: 250, Peak: 250, Average: 250}, reading)
+	assert.Len(t, api.queries, 1)
+}
+
+func TestKeplerNodePowerFallsBackToPackageAndDRAM(t *testing.T) {
+	api := &fakeAPI{values: map[string]float64{"kepler_node_package_joules_total": 900}}
+	source := NewKeplerSource(api)
+
+	reading, err := source.NodePower(context.Background(), "node-a", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 900.0, reading.Current)
+
+	_, err = NewKeplerSource(&fakeAPI{}).NodePower(context.Background(), "node-a", 0)
+	assert.ErrorContains(t, err, "no power metrics for node node-a")
+}
+
+func TestKeplerPodPowerHistory(t *testing.T) {
+	api := &fakeAPI{}
+	samples, err := NewKeplerSource(api).PodPowerHistory(context.Background(), pods, 10*time.Minute, time.Minute)
+	require.NoError(t, err)
+	assert.Len(t, samples, 11)
+	assert.Equal(t, []string{`sum(rate(kepler_container_joules_total{container_namespace="default",pod_name=~"llm-a|llm\\.b"}[1m]))`}, api.queries)
+}
+
+func TestKeplerCustomNames(t *testing.T) {
+	api := &fakeAPI{values: map[string]float64{"container_energy_joules": 250, "node_energy_joules": 900}}
+	source := NewKeplerSource(api)
+	source.Names.Kepler.ContainerJoules = "container_energy_joules"
+	source.Names.Kepler.PodLabel = "pod"
+	source.Names.Kepler.NamespaceLabel = "namespace"
+	source.Names.Kepler.NodePlatformJoules = "node_energy_joules"
+	source.Names.Kepler.NodeLabel = "node"
+
+	_, err := source.PodPower(context.Background(), pods[:1], 0)
+	require.NoError(t, err)
+	_, err = source.NodePower(context.Background(), "node-a", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`sum(rate(container_energy_joules{namespace="default",pod="llm-a"}[1m]))`,
+		`sum(rate(node_energy_joules{node="node-a"}[1m]))`,
+	}, api.queries)
+
+	_, err = source.PodPower(context.Background(), nil, 0)
+	assert.Error(t, err)
+}
+
+func TestKeplerPodDevices(t *testing.T) {
+	api := &fakeAPI{
+		values: map[string]float64{"kepler_container": 1},
+		labels: model.Metric{"package": "0", "gpu": "1"},
+	}
+	devices, err := NewKeplerSource(api).PodDevices(context.Background(), pods[0])
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"package": "0", "gpu": "1"}, devices)
+}
+
+func TestDCGMSource(t *testing.T) {
+	api := &fakeAPI{
+		values: map[string]float64{"DCGM_FI_DEV_POWER_USAGE": 350, "DCGM_FI_DEV_GPU_TEMP": 70},
+		labels: model.Metric{"gpu": "0", "modelName": "NVIDIA A100"},
+	}
+	source := NewDCGMSource(api)
+	ctx := context.Background()
+
+	reading, err := source.PodPower(ctx, pods, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 350.0, reading.Current)
+	reading, err = source.NodePower(ctx, "node-a", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 350.0, reading.Current)
+	reading, err = source.NodeTemperature(ctx, "node-a", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 70.0, reading.Current)
+	assert.Equal(t, []string{
+		`sum(DCGM_FI_DEV_POWER_USAGE{namespace="default",pod=~"llm-a|llm\\.b"})`,
+		`sum(DCGM_FI_DEV_POWER_USAGE{Hostname="node-a"})`,
+		`max(DCGM_FI_DEV_GPU_TEMP{Hostname="node-a"})`,
+	}, api.queries)
+
+	devices, err := source.PodDevices(ctx, pods[0])
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"gpu": "0", "modelName": "NVIDIA A100"}, devices)
+}
+
+func TestPodSelectors(t *testing.T) {
+	selectors := podSelectors("power", "pod", "namespace", []types.NamespacedName{
+		{Namespace: "prod", Name: "llm-a"},
+		{Namespace: "default", Name: `llm"}`},
+		{Namespace: "prod", Name: "llm.b"},
+	})
+	assert.Equal(t, []string{
+		`power{namespace="default",pod="llm\"}"}`,
+		`power{namespace="prod",pod=~"llm-a|llm\\.b"}`,
+	}, selectors)
+}
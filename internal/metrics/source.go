@@ -0,0 +1,53 @@
+// source.go
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Names of the built-in metrics sources.
+const (
+	Kepler = "Kepler"
+	DCGM   = "DCGM"
+	File   = "File"
+)
+
+// Default is the source read when none is selected.
+const Default = Kepler
+
+// Reading is a measurement now and over a window: watts for power, celsius
+// for temperature. With a zero window only Current is read, and Peak and
+// Average are equal to it.
+type Reading struct {
+	Current float64
+	Peak    float64
+	Average float64
+}
+
+// Sample is a measurement at a point in time.
+type Sample struct {
+	Time  time.Time
+	Value float64
+}
+
+// PowerMetricsSource reads the power of pods, nodes and GPUs and the
+// temperature of nodes. Pods are measured together: the reading is the sum of
+// their power at each point in time.
+type PowerMetricsSource interface {
+	PodPower(ctx context.Context, pods []types.NamespacedName, window time.Duration) (*Reading, error)
+	// PodPowerHistory returns the power of the pods over lookback ending now,
+	// one sample per step.
+	PodPowerHistory(ctx context.Context, pods []types.NamespacedName, lookback, step time.Duration) ([]Sample, error)
+	NodePower(ctx context.Context, node string, window time.Duration) (*Reading, error)
+	GPUPower(ctx context.Context, node string, window time.Duration) (*Reading, error)
+	NodeTemperature(ctx context.Context, node string, window time.Duration) (*Reading, error)
+}
+
+// PodDeviceSource is implemented by the sources that know the devices a pod
+// runs on, e.g. the CPU package and GPU reported along with alerts.
+type PodDeviceSource interface {
+	PodDevices(ctx context.Context, pod types.NamespacedName) (map[string]string, error)
+}
@@ -0,0 +1,89 @@
+// client.go
+package planner
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// DefaultTimeout bounds a call to the Planner service when no timeout is configured.
+const DefaultTimeout = 5 * time.Second
+
+// NewClient returns a client of the Planner service at address, along with
+// its connection to be closed by the caller. The connection is established
+// lazily and re-established as needed, so an unreachable planner only fails
+// the calls made while it is down.
+func NewClient(address string) (PlannerClient, *grpc.ClientConn, error) {
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewPlannerClient(conn), conn, nil
+}
+
+// Backoff is the delay between attempts to re-establish a watch. It starts at
+// Initial and doubles after every failed attempt up to Max.
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+// DefaultBackoff is the backoff of watches when none is configured.
+var DefaultBackoff = Backoff{Initial: time.Second, Max: time.Minute}
+
+// next returns the delay following delay.
+func (b Backoff) next(delay time.Duration) time.Duration {
+	if delay <= 0 {
+		return b.Initial
+	}
+	return min(2*delay, b.Max)
+}
+
+// WatchRecommendations subscribes to the plans of request and calls handle
+// with each plan received, until ctx is done. When the stream fails or ends,
+// it subscribes again after a delay growing with every consecutive failure;
+// the delay is reset once a plan is received.
+func WatchRecommendations(ctx context.Context, c PlannerClient, request *WatchRecommendationsRequest, backoff Backoff, handle func(*CalculateOptimalReplicasResponse)) {
+	var delay time.Duration
+	for {
+		received, err := watchOnce(ctx, c, request, handle)
+		if err != nil && ctx.Err() == nil {
+			log.Error(err, "Planner watch failed", "subscriber", request.Subscriber)
+		}
+		if received {
+			delay = 0
+		}
+		delay = backoff.next(delay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// watchOnce receives the plans of a single stream until it ends, and reports
+// whether any plan was received.
+func watchOnce(ctx context.Context, c PlannerClient, request *WatchRecommendationsRequest, handle func(*CalculateOptimalReplicasResponse)) (bool, error) {
+	stream, err := c.WatchRecommendations(ctx, request)
+	if err != nil {
+		return false, err
+	}
+	received := false
+	for {
+		response, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			return received, err
+		}
+		received = true
+		handle(response)
+	}
+}
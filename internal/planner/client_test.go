@@ -0,0 +1,76 @@
+package planner
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+// fakeWatchClient answers each WatchRecommendations call with the next of its
+// streams, or with an error when the stream is nil. It calls cancel once the
+// streams are exhausted.
+type fakeWatchClient struct {
+	PlannerClient
+	streams [][]*CalculateOptimalReplicasResponse
+	calls   int
+	cancel  context.CancelFunc
+}
+
+func (f *fakeWatchClient) WatchRecommendations(ctx context.Context, in *WatchRecommendationsRequest, opts ...grpc.CallOption) (Planner_WatchRecommendationsClient, error) {
+	f.calls++
+	if f.calls > len(f.streams) {
+		f.cancel()
+		return nil, ctx.Err()
+	}
+	responses := f.streams[f.calls-1]
+	if responses == nil {
+		return nil, errors.New("planner unavailable")
+	}
+	return &fakeWatchStream{responses: responses}, nil
+}
+
+// fakeWatchStream yields its responses, then ends.
+type fakeWatchStream struct {
+	grpc.ClientStream
+	responses []*CalculateOptimalReplicasResponse
+}
+
+func (f *fakeWatchStream) Recv() (*CalculateOptimalReplicasResponse, error) {
+	if len(f.responses) == 0 {
+		return nil, io.EOF
+	}
+	response := f.responses[0]
+	f.responses = f.responses[1:]
+	return response, nil
+}
+
+func TestWatchRecommendationsReconnects(t *testing.T) {
+	first := &CalculateOptimalReplicasResponse{Reason: "first"}
+	second := &CalculateOptimalReplicasResponse{Reason: "second"}
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &fakeWatchClient{
+		streams: [][]*CalculateOptimalReplicasResponse{nil, {first}, nil, {second}},
+		cancel:  cancel,
+	}
+
+	var received []string
+	WatchRecommendations(ctx, c, &WatchRecommendationsRequest{Subscriber: "default/config"},
+		Backoff{Initial: time.Millisecond, Max: 2 * time.Millisecond},
+		func(response *CalculateOptimalReplicasResponse) { received = append(received, response.Reason) })
+
+	assert.Equal(t, []string{"first", "second"}, received)
+	assert.Equal(t, 5, c.calls)
+}
+
+func TestBackoff(t *testing.T) {
+	backoff := Backoff{Initial: time.Second, Max: 3 * time.Second}
+	assert.Equal(t, time.Second, backoff.next(0))
+	assert.Equal(t, 2*time.Second, backoff.next(time.Second))
+	assert.Equal(t, 3*time.Second, backoff.next(2*time.Second))
+	assert.Equal(t, 3*time.Second, backoff.next(3*time.Second))
+}
@@ -23,6 +23,9 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type PlannerClient interface {
 	CalculateOptimalReplicas(ctx context.Context, in *CalculateOptimalReplicasRequest, opts ...grpc.CallOption) (*CalculateOptimalReplicasResponse, error)
+	// WatchRecommendations sends the current plan of the request, then a new
+	// plan whenever the inputs of the planner change it.
+	WatchRecommendations(ctx context.Context, in *WatchRecommendationsRequest, opts ...grpc.CallOption) (Planner_WatchRecommendationsClient, error)
 }
 
 type plannerClient struct {
@@ -42,11 +45,46 @@ func (c *plannerClient) CalculateOptimalReplicas(ctx context.Context, in *Calcul
 	return out, nil
 }
 
+func (c *plannerClient) WatchRecommendations(ctx context.Context, in *WatchRecommendationsRequest, opts ...grpc.CallOption) (Planner_WatchRecommendationsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Planner_ServiceDesc.Streams[0], "/planner.Planner/WatchRecommendations", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &plannerWatchRecommendationsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Planner_WatchRecommendationsClient interface {
+	Recv() (*CalculateOptimalReplicasResponse, error)
+	grpc.ClientStream
+}
+
+type plannerWatchRecommendationsClient struct {
+	grpc.ClientStream
+}
+
+func (x *plannerWatchRecommendationsClient) Recv() (*CalculateOptimalReplicasResponse, error) {
+	m := new(CalculateOptimalReplicasResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // PlannerServer is the server API for Planner service.
 // All implementations must embed UnimplementedPlannerServer
 // for forward compatibility
 type PlannerServer interface {
 	CalculateOptimalReplicas(context.Context, *CalculateOptimalReplicasRequest) (*CalculateOptimalReplicasResponse, error)
+	// WatchRecommendations sends the current plan of the request, then a new
+	// plan whenever the inputs of the planner change it.
+	WatchRecommendations(*WatchRecommendationsRequest, Planner_WatchRecommendationsServer) error
 	mustEmbedUnimplementedPlannerServer()
 }
 
@@ -57,6 +95,9 @@ type UnimplementedPlannerServer struct {
 func (UnimplementedPlannerServer) CalculateOptimalReplicas(context.Context, *CalculateOptimalReplicasRequest) (*CalculateOptimalReplicasResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CalculateOptimalReplicas not implemented")
 }
+func (UnimplementedPlannerServer) WatchRecommendations(*WatchRecommendationsRequest, Planner_WatchRecommendationsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchRecommendations not implemented")
+}
 func (UnimplementedPlannerServer) mustEmbedUnimplementedPlannerServer() {}
 
 // UnsafePlannerServer may be embedded to opt out of forward compatibility for this service.
@@ -88,6 +129,27 @@ func _Planner_CalculateOptimalReplicas_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Planner_WatchRecommendations_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRecommendationsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PlannerServer).WatchRecommendations(m, &plannerWatchRecommendationsServer{stream})
+}
+
+type Planner_WatchRecommendationsServer interface {
+	Send(*CalculateOptimalReplicasResponse) error
+	grpc.ServerStream
+}
+
+type plannerWatchRecommendationsServer struct {
+	grpc.ServerStream
+}
+
+func (x *plannerWatchRecommendationsServer) Send(m *CalculateOptimalReplicasResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // Planner_ServiceDesc is the grpc.ServiceDesc for Planner service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -100,6 +162,12 @@ var Planner_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _Planner_CalculateOptimalReplicas_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchRecommendations",
+			Handler:       _Planner_WatchRecommendations_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "grpc.proto",
 }
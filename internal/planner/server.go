@@ -0,0 +1,261 @@
+// server.go
+package planner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/Climatik-Project/Climatik-Project/internal/metrics"
+	"github.com/Climatik-Project/Climatik-Project/internal/promql"
+)
+
+// rateWindow is the window of the rates of the counters read.
+const rateWindow = time.Minute
+
+// DefaultWatchInterval is how often the plan of a watch is re-evaluated when
+// no interval is configured.
+const DefaultWatchInterval = 30 * time.Second
+
+var log = ctrl.Log.WithName("planner")
+
+// Server implements the Planner service. It estimates the power per replica
+// and the load of every deployment from Prometheus and divides the power cap
+// between the deployments in proportion to their load, or to their power
+// when no load is reported.
+//
+// The power is read from the Kepler container energy counters and the load
+// from the request counter of Names, selecting the namespace of a deployment
+// and exactly the pods listed in the request, so that pods of other
+// deployments sharing a name prefix are never counted. Watches re-evaluate
+// their plan every WatchInterval.
+type Server struct {
+	UnimplementedPlannerServer
+
+	Prometheus    prom_v1.API
+	Names         *metrics.SharedNames
+	WatchInterval time.Duration
+}
+
+// NewServer returns a Planner server querying prometheus for the series of
+// the default names.
+func NewServer(prometheus prom_v1.API) *Server {
+	return &Server{
+		Prometheus:    prometheus,
+		Names:         metrics.NewSharedNames(metrics.DefaultNames()),
+		WatchInterval: DefaultWatchInterval,
+	}
+}
+
+// deploymentMetrics holds the figures measured for a deployment.
+type deploymentMetrics struct {
+	deployment      *Deployment
+	power           float64
+	powerPerReplica float64
+	load            float64
+}
+
+// CalculateOptimalReplicas returns the replicas of every deployment whose
+// power per replica is known, within its minimum and maximum replicas.
+// Deployments without metrics are left out so that callers can fall back to
+// their own computation for them. Priorities, latency objectives and the
+// carbon intensity are not taken into account yet.
+func (s *Server) CalculateOptimalReplicas(ctx context.Context, request *CalculateOptimalReplicasRequest) (*CalculateOptimalReplicasResponse, error) {
+	if request.PowerCap <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "powerCap must be positive, got %v", request.PowerCap)
+	}
+
+	measured := make([]deploymentMetrics, 0, len(request.Deployments))
+	var totalPower, totalLoad float64
+	for _, deployment := range request.Deployments {
+		figures, err := s.measure(ctx, deployment)
+		if err != nil {
+			log.Error(err, "Failed to measure deployment", "name", deployment.Name, "namespace", deployment.Namespace)
+			continue
+		}
+		if figures.powerPerReplica <= 0 {
+			log.Info("No power is reported for the deployment", "name", deployment.Name, "namespace", deployment.Namespace)
+			continue
+		}
+		measured = append(measured, figures)
+		totalPower += figures.power
+		totalLoad += figures.load
+	}
+
+	response := &CalculateOptimalReplicasResponse{}
+	for _, figures := range measured {
+		share := shareOf(figures.power, totalPower, len(measured))
+		basis := "power"
+		if totalLoad > 0 {
+			share = shareOf(figures.load, totalLoad, len(measured))
+			basis = "request rate"
+		}
+		deployment := figures.deployment
+		budget := request.PowerCap * share
+		replicas := max(int32(math.Floor(budget/figures.powerPerReplica)), deployment.MinReplicas, 1)
+		reason := fmt.Sprintf("%.0f%% of the power cap by %s fits %d replicas of %.0fW", share*100, basis, replicas, figures.powerPerReplica)
+		if deployment.MaxReplicas > 0 && replicas > deployment.MaxReplicas {
+			replicas = max(deployment.MaxReplicas, 1)
+			reason = fmt.Sprintf("%.0f%% of the power cap by %s is bounded by %d replicas", share*100, basis, replicas)
+		}
+		predictedPower := float64(replicas) * figures.powerPerReplica
+		log.Info("Replicas calculated", "name", deployment.Name, "namespace", deployment.Namespace,
+			"powerPerReplica", figures.powerPerReplica, "load", figures.load, "budget", budget, "replicas", replicas)
+		response.DeploymentReplicas = append(response.DeploymentReplicas, &DeploymentReplicas{
+			Name:            deployment.Name,
+			Namespace:       deployment.Namespace,
+			OptimalReplicas: replicas,
+			Reason:          reason,
+			PredictedPower:  predictedPower,
+		})
+		response.PredictedTotalPower += predictedPower
+	}
+	response.Reason = fmt.Sprintf("Planned %d of %d deployments under a power cap of %.0fW",
+		len(response.DeploymentReplicas), len(request.Deployments), request.PowerCap)
+	return response, nil
+}
+
+// WatchRecommendations sends the plan of the request at once, then re-evaluates
+// it every WatchInterval and sends it again whenever the replicas changed,
+// until the subscriber goes away.
+func (s *Server) WatchRecommendations(request *WatchRecommendationsRequest, stream Planner_WatchRecommendationsServer) error {
+	if request.Request == nil {
+		return status.Error(codes.InvalidArgument, "request must be set")
+	}
+	interval := s.WatchInterval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	ctx := stream.Context()
+	log.Info("Watch started", "subscriber", request.Subscriber)
+	defer log.Info("Watch stopped", "subscriber", request.Subscriber)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var last *CalculateOptimalReplicasResponse
+	for {
+		response, err := s.CalculateOptimalReplicas(ctx, request.Request)
+		if err != nil {
+			return err
+		}
+		if last == nil || !samePlan(last, response) {
+			if err := stream.Send(response); err != nil {
+				return err
+			}
+			last = response
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// samePlan reports whether two plans set the same replicas on the same deployments.
+func samePlan(a, b *CalculateOptimalReplicasResponse) bool {
+	if len(a.DeploymentReplicas) != len(b.DeploymentReplicas) {
+		return false
+	}
+	for i, replicas := range a.DeploymentReplicas {
+		other := b.DeploymentReplicas[i]
+		if replicas.Name != other.Name || replicas.Namespace != other.Namespace || replicas.OptimalReplicas != other.OptimalReplicas {
+			return false
+		}
+	}
+	return true
+}
+
+// measure returns the power per replica and the load of a deployment,
+// taking them from the request when provided and from Prometheus otherwise.
+func (s *Server) measure(ctx context.Context, deployment *Deployment) (deploymentMetrics, error) {
+	figures := deploymentMetrics{deployment: deployment, powerPerReplica: deployment.WattsPerReplica, load: deployment.RequestRate}
+	if len(deployment.Pods) == 0 {
+		if figures.powerPerReplica <= 0 {
+			return figures, errors.New("no pods are given for the deployment")
+		}
+		return figures, nil
+	}
+	names := s.Names.Load()
+	if figures.powerPerReplica <= 0 {
+		power, err := s.query(ctx, powerQuery(&names.Kepler, deployment))
+		if err != nil {
+			return figures, err
+		}
+		replicas, err := s.query(ctx, replicasQuery(&names.Kepler, deployment))
+		if err != nil {
+			return figures, err
+		}
+		figures.power = power
+		if replicas > 0 {
+			figures.powerPerReplica = power / replicas
+		}
+	}
+	if figures.load <= 0 {
+		// A deployment without load metrics is weighted by its power only.
+		load, err := s.query(ctx, loadQuery(&names.Requests, deployment))
+		if err != nil {
+			log.Error(err, "Failed to query load", "name", deployment.Name, "namespace", deployment.Namespace)
+		}
+		figures.load = load
+	}
+	return figures, nil
+}
+
+// powerQuery returns the power of the pods of a deployment in watts, e.g.
+// sum(rate(kepler_container_joules_total{container_namespace="default",pod_name=~"llm-a|llm-b"}[1m])).
+func powerQuery(names *metrics.KeplerNames, deployment *Deployment) string {
+	return promql.Sum(promql.Rate(podSelector(names.ContainerJoules, names.NamespaceLabel, names.PodLabel, deployment), rateWindow))
+}
+
+// replicasQuery returns the number of pods of a deployment reporting power.
+func replicasQuery(names *metrics.KeplerNames, deployment *Deployment) string {
+	return promql.Count(promql.CountBy(podSelector(names.ContainerJoules, names.NamespaceLabel, names.PodLabel, deployment), names.PodLabel))
+}
+
+// loadQuery returns the requests per second served by the pods of a deployment.
+func loadQuery(names *metrics.RequestNames, deployment *Deployment) string {
+	return promql.Sum(promql.Rate(podSelector(names.RequestsTotal, names.NamespaceLabel, names.PodLabel, deployment), rateWindow))
+}
+
+// podSelector selects the series of metric of the pods of the deployment.
+func podSelector(metric, namespaceLabel, podLabel string, deployment *Deployment) string {
+	return promql.Selector(metric, promql.Equal(namespaceLabel, deployment.Namespace), promql.OneOf(podLabel, deployment.Pods...))
+}
+
+// query evaluates the query and returns the value of its first sample, or 0
+// when the result is empty.
+func (s *Server) query(ctx context.Context, query string) (float64, error) {
+	result, warnings, err := s.Prometheus.Query(ctx, query, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if len(warnings) > 0 {
+		log.Info("Prometheus query warnings", "query", query, "warnings", warnings)
+	}
+	vector, ok := result.(model.Vector)
+	if !ok {
+		return 0, fmt.Errorf("unexpected result type %s for query %s", result.Type(), query)
+	}
+	if len(vector) == 0 {
+		return 0, nil
+	}
+	return float64(vector[0].Value), nil
+}
+
+// shareOf returns the fraction of total taken by part, splitting evenly
+// between count parts when nothing is reported.
+func shareOf(part, total float64, count int) float64 {
+	if total <= 0 {
+		return 1 / float64(count)
+	}
+	return part / total
+}
@@ -0,0 +1,286 @@
+package planner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/Climatik-Project/Climatik-Project/internal/metrics"
+)
+
+// fakeMetrics are the figures reported for a deployment by fakePrometheus.
+type fakeMetrics struct {
+	power, replicas, load float64
+}
+
+// fakePrometheus answers the default queries from the metrics registered for
+// every deployment, whose single pod is named after it with a -0 suffix. The
+// figures of all the deployments whose pod matches the pod matcher of a query
+// are summed, as Prometheus would.
+type fakePrometheus struct {
+	prom_v1.API
+	deployments map[string]fakeMetrics
+}
+
+var podMatcher = regexp.MustCompile(`\bpod(?:_name)?(=~?)("(?:[^"\\]|\\.)*")`)
+
+func (f *fakePrometheus) Query(ctx context.Context, query string, ts time.Time, opts ...prom_v1.Option) (model.Value, prom_v1.Warnings, error) {
+	match := podMatcher.FindStringSubmatch(query)
+	if match == nil {
+		return nil, nil, fmt.Errorf("no pod matcher in %s", query)
+	}
+	value, err := strconv.Unquote(match[2])
+	if err != nil {
+		return nil, nil, err
+	}
+	pattern := regexp.QuoteMeta(value)
+	if match[1] == "=~" {
+		pattern = value
+	}
+	pods, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, nil, err
+	}
+	var sum float64
+	matched := false
+	for name, metrics := range f.deployments {
+		if !pods.MatchString(name + "-0") {
+			continue
+		}
+		matched = true
+		switch {
+		case strings.HasPrefix(query, "count("):
+			sum += metrics.replicas
+		case strings.Contains(query, "http_requests_total"):
+			sum += metrics.load
+		default:
+			sum += metrics.power
+		}
+	}
+	if !matched {
+		return nil, nil, errors.New("no such deployment")
+	}
+	return model.Vector{&model.Sample{Value: model.SampleValue(sum)}}, nil, nil
+}
+
+func calculate(t *testing.T, server *Server, powerCap float64, names ...string) map[string]int32 {
+	request := &CalculateOptimalReplicasRequest{PowerCap: powerCap}
+	for _, name := range names {
+		request.Deployments = append(request.Deployments, &Deployment{Name: name, Namespace: "default", Pods: []string{name + "-0"}})
+	}
+	response, err := server.CalculateOptimalReplicas(context.Background(), request)
+	require.NoError(t, err)
+	replicas := make(map[string]int32, len(response.DeploymentReplicas))
+	for _, deployment := range response.DeploymentReplicas {
+		assert.Equal(t, "default", deployment.Namespace)
+		replicas[deployment.Name] = deployment.OptimalReplicas
+	}
+	return replicas
+}
+
+func TestCalculateOptimalReplicas(t *testing.T) {
+	tests := []struct {
+		name        string
+		deployments map[string]fakeMetrics
+		powerCap    float64
+		expected    map[string]int32
+	}{
+		{
+			name:        "single deployment takes the whole cap",
+			deployments: map[string]fakeMetrics{"llm": {power: 500, replicas: 2, load: 10}},
+			powerCap:    1000,
+			expected:    map[string]int32{"llm": 4},
+		},
+		{
+			name: "cap is divided by load",
+			deployments: map[string]fakeMetrics{
+				"llm":  {power: 400, replicas: 2, load: 30},
+				"chat": {power: 400, replicas: 4, load: 10},
+			},
+			powerCap: 800,
+			expected: map[string]int32{"llm": 3, "chat": 2},
+		},
+		{
+			name: "cap is divided by power without load",
+			deployments: map[string]fakeMetrics{
+				"llm":  {power: 300, replicas: 3},
+				"chat": {power: 100, replicas: 1},
+			},
+			powerCap: 200,
+			expected: map[string]int32{"llm": 1, "chat": 1},
+		},
+		{
+			name:        "at least one replica is kept",
+			deployments: map[string]fakeMetrics{"llm": {power: 500, replicas: 1}},
+			powerCap:    100,
+			expected:    map[string]int32{"llm": 1},
+		},
+		{
+			name:        "deployments without power are left out",
+			deployments: map[string]fakeMetrics{"llm": {replicas: 2}},
+			powerCap:    100,
+			expected:    map[string]int32{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(&fakePrometheus{deployments: tt.deployments})
+			names := make([]string, 0, len(tt.deployments))
+			for name := range tt.deployments {
+				names = append(names, name)
+			}
+			assert.Equal(t, tt.expected, calculate(t, server, tt.powerCap, names...))
+		})
+	}
+}
+
+func TestCalculateOptimalReplicasFromRequest(t *testing.T) {
+	server := NewServer(&fakePrometheus{deployments: map[string]fakeMetrics{
+		"llm":  {power: 500, replicas: 2, load: 10},
+		"chat": {power: 500, replicas: 2, load: 10},
+	}})
+	response, err := server.CalculateOptimalReplicas(context.Background(), &CalculateOptimalReplicasRequest{
+		PowerCap: 1000,
+		Deployments: []*Deployment{
+			{Name: "llm", Namespace: "default", WattsPerReplica: 100, RequestRate: 30, MaxReplicas: 5},
+			{Name: "chat", Namespace: "default", RequestRate: 10, MinReplicas: 2, Pods: []string{"chat-0"}},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, response.DeploymentReplicas, 2)
+
+	llm, chat := response.DeploymentReplicas[0], response.DeploymentReplicas[1]
+	assert.Equal(t, int32(5), llm.OptimalReplicas)
+	assert.Equal(t, 500.0, llm.PredictedPower)
+	assert.Contains(t, llm.Reason, "bounded")
+	assert.Equal(t, int32(2), chat.OptimalReplicas)
+	assert.Equal(t, 500.0, chat.PredictedPower)
+	assert.Equal(t, 1000.0, response.PredictedTotalPower)
+	assert.NotEmpty(t, response.Reason)
+}
+
+func TestCalculateOptimalReplicasUnknownDeployment(t *testing.T) {
+	server := NewServer(&fakePrometheus{deployments: map[string]fakeMetrics{"llm": {power: 500, replicas: 2}}})
+	assert.Equal(t, map[string]int32{"llm": 4}, calculate(t, server, 1000, "llm", "missing"))
+}
+
+func TestCalculateOptimalReplicasMeasuresOnlyItsPods(t *testing.T) {
+	server := NewServer(&fakePrometheus{deployments: map[string]fakeMetrics{
+		"web":     {power: 500, replicas: 2},
+		"web-api": {power: 1000, replicas: 1},
+		"llm.v2":  {power: 300, replicas: 1},
+		"llmxv2":  {power: 900, replicas: 1},
+	}})
+	assert.Equal(t, map[string]int32{"web": 5, "llm.v2": 2}, calculate(t, server, 2000, "web", "llm.v2"))
+}
+
+// recordingPrometheus records the queries and reports no series.
+type recordingPrometheus struct {
+	prom_v1.API
+	queries []string
+}
+
+func (r *recordingPrometheus) Query(ctx context.Context, query string, ts time.Time, opts ...prom_v1.Option) (model.Value, prom_v1.Warnings, error) {
+	r.queries = append(r.queries, query)
+	return model.Vector{}, nil, nil
+}
+
+func TestQueriesEscapeValuesAndFollowNames(t *testing.T) {
+	prometheus := &recordingPrometheus{}
+	server := NewServer(prometheus)
+	names := metrics.DefaultNames()
+	names.Kepler.PodLabel = "pod"
+	names.Requests.RequestsTotal = "istio_requests_total"
+	server.Names.Store(names)
+
+	_, err := server.CalculateOptimalReplicas(context.Background(), &CalculateOptimalReplicasRequest{
+		PowerCap:    1000,
+		Deployments: []*Deployment{{Name: "llm", Namespace: `team.a"}`, Pods: []string{`llm"x-0`, "llm.v2-0"}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`sum(rate(kepler_container_joules_total{container_namespace="team.a\"}",pod=~"llm\"x-0|llm\\.v2-0"}[1m]))`,
+		`count(count by (pod) (kepler_container_joules_total{container_namespace="team.a\"}",pod=~"llm\"x-0|llm\\.v2-0"}))`,
+		`sum(rate(istio_requests_total{namespace="team.a\"}",pod=~"llm\"x-0|llm\\.v2-0"}[1m]))`,
+	}, prometheus.queries)
+}
+
+func TestCalculateOptimalReplicasWithoutPods(t *testing.T) {
+	server := NewServer(&fakePrometheus{deployments: map[string]fakeMetrics{"llm": {power: 500, replicas: 2}}})
+	response, err := server.CalculateOptimalReplicas(context.Background(), &CalculateOptimalReplicasRequest{
+		PowerCap:    1000,
+		Deployments: []*Deployment{{Name: "llm", Namespace: "default"}},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, response.DeploymentReplicas)
+}
+
+func TestCalculateOptimalReplicasInvalidPowerCap(t *testing.T) {
+	server := NewServer(&fakePrometheus{})
+	_, err := server.CalculateOptimalReplicas(context.Background(), &CalculateOptimalReplicasRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// fakeRecommendationsStream records the plans sent and calls onSend after each.
+type fakeRecommendationsStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	sent   []*CalculateOptimalReplicasResponse
+	onSend func(count int)
+}
+
+func (f *fakeRecommendationsStream) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeRecommendationsStream) Send(response *CalculateOptimalReplicasResponse) error {
+	f.sent = append(f.sent, response)
+	f.onSend(len(f.sent))
+	return nil
+}
+
+func TestWatchRecommendations(t *testing.T) {
+	prometheus := &fakePrometheus{deployments: map[string]fakeMetrics{"llm": {power: 500, replicas: 2, load: 10}}}
+	server := NewServer(prometheus)
+	server.WatchInterval = time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := &fakeRecommendationsStream{ctx: ctx, onSend: func(count int) {
+		if count == 1 {
+			prometheus.deployments["llm"] = fakeMetrics{power: 1000, replicas: 2, load: 10}
+		} else {
+			cancel()
+		}
+	}}
+
+	err := server.WatchRecommendations(&WatchRecommendationsRequest{
+		Subscriber: "default/config",
+		Request: &CalculateOptimalReplicasRequest{
+			PowerCap:    1000,
+			Deployments: []*Deployment{{Name: "llm", Namespace: "default", Pods: []string{"llm-0"}}},
+		},
+	}, stream)
+	require.NoError(t, err)
+	require.Len(t, stream.sent, 2)
+	assert.Equal(t, int32(4), stream.sent[0].DeploymentReplicas[0].OptimalReplicas)
+	assert.Equal(t, int32(2), stream.sent[1].DeploymentReplicas[0].OptimalReplicas)
+}
+
+func TestWatchRecommendationsInvalidRequest(t *testing.T) {
+	err := NewServer(&fakePrometheus{}).WatchRecommendations(&WatchRecommendationsRequest{}, &fakeRecommendationsStream{ctx: context.Background()})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
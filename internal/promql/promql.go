@@ -0,0 +1,128 @@
+// promql.go
+package promql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// Match types of label matchers.
+const (
+	MatchEqual     = "="
+	MatchNotEqual  = "!="
+	MatchRegexp    = "=~"
+	MatchNotRegexp = "!~"
+)
+
+// Matcher selects the series whose label matches a value. Values are always
+// quoted when rendered, so that they cannot change the structure of a query.
+type Matcher struct {
+	Label string
+	Type  string
+	Value string
+}
+
+// Equal matches the label exactly against value.
+func Equal(label, value string) Matcher {
+	return Matcher{Label: label, Type: MatchEqual, Value: value}
+}
+
+// OneOf matches the label exactly against any of values: with an equality
+// matcher for a single value and otherwise with a regular expression of the
+// values, whose metacharacters are escaped.
+func OneOf(label string, values ...string) Matcher {
+	if len(values) == 1 {
+		return Equal(label, values[0])
+	}
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, regexp.QuoteMeta(value))
+	}
+	return Matcher{Label: label, Type: MatchRegexp, Value: strings.Join(quoted, "|")}
+}
+
+// String renders the matcher with its value as a PromQL string.
+func (m Matcher) String() string {
+	return m.Label + m.Type + Quote(m.Value)
+}
+
+// Quote returns value as a double-quoted PromQL string, escaping quotes,
+// backslashes and non-printable characters.
+func Quote(value string) string {
+	return strconv.Quote(value)
+}
+
+// Selector selects the series of metric matching all matchers.
+func Selector(metric string, matchers ...Matcher) string {
+	if len(matchers) == 0 {
+		return metric
+	}
+	rendered := make([]string, 0, len(matchers))
+	for _, matcher := range matchers {
+		rendered = append(rendered, matcher.String())
+	}
+	return metric + "{" + strings.Join(rendered, ",") + "}"
+}
+
+// Rate is the per-second rate of the counters of selector over window.
+func Rate(selector string, window time.Duration) string {
+	return fmt.Sprintf("rate(%s[%s])", selector, model.Duration(window))
+}
+
+// Sum sums the series of expr.
+func Sum(expr string) string {
+	return "sum(" + expr + ")"
+}
+
+// Max is the highest of the series of expr.
+func Max(expr string) string {
+	return "max(" + expr + ")"
+}
+
+// Count is the number of series of expr.
+func Count(expr string) string {
+	return "count(" + expr + ")"
+}
+
+// CountBy is the number of series of expr for every value of labels.
+func CountBy(expr string, labels ...string) string {
+	return "count by (" + strings.Join(labels, ", ") + ") (" + expr + ")"
+}
+
+// Or is the union of the series of exprs.
+func Or(exprs ...string) string {
+	return strings.Join(exprs, " or ")
+}
+
+// MaxOverTime is the highest value of expr over window, evaluated as a
+// subquery.
+func MaxOverTime(expr string, window time.Duration) string {
+	return fmt.Sprintf("max_over_time((%s)[%s:])", expr, model.Duration(window))
+}
+
+// AvgOverTime is the average value of expr over window, evaluated as a
+// subquery.
+func AvgOverTime(expr string, window time.Duration) string {
+	return fmt.Sprintf("avg_over_time((%s)[%s:])", expr, model.Duration(window))
+}
+
+// ValidateMetricName fails when name is not a valid metric name.
+func ValidateMetricName(name string) error {
+	if !model.IsValidMetricName(model.LabelValue(name)) {
+		return fmt.Errorf("invalid metric name %q", name)
+	}
+	return nil
+}
+
+// ValidateLabelName fails when name is not a valid label name.
+func ValidateLabelName(name string) error {
+	if !model.LabelName(name).IsValid() {
+		return fmt.Errorf("invalid label name %q", name)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package promql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectorEscapesValues(t *testing.T) {
+	assert.Equal(t, `up`, Selector("up"))
+	assert.Equal(t, `up{job="api",pod="a\"}"}`, Selector("up", Equal("job", "api"), Equal("pod", `a"}`)))
+	assert.Equal(t, `up{path="C:\\tmp\n"}`, Selector("up", Equal("path", "C:\\tmp\n")))
+}
+
+func TestOneOf(t *testing.T) {
+	assert.Equal(t, `pod="llm.a"`, OneOf("pod", "llm.a").String())
+	assert.Equal(t, `pod=~"llm\\.a|llm-b|llm\\(c\\)"`, OneOf("pod", "llm.a", "llm-b", "llm(c)").String())
+}
+
+func TestFunctions(t *testing.T) {
+	selector := Selector("kepler_container_joules_total", Equal("container_namespace", "default"))
+	rate := Rate(selector, time.Minute)
+	assert.Equal(t, `rate(kepler_container_joules_total{container_namespace="default"}[1m])`, rate)
+	assert.Equal(t, `sum(a or b)`, Sum(Or("a", "b")))
+	assert.Equal(t, `max(a)`, Max("a"))
+	assert.Equal(t, `count(count by (pod, namespace) (a))`, Count(CountBy("a", "pod", "namespace")))
+	assert.Equal(t, `max_over_time((a)[5m:])`, MaxOverTime("a", 5*time.Minute))
+	assert.Equal(t, `avg_over_time((a)[1m30s:])`, AvgOverTime("a", 90*time.Second))
+}
+
+func TestValidateNames(t *testing.T) {
+	assert.NoError(t, ValidateMetricName("node:power:sum"))
+	assert.Error(t, ValidateMetricName(`up{job="x"}`))
+	assert.NoError(t, ValidateLabelName("pod_name"))
+	assert.Error(t, ValidateLabelName("pod-name"))
+}
@@ -0,0 +1,29 @@
+// fair.go
+package strategy
+
+// FairStrategy guarantees every target its floor and then hands out the
+// budget left one replica at a time to the target with the fewest replicas
+// that can afford another, so that replica counts stay as even as the
+// budget and the bounds of the targets allow. Ties go to the target listed
+// first.
+type FairStrategy struct{}
+
+func (FairStrategy) Allocate(budget float64, targets []Target) []int32 {
+	replicas, remaining := allocateFloors(budget, targets)
+	for {
+		fewest := -1
+		for i := range targets {
+			if !targets[i].canGrow(replicas[i]) || targets[i].PowerPerReplica > remaining {
+				continue
+			}
+			if fewest < 0 || replicas[i] < replicas[fewest] {
+				fewest = i
+			}
+		}
+		if fewest < 0 {
+			return replicas
+		}
+		replicas[fewest]++
+		remaining -= targets[fewest].PowerPerReplica
+	}
+}
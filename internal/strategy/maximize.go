@@ -0,0 +1,28 @@
+// maximize.go
+package strategy
+
+// MaximizeReplicasStrategy maximizes the total number of replicas. After the
+// floors, it fills the budget with the cheapest replicas first, like water
+// filling the lowest vessels first, one replica at a time until no target
+// can afford another. Ties go to the target listed first.
+type MaximizeReplicasStrategy struct{}
+
+func (MaximizeReplicasStrategy) Allocate(budget float64, targets []Target) []int32 {
+	replicas, remaining := allocateFloors(budget, targets)
+	for {
+		cheapest := -1
+		for i := range targets {
+			if !targets[i].canGrow(replicas[i]) || targets[i].PowerPerReplica > remaining {
+				continue
+			}
+			if cheapest < 0 || targets[i].PowerPerReplica < targets[cheapest].PowerPerReplica {
+				cheapest = i
+			}
+		}
+		if cheapest < 0 {
+			return replicas
+		}
+		replicas[cheapest]++
+		remaining -= targets[cheapest].PowerPerReplica
+	}
+}
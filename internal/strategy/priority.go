@@ -0,0 +1,83 @@
+// priority.go
+package strategy
+
+import (
+	"math"
+	"sort"
+)
+
+// PriorityStrategy serves targets in order of decreasing priority. After the
+// floors, the budget left is split between the targets of the highest
+// priority in proportion to their weight, and whatever they cannot use, as
+// they reach their maximum or cannot afford another replica, passes on to
+// the next priority.
+type PriorityStrategy struct{}
+
+func (PriorityStrategy) Allocate(budget float64, targets []Target) []int32 {
+	replicas, remaining := allocateFloors(budget, targets)
+
+	order := make([]int, len(targets))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return targets[order[a]].Priority > targets[order[b]].Priority
+	})
+
+	for start := 0; start < len(order); {
+		end := start
+		for end < len(order) && targets[order[end]].Priority == targets[order[start]].Priority {
+			end++
+		}
+		remaining = allocateByWeight(remaining, targets, replicas, order[start:end])
+		start = end
+	}
+	return replicas
+}
+
+// allocateByWeight splits the budget between the targets of group in
+// proportion to their weight, then hands what rounding left to the heaviest
+// targets that can still afford a replica. It returns the budget left.
+func allocateByWeight(budget float64, targets []Target, replicas []int32, group []int) float64 {
+	if budget <= 0 {
+		return budget
+	}
+	var totalWeight float64
+	for _, i := range group {
+		totalWeight += weightOf(&targets[i])
+	}
+	spent := 0.0
+	for _, i := range group {
+		target := &targets[i]
+		extra := int32(math.Floor(budget * weightOf(target) / totalWeight / target.PowerPerReplica))
+		if target.MaxReplicas > 0 {
+			extra = min(extra, max(target.MaxReplicas-replicas[i], 0))
+		}
+		replicas[i] += extra
+		spent += float64(extra) * target.PowerPerReplica
+	}
+	budget -= spent
+
+	byWeight := append([]int(nil), group...)
+	sort.SliceStable(byWeight, func(a, b int) bool {
+		return weightOf(&targets[byWeight[a]]) > weightOf(&targets[byWeight[b]])
+	})
+	for grown := true; grown; {
+		grown = false
+		for _, i := range byWeight {
+			if targets[i].canGrow(replicas[i]) && targets[i].PowerPerReplica <= budget {
+				replicas[i]++
+				budget -= targets[i].PowerPerReplica
+				grown = true
+			}
+		}
+	}
+	return budget
+}
+
+func weightOf(target *Target) float64 {
+	if target.Weight <= 0 {
+		return 1
+	}
+	return float64(target.Weight)
+}
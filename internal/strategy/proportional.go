@@ -0,0 +1,28 @@
+// proportional.go
+package strategy
+
+import "math"
+
+// ProportionalStrategy splits the budget between targets in proportion to
+// their current power and gives each target the replicas that fit in its
+// part. Budget left over by rounding is not redistributed.
+type ProportionalStrategy struct{}
+
+func (ProportionalStrategy) Allocate(budget float64, targets []Target) []int32 {
+	var totalPower float64
+	for _, target := range targets {
+		totalPower += target.Power
+	}
+	replicas := make([]int32, len(targets))
+	for i, target := range targets {
+		share := 1 / float64(len(targets))
+		if totalPower > 0 {
+			share = target.Power / totalPower
+		}
+		replicas[i] = max(int32(math.Floor(budget*share/target.PowerPerReplica)), target.floor())
+		if target.MaxReplicas > 0 {
+			replicas[i] = max(min(replicas[i], target.MaxReplicas), target.floor())
+		}
+	}
+	return replicas
+}
@@ -0,0 +1,115 @@
+// strategy.go
+package strategy
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Target is a workload competing for a power budget.
+type Target struct {
+	Name string
+	// Power is the current power of the workload in watts.
+	Power float64
+	// PowerPerReplica is the power of one replica of the workload in watts.
+	PowerPerReplica float64
+	// MinReplicas is the lowest number of replicas allocated to the workload,
+	// never less than one.
+	MinReplicas int32
+	// MaxReplicas is the highest number of replicas allocated to the
+	// workload, or zero for no bound.
+	MaxReplicas int32
+	// Priority orders workloads for the Priority strategy, highest first.
+	Priority int32
+	// Weight divides the budget between workloads of equal priority for the
+	// Priority strategy. Zero counts as one.
+	Weight int32
+}
+
+// floor returns the replicas a target is guaranteed by every strategy.
+func (t *Target) floor() int32 {
+	return max(t.MinReplicas, 1)
+}
+
+// canGrow reports whether one more replica is allowed above replicas.
+func (t *Target) canGrow(replicas int32) bool {
+	return t.MaxReplicas <= 0 || replicas < t.MaxReplicas
+}
+
+// Strategy allocates replicas to targets under a power budget in watts. It
+// returns the replicas of each target, in the order of targets. Every target
+// gets at least its floor, even when the floors alone exceed the budget, and
+// targets must report a positive power per replica.
+type Strategy interface {
+	Allocate(budget float64, targets []Target) []int32
+}
+
+// Names of the built-in strategies.
+const (
+	Proportional     = "Proportional"
+	MaximizeReplicas = "MaximizeReplicas"
+	Priority         = "Priority"
+	Fair             = "Fair"
+)
+
+// Default is the strategy used when none is selected.
+const Default = Proportional
+
+var (
+	mu         sync.RWMutex
+	strategies = map[string]Strategy{}
+)
+
+func init() {
+	Register(Proportional, ProportionalStrategy{})
+	Register(MaximizeReplicas, MaximizeReplicasStrategy{})
+	Register(Priority, PriorityStrategy{})
+	Register(Fair, FairStrategy{})
+}
+
+// Register makes a strategy available under name, replacing any strategy
+// already registered under it.
+func Register(name string, strategy Strategy) {
+	mu.Lock()
+	defer mu.Unlock()
+	strategies[name] = strategy
+}
+
+// Get returns the strategy registered under name, or the default strategy
+// when name is empty.
+func Get(name string) (Strategy, error) {
+	if name == "" {
+		name = Default
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	strategy, ok := strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown replica allocation strategy: %s", name)
+	}
+	return strategy, nil
+}
+
+// Names returns the names of the registered strategies in alphabetical order.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// allocateFloors gives every target its floor and returns the allocation and
+// the budget left, which is negative when the floors exceed the budget.
+func allocateFloors(budget float64, targets []Target) ([]int32, float64) {
+	replicas := make([]int32, len(targets))
+	for i := range targets {
+		replicas[i] = targets[i].floor()
+		budget -= float64(replicas[i]) * targets[i].PowerPerReplica
+	}
+	return replicas, budget
+}
@@ -0,0 +1,139 @@
+package strategy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		budget   float64
+		targets  []Target
+		expected []int32
+	}{
+		{
+			name:     "proportional splits by current power",
+			strategy: Proportional,
+			budget:   1000,
+			targets: []Target{
+				{Name: "a", Power: 300, PowerPerReplica: 100},
+				{Name: "b", Power: 100, PowerPerReplica: 50},
+			},
+			expected: []int32{7, 5},
+		},
+		{
+			name:     "proportional keeps the minimum",
+			strategy: Proportional,
+			budget:   100,
+			targets:  []Target{{Name: "a", Power: 500, PowerPerReplica: 250, MinReplicas: 2}},
+			expected: []int32{2},
+		},
+		{
+			name:     "proportional keeps at least one replica",
+			strategy: Proportional,
+			budget:   100,
+			targets:  []Target{{Name: "a", Power: 500, PowerPerReplica: 250}},
+			expected: []int32{1},
+		},
+		{
+			name:     "proportional is bounded by the maximum",
+			strategy: Proportional,
+			budget:   1000,
+			targets:  []Target{{Name: "a", Power: 500, PowerPerReplica: 250, MaxReplicas: 3}},
+			expected: []int32{3},
+		},
+		{
+			name:     "maximize fills the cheapest replicas first",
+			strategy: MaximizeReplicas,
+			budget:   1000,
+			targets: []Target{
+				{Name: "a", PowerPerReplica: 300},
+				{Name: "b", PowerPerReplica: 100},
+			},
+			expected: []int32{1, 7},
+		},
+		{
+			name:     "maximize spills over once the cheapest is full",
+			strategy: MaximizeReplicas,
+			budget:   1000,
+			targets: []Target{
+				{Name: "a", PowerPerReplica: 300},
+				{Name: "b", PowerPerReplica: 100, MaxReplicas: 4},
+			},
+			expected: []int32{2, 4},
+		},
+		{
+			name:     "priority serves the highest priority first",
+			strategy: Priority,
+			budget:   1000,
+			targets: []Target{
+				{Name: "low", PowerPerReplica: 100, Priority: 1},
+				{Name: "high", PowerPerReplica: 100, Priority: 10},
+			},
+			expected: []int32{1, 9},
+		},
+		{
+			name:     "priority passes unused budget on",
+			strategy: Priority,
+			budget:   1000,
+			targets: []Target{
+				{Name: "low", PowerPerReplica: 100, Priority: 1},
+				{Name: "high", PowerPerReplica: 100, Priority: 10, MaxReplicas: 3},
+			},
+			expected: []int32{7, 3},
+		},
+		{
+			name:     "priority splits equal priorities by weight",
+			strategy: Priority,
+			budget:   1000,
+			targets: []Target{
+				{Name: "a", PowerPerReplica: 100, Weight: 3},
+				{Name: "b", PowerPerReplica: 100, Weight: 1},
+			},
+			expected: []int32{7, 3},
+		},
+		{
+			name:     "fair evens out replica counts",
+			strategy: Fair,
+			budget:   1000,
+			targets: []Target{
+				{Name: "a", PowerPerReplica: 100},
+				{Name: "b", PowerPerReplica: 200},
+			},
+			expected: []int32{4, 3},
+		},
+		{
+			name:     "fair guarantees the minimum over the budget",
+			strategy: Fair,
+			budget:   100,
+			targets: []Target{
+				{Name: "a", PowerPerReplica: 100, MinReplicas: 2},
+				{Name: "b", PowerPerReplica: 100},
+			},
+			expected: []int32{2, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy, err := Get(tt.strategy)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, strategy.Allocate(tt.budget, tt.targets))
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	strategy, err := Get("")
+	require.NoError(t, err)
+	assert.Equal(t, ProportionalStrategy{}, strategy)
+
+	_, err = Get("Random")
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{Fair, MaximizeReplicas, Priority, Proportional}, Names())
+}